@@ -0,0 +1,3 @@
+-- record the outcome of a resolved market (NULL until resolved, TRUE => YES, FALSE => NO)
+ALTER TABLE markets
+ADD COLUMN IF NOT EXISTS outcome BOOLEAN;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_market_moderation_log_market_id;
+
+DROP TABLE IF EXISTS market_moderation_log;
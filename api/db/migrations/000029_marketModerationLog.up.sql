@@ -0,0 +1,11 @@
+-- audit log of admin pause/unpause/suspend/unsuspend actions on markets (who did it and why)
+CREATE TABLE IF NOT EXISTS market_moderation_log (
+    id SERIAL PRIMARY KEY,
+    market_id UUID NOT NULL REFERENCES markets(market_id) ON DELETE CASCADE,
+    action VARCHAR(32) NOT NULL CHECK (action IN ('pause', 'unpause', 'suspend', 'unsuspend')),
+    account_id VARCHAR(255) NOT NULL, -- the ADMIN user who performed the action
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_moderation_log_market_id ON market_moderation_log(market_id);
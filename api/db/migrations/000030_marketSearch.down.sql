@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_matches_market_id;
+
+DROP INDEX IF EXISTS idx_markets_search;
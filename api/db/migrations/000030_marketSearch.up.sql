@@ -0,0 +1,4 @@
+-- full-text search over market statement + description (SearchMarkets), plus an index for per-market volume aggregation
+CREATE INDEX IF NOT EXISTS idx_markets_search ON markets USING GIN (to_tsvector('english', statement || ' ' || description));
+
+CREATE INDEX IF NOT EXISTS idx_matches_market_id ON matches(market_id);
@@ -0,0 +1,3 @@
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS closed_at;
+
+ALTER TABLE markets DROP COLUMN IF EXISTS closed_at;
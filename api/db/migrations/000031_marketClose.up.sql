@@ -0,0 +1,4 @@
+-- markets are closed by the cron job once closes_at has passed; their remaining open prediction intents are closed with them
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_market_refunds_evm_address;
+
+DROP TABLE IF EXISTS market_refunds;
+
+ALTER TABLE markets DROP COLUMN IF EXISTS void_reason;
+ALTER TABLE markets DROP COLUMN IF EXISTS voided_at;
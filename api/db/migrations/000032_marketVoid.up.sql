@@ -0,0 +1,16 @@
+-- voided (cancelled) markets: collateral is refunded 50/50 to YES and NO token holders, per-account refunds are recorded for auditing
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+CREATE TABLE IF NOT EXISTS market_refunds (
+    id SERIAL PRIMARY KEY,
+    market_id UUID NOT NULL REFERENCES markets(market_id) ON DELETE CASCADE,
+    evm_address TEXT NOT NULL,
+    n_yes BIGINT NOT NULL CHECK (n_yes >= 0),
+    n_no BIGINT NOT NULL CHECK (n_no >= 0),
+    refund_amount BIGINT NOT NULL CHECK (refund_amount >= 0), -- (n_yes + n_no) / 2, in collateral token units
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (market_id, evm_address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_refunds_evm_address ON market_refunds(evm_address);
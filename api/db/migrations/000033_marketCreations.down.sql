@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_market_creations_step;
+
+DROP TABLE IF EXISTS market_creations;
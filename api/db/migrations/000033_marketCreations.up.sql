@@ -0,0 +1,20 @@
+-- market creation saga: one row per market_id recording the last completed step (contract -> CLOB -> db) so retries resume instead of starting over
+CREATE TABLE IF NOT EXISTS market_creations (
+    market_id UUID PRIMARY KEY,
+    net VARCHAR(32) NOT NULL,
+    statement TEXT NOT NULL,
+    description TEXT NOT NULL,
+    image_url VARCHAR(2048) NOT NULL DEFAULT '',
+    closes_at TIMESTAMPTZ NOT NULL,
+    category_ids INTEGER[] NOT NULL DEFAULT '{}',
+    smart_contract_id VARCHAR(256), -- set once the market exists on the smart contract
+    remaining_allowance BIGINT NOT NULL DEFAULT 0,
+    step VARCHAR(32) NOT NULL DEFAULT 'pending' CHECK (step IN ('pending', 'contract_created', 'clob_created', 'completed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMPTZ, -- a creation is only driven by one caller at a time
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_creations_step ON market_creations(step) WHERE step <> 'completed';
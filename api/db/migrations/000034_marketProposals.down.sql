@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_market_proposals_account_id;
+DROP INDEX IF EXISTS idx_market_proposals_status;
+
+DROP TABLE IF EXISTS market_proposals;
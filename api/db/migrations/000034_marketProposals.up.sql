@@ -0,0 +1,22 @@
+-- market proposals: drafts signed by the proposer's Hedera key, reviewed by an ADMIN or MODERATOR before anything goes on-chain
+CREATE TABLE IF NOT EXISTS market_proposals (
+    proposal_id UUID PRIMARY KEY, -- becomes the market_id once approved
+    net VARCHAR(32) NOT NULL,
+    statement TEXT NOT NULL,
+    description TEXT NOT NULL,
+    image_url VARCHAR(2048) NOT NULL DEFAULT '',
+    closes_at TIMESTAMPTZ, -- NULL = default duration applied at approval time
+    category_ids INTEGER[] NOT NULL DEFAULT '{}',
+    account_id VARCHAR(32) NOT NULL,
+    public_key VARCHAR(256) NOT NULL,
+    key_type INTEGER NOT NULL,
+    sig VARCHAR(256) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewed_by VARCHAR(32), -- accountId of the reviewer
+    review_reason TEXT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_proposals_status ON market_proposals(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_market_proposals_account_id ON market_proposals(account_id);
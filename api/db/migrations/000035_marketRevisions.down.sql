@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_market_revisions_market_id;
+
+DROP TABLE IF EXISTS market_revisions;
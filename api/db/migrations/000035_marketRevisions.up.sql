@@ -0,0 +1,13 @@
+-- audit trail of admin edits to the off-chain market fields (the on-chain statement is immutable)
+CREATE TABLE IF NOT EXISTS market_revisions (
+    id SERIAL PRIMARY KEY,
+    market_id UUID NOT NULL REFERENCES markets(market_id) ON DELETE CASCADE,
+    field VARCHAR(32) NOT NULL CHECK (field IN ('description', 'image_url', 'closes_at', 'category_ids')),
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    account_id VARCHAR(255) NOT NULL, -- the ADMIN user who made the change
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_revisions_market_id ON market_revisions(market_id, created_at);
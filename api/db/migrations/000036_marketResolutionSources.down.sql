@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_markets_resolution_source;
+
+ALTER TABLE markets DROP COLUMN IF EXISTS resolution_config;
+ALTER TABLE markets DROP COLUMN IF EXISTS resolution_source;
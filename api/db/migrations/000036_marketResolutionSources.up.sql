@@ -0,0 +1,6 @@
+-- every market references one resolution source (manual, http_json or price_threshold) and its JSON config
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS resolution_source VARCHAR(32) NOT NULL DEFAULT 'manual' CHECK (resolution_source IN ('manual', 'http_json', 'price_threshold'));
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS resolution_config JSONB NOT NULL DEFAULT '{}';
+
+-- the cron only polls closed, unresolved markets with an automatic source
+CREATE INDEX IF NOT EXISTS idx_markets_resolution_source ON markets(resolution_source) WHERE resolution_source <> 'manual' AND resolved_at IS NULL AND voided_at IS NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS resolution_disputes;
+
+DROP INDEX IF EXISTS idx_market_resolutions_status;
+
+DROP TABLE IF EXISTS market_resolutions;
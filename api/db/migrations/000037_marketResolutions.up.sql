@@ -0,0 +1,31 @@
+-- proposed outcomes wait out a dispute window before they are finalised on-chain
+CREATE TABLE IF NOT EXISTS market_resolutions (
+    market_id UUID PRIMARY KEY REFERENCES markets(market_id) ON DELETE CASCADE,
+    proposed_outcome BOOLEAN NOT NULL,
+    proposed_by VARCHAR(255) NOT NULL, -- accountId of the ADMIN/ORACLE user, or the resolution source (e.g. source:http_json)
+    proposed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    dispute_ends_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'proposed' CHECK (status IN ('proposed', 'disputed', 'decided', 'finalized')),
+    decision VARCHAR(16) CHECK (decision IN ('undisputed', 'upheld', 'overturned')),
+    final_outcome BOOLEAN,
+    decided_by VARCHAR(255),
+    decision_reason TEXT,
+    decided_at TIMESTAMPTZ,
+    finalized_at TIMESTAMPTZ -- set once the outcome is resolved on-chain
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_resolutions_status ON market_resolutions(status, dispute_ends_at) WHERE status <> 'finalized';
+
+-- disputes filed by holders during the dispute window (signed with the holder's Hedera key)
+CREATE TABLE IF NOT EXISTS resolution_disputes (
+    id SERIAL PRIMARY KEY,
+    market_id UUID NOT NULL REFERENCES market_resolutions(market_id) ON DELETE CASCADE,
+    account_id VARCHAR(32) NOT NULL,
+    evm_address VARCHAR(42) NOT NULL, -- the holder's position on this market is held by this address
+    evidence TEXT NOT NULL,
+    public_key VARCHAR(256) NOT NULL,
+    key_type INTEGER NOT NULL,
+    sig VARCHAR(256) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (market_id, account_id)
+);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_market_status_history_market_id;
+
+DROP TABLE IF EXISTS market_status_history;
+
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS is_paused BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE markets SET is_paused = (status = 'paused');
+
+DROP INDEX IF EXISTS idx_markets_status;
+
+ALTER TABLE markets DROP COLUMN IF EXISTS status;
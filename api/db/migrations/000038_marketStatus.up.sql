@@ -0,0 +1,34 @@
+-- a single lifecycle status replaces is_paused and the resolved_at/closed_at/voided_at checks as the state of a market
+-- (is_suspended stays: suspension hides a market whatever its status)
+-- markets are only inserted once they are on-chain and on the CLOB, so they start open - draft is kept for markets not there yet
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('draft', 'open', 'paused', 'closed', 'resolving', 'resolved', 'voided'));
+
+UPDATE markets m SET status = CASE
+    WHEN m.voided_at IS NOT NULL THEN 'voided'
+    WHEN m.resolved_at IS NOT NULL THEN 'resolved'
+    WHEN EXISTS (SELECT 1 FROM market_resolutions mr WHERE mr.market_id = m.market_id) THEN 'resolving'
+    WHEN m.closed_at IS NOT NULL THEN 'closed'
+    WHEN m.is_paused THEN 'paused'
+    ELSE 'open'
+END;
+
+ALTER TABLE markets DROP COLUMN IF EXISTS is_paused;
+
+CREATE INDEX IF NOT EXISTS idx_markets_status ON markets(status);
+
+-- every status change of a market: who did it and why
+CREATE TABLE IF NOT EXISTS market_status_history (
+    id SERIAL PRIMARY KEY,
+    market_id UUID NOT NULL REFERENCES markets(market_id) ON DELETE CASCADE,
+    from_status VARCHAR(16), -- NULL when the market was created
+    to_status VARCHAR(16) NOT NULL,
+    changed_by VARCHAR(255) NOT NULL, -- accountId, or system for the cron and undisputed resolutions
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_status_history_market_id ON market_status_history(market_id, created_at);
+
+-- the status every existing market starts its history with
+INSERT INTO market_status_history (market_id, from_status, to_status, changed_by, reason)
+SELECT market_id, NULL, status, 'system', 'migrated from is_paused/closed_at/resolved_at/voided_at' FROM markets;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS market_image_upload_chunks;
+
+DROP INDEX IF EXISTS idx_market_image_uploads_expires_at;
+
+DROP TABLE IF EXISTS market_image_uploads;
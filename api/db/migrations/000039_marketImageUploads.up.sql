@@ -0,0 +1,23 @@
+-- resumable, chunked market image uploads - CreateMarketv2 references a completed upload by its upload_id
+CREATE TABLE IF NOT EXISTS market_image_uploads (
+    upload_id UUID PRIMARY KEY,
+    total_size BIGINT NOT NULL CHECK (total_size > 0), -- declared by the client on the first chunk
+    received_bytes BIGINT NOT NULL DEFAULT 0, -- the next chunk must start at this offset
+    status VARCHAR(16) NOT NULL DEFAULT 'uploading' CHECK (status IN ('uploading', 'complete', 'failed')),
+    sha256 VARCHAR(64), -- hex checksum of the whole image, sent with the final chunk
+    image_url TEXT, -- set once complete
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_image_uploads_expires_at ON market_image_uploads(expires_at);
+
+-- received chunks, deleted once the upload is complete
+CREATE TABLE IF NOT EXISTS market_image_upload_chunks (
+    upload_id UUID NOT NULL REFERENCES market_image_uploads(upload_id) ON DELETE CASCADE,
+    chunk_offset BIGINT NOT NULL,
+    data BYTEA NOT NULL,
+    PRIMARY KEY (upload_id, chunk_offset)
+);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_markets_series_id;
+
+ALTER TABLE markets DROP COLUMN IF EXISTS series_id;
+
+DROP INDEX IF EXISTS idx_market_series_occurrences_pending;
+
+DROP TABLE IF EXISTS market_series_occurrences;
+
+DROP TABLE IF EXISTS market_series;
+
+DROP TABLE IF EXISTS market_templates;
@@ -0,0 +1,50 @@
+-- recurring markets: a template says what the market looks like, a series says when (cadence) and with which parameters
+CREATE TABLE IF NOT EXISTS market_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    statement_template TEXT NOT NULL, -- Go text/template, e.g. Will HBAR close above ${{.Params.threshold}} on {{.ClosesAt.Format "Mon Jan 2"}}?
+    description_template TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL,
+    category_ids INTEGER[] NOT NULL DEFAULT '{}',
+    resolution_source VARCHAR(32) NOT NULL DEFAULT 'manual' CHECK (resolution_source IN ('manual', 'http_json', 'price_threshold')),
+    resolution_config_template TEXT NOT NULL DEFAULT '{}', -- rendered like the statement, then validated as the market's resolution_config
+    created_by VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS market_series (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES market_templates(id),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    net VARCHAR(16) NOT NULL,
+    cadence VARCHAR(128) NOT NULL, -- cron expression (with seconds, like CRON_STR) for the closes_at of each market
+    lead_time_hours INTEGER NOT NULL DEFAULT 168 CHECK (lead_time_hours > 0), -- markets are created this long before they close
+    parameter_generator VARCHAR(32) NOT NULL DEFAULT 'static' CHECK (parameter_generator IN ('static', 'http_json_price')),
+    parameter_config JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_error TEXT,
+    created_by VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- one row per generated market - claimed before the market is created so the cron never creates an occurrence twice
+CREATE TABLE IF NOT EXISTS market_series_occurrences (
+    series_id INTEGER NOT NULL REFERENCES market_series(id) ON DELETE CASCADE,
+    closes_at TIMESTAMPTZ NOT NULL,
+    market_id UUID NOT NULL UNIQUE,
+    params JSONB NOT NULL DEFAULT '{}', -- what the parameter generator produced
+    statement TEXT NOT NULL, -- rendered once, so a retried creation is identical
+    description TEXT NOT NULL,
+    resolution_config JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ, -- set once the market exists and is linked to the series
+    PRIMARY KEY (series_id, closes_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_series_occurrences_pending ON market_series_occurrences(created_at) WHERE completed_at IS NULL;
+
+-- generated markets link back to their series
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES market_series(id);
+
+CREATE INDEX IF NOT EXISTS idx_markets_series_id ON markets(series_id) WHERE series_id IS NOT NULL;
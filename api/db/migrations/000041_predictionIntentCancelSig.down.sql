@@ -0,0 +1 @@
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS cancel_sig;
@@ -0,0 +1,2 @@
+-- the signature over the cancel payload (see lib.AssembleCancelPredictionIntentPayload) - NULL when the system cancelled the intent (e.g. eviction)
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS cancel_sig TEXT;
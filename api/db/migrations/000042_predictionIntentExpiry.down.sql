@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_prediction_intents_expires_at;
+
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS expired_at;
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS expiry_sig;
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS expires_at;
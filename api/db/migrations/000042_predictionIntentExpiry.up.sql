@@ -0,0 +1,6 @@
+-- good-till-time orders: expires_at is signed by the account (expiry_sig - see lib.AssemblePredictionIntentExpiryPayload), expired_at is set by the cron
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ; -- NULL => good till cancelled
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS expiry_sig TEXT;
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS expired_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_prediction_intents_expires_at ON prediction_intents(expires_at) WHERE expires_at IS NOT NULL AND expired_at IS NULL;
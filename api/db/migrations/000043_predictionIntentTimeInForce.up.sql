@@ -0,0 +1,2 @@
+-- GTC rests on the book, IOC cancels whatever did not fill at once, FOK fills in full at once or not at all
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS time_in_force VARCHAR(3) NOT NULL DEFAULT 'GTC' CHECK (time_in_force IN ('GTC', 'IOC', 'FOK'));
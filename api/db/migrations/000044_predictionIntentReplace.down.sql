@@ -0,0 +1,2 @@
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS replaces_tx_id;
+ALTER TABLE prediction_intents DROP COLUMN IF EXISTS replaced_by_tx_id;
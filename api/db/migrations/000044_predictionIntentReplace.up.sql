@@ -0,0 +1,3 @@
+-- cancel-replace (ReplacePredictionIntent) links the cancelled intent and its replacement both ways
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS replaced_by_tx_id UUID;
+ALTER TABLE prediction_intents ADD COLUMN IF NOT EXISTS replaces_tx_id UUID;
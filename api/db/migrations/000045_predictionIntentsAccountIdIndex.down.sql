@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_prediction_intents_account_id_open;
@@ -0,0 +1,2 @@
+-- the account-wide risk check (RiskService) reads every open intent of the account on each new order
+CREATE INDEX IF NOT EXISTS idx_prediction_intents_account_id_open ON prediction_intents(account_id) WHERE cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
@@ -0,0 +1,17 @@
+-- trading rules checked at intake: a market's own row wins over its network's row (market_id NULL); with neither, MIN_ORDER_SIZE_USD and the defaults in lib apply
+CREATE TABLE IF NOT EXISTS trading_rules (
+    id SERIAL PRIMARY KEY,
+    net VARCHAR(16) NOT NULL CHECK (net IN ('testnet', 'mainnet', 'previewnet')),
+    market_id UUID REFERENCES markets(market_id) ON DELETE CASCADE,
+    min_notional_usd DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (min_notional_usd >= 0), -- notional = |price_usd| * qty
+    max_notional_usd DOUBLE PRECISION CHECK (max_notional_usd > 0), -- NULL => no maximum
+    price_tick DOUBLE PRECISION NOT NULL CHECK (price_tick > 0 AND price_tick < 1), -- |price_usd| must be a multiple
+    qty_step DOUBLE PRECISION NOT NULL CHECK (qty_step > 0), -- qty must be a multiple
+    updated_by VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (max_notional_usd IS NULL OR max_notional_usd >= min_notional_usd)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trading_rules_net ON trading_rules(net) WHERE market_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trading_rules_market_id ON trading_rules(market_id) WHERE market_id IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE markets DROP COLUMN IF EXISTS clob_removal_failed_at;
@@ -0,0 +1,2 @@
+-- markets whose book could not be removed from the CLOB (when closed, resolved or voided) - retried by the cron
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS clob_removal_failed_at TIMESTAMPTZ;
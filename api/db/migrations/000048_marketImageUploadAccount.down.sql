@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_market_image_uploads_account_id_created_at;
+
+ALTER TABLE market_image_uploads DROP COLUMN IF EXISTS account_id;
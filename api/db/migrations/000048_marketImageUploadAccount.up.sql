@@ -0,0 +1,5 @@
+-- uploads need a login - who started each one, for the per-account quota (uploads started before this belong to no account)
+ALTER TABLE market_image_uploads ADD COLUMN IF NOT EXISTS account_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE market_image_uploads ALTER COLUMN account_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_market_image_uploads_account_id_created_at ON market_image_uploads (account_id, created_at);
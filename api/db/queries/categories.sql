@@ -1,3 +1,19 @@
+-- CREATE
+
+-- name: CreateCategory :one
+INSERT INTO categories (name, description, is_active, sort_order)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: AddMarketCategory :exec
+INSERT INTO market_categories (market_id, category_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+
+
+
+
 -- READ
 
 -- name: GetCategories :many
@@ -10,3 +26,35 @@ SELECT *
 FROM categories
 WHERE is_active = TRUE
 ORDER BY sort_order, name;
+
+-- name: GetMarketCategoryIds :many
+SELECT category_id
+FROM market_categories
+WHERE market_id = $1
+ORDER BY category_id;
+
+
+
+
+
+-- UPDATE
+
+-- name: UpdateCategory :one
+UPDATE categories
+SET name = $2, description = $3, is_active = $4, sort_order = $5, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+
+
+
+
+-- DELETE
+
+-- name: DeleteCategory :execrows
+DELETE FROM categories
+WHERE id = $1;
+
+-- name: DeleteMarketCategories :exec
+DELETE FROM market_categories
+WHERE market_id = $1;
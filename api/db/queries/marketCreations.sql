@@ -0,0 +1,67 @@
+-- CREATE
+
+-- name: StartMarketCreation :one
+-- idempotent per market_id: a retry returns the existing row (with its original parameters) and bumps attempts
+INSERT INTO market_creations (market_id, net, statement, description, image_url, closes_at, category_ids, attempts)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 1)
+ON CONFLICT (market_id)
+DO UPDATE SET
+  attempts = market_creations.attempts + 1,
+  updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+
+
+
+
+
+
+
+-- READ
+
+-- name: GetMarketCreation :one
+SELECT * FROM market_creations
+WHERE market_id = $1;
+
+-- name: GetStuckMarketCreations :many
+-- not completed and nobody is currently driving them
+SELECT * FROM market_creations
+WHERE step <> 'completed' AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+ORDER BY created_at ASC
+LIMIT $1 OFFSET $2;
+
+
+
+
+
+-- UPDATE
+
+-- name: ClaimMarketCreation :one
+UPDATE market_creations
+SET locked_until = CURRENT_TIMESTAMP + INTERVAL '5 minutes', updated_at = CURRENT_TIMESTAMP
+WHERE market_id = $1 AND step <> 'completed' AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: SetMarketCreationContractCreated :one
+UPDATE market_creations
+SET step = 'contract_created', smart_contract_id = $2, remaining_allowance = $3, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE market_id = $1 AND step = 'pending'
+RETURNING *;
+
+-- name: SetMarketCreationClobCreated :one
+UPDATE market_creations
+SET step = 'clob_created', last_error = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE market_id = $1 AND step = 'contract_created'
+RETURNING *;
+
+-- name: SetMarketCreationCompleted :one
+UPDATE market_creations
+SET step = 'completed', last_error = NULL, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE market_id = $1 AND step = 'clob_created'
+RETURNING *;
+
+-- name: SetMarketCreationFailed :exec
+-- records the error and releases the lock so the creation can be resumed
+UPDATE market_creations
+SET last_error = $2, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE market_id = $1;
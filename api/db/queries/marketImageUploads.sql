@@ -0,0 +1,87 @@
+-- CREATE
+
+-- name: CreateMarketImageUpload :one
+-- upload_id is generated by the client - returns no row if it is already taken
+INSERT INTO market_image_uploads (upload_id, total_size, expires_at, account_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (upload_id) DO NOTHING
+RETURNING *;
+
+-- name: CreateMarketImageUploadChunk :exec
+INSERT INTO market_image_upload_chunks (upload_id, chunk_offset, data)
+VALUES ($1, $2, $3);
+
+
+
+
+
+
+
+
+-- READ
+
+-- name: GetMarketImageUpload :one
+SELECT * FROM market_image_uploads
+WHERE upload_id = $1;
+
+-- name: GetMarketImageUploadForUpdate :one
+SELECT * FROM market_image_uploads
+WHERE upload_id = $1
+FOR UPDATE;
+
+-- name: GetMarketImageUploadUsageByAccountId :one
+-- uploads an account started since sqlc.arg('since') (failed and complete ones too) and the bytes they declared
+SELECT COUNT(*)::bigint AS n_uploads, COALESCE(SUM(total_size), 0)::bigint AS total_bytes
+FROM market_image_uploads
+WHERE account_id = sqlc.arg('account_id') AND created_at > sqlc.arg('since');
+
+-- name: GetMarketImageUploadChunks :many
+SELECT data FROM market_image_upload_chunks
+WHERE upload_id = $1
+ORDER BY chunk_offset ASC;
+
+
+
+
+
+
+
+
+-- UPDATE
+
+-- name: AdvanceMarketImageUpload :one
+-- only from the expected offset - a chunk that raced another stream for the same upload is rejected
+UPDATE market_image_uploads
+SET received_bytes = sqlc.arg('received_bytes'), updated_at = CURRENT_TIMESTAMP
+WHERE upload_id = sqlc.arg('upload_id') AND status = 'uploading' AND received_bytes = sqlc.arg('from_received_bytes')
+RETURNING *;
+
+-- name: CompleteMarketImageUpload :one
+UPDATE market_image_uploads
+SET status = 'complete', sha256 = $2, image_url = $3, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE upload_id = $1 AND status = 'uploading'
+RETURNING *;
+
+-- name: FailMarketImageUpload :one
+UPDATE market_image_uploads
+SET status = 'failed', last_error = $2, updated_at = CURRENT_TIMESTAMP
+WHERE upload_id = $1 AND status = 'uploading'
+RETURNING *;
+
+
+
+
+
+
+
+
+-- DELETE
+
+-- name: DeleteMarketImageUploadChunks :exec
+DELETE FROM market_image_upload_chunks
+WHERE upload_id = $1;
+
+-- name: DeleteExpiredMarketImageUploads :execrows
+-- chunks go with them (ON DELETE CASCADE) - the stored images themselves are content-addressed and kept
+DELETE FROM market_image_uploads
+WHERE expires_at < CURRENT_TIMESTAMP;
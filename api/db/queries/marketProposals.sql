@@ -0,0 +1,42 @@
+-- CREATE
+
+-- name: CreateMarketProposal :one
+INSERT INTO market_proposals (proposal_id, net, statement, description, image_url, closes_at, category_ids, account_id, public_key, key_type, sig)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+
+
+
+
+
+
+
+-- READ
+
+-- name: GetMarketProposal :one
+SELECT * FROM market_proposals
+WHERE proposal_id = $1;
+
+-- name: GetMarketProposalsByStatus :many
+-- oldest first so the review queue is worked in order
+SELECT * FROM market_proposals
+WHERE status = $1
+ORDER BY created_at ASC
+LIMIT $2 OFFSET $3;
+
+
+
+
+-- UPDATE
+
+-- name: ReviewMarketProposal :one
+-- only a pending proposal can be reviewed, so two reviewers can not both act on it
+UPDATE market_proposals
+SET
+  status = sqlc.arg('status'),
+  reviewed_by = sqlc.arg('reviewed_by'),
+  review_reason = sqlc.narg('review_reason'),
+  reviewed_at = CURRENT_TIMESTAMP
+WHERE proposal_id = sqlc.arg('proposal_id') AND status = 'pending'
+RETURNING *;
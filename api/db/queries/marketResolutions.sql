@@ -0,0 +1,74 @@
+-- CREATE
+
+-- name: ProposeMarketResolution :one
+-- a market gets one proposal - a second proposal for the same market inserts nothing (sql.ErrNoRows)
+INSERT INTO market_resolutions (market_id, proposed_outcome, proposed_by, dispute_ends_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (market_id) DO NOTHING
+RETURNING *;
+
+-- name: CreateResolutionDispute :one
+INSERT INTO resolution_disputes (market_id, account_id, evm_address, evidence, public_key, key_type, sig)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+
+
+
+
+
+
+
+-- READ
+
+-- name: GetMarketResolution :one
+SELECT * FROM market_resolutions
+WHERE market_id = $1;
+
+-- name: GetResolutionDisputes :many
+SELECT * FROM resolution_disputes
+WHERE market_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetMarketResolutionsDueForFinalization :many
+-- decided resolutions (still to go on-chain) and undisputed proposals whose dispute window has passed
+SELECT mr.* FROM market_resolutions mr
+JOIN markets m ON m.market_id = mr.market_id
+WHERE m.status = 'resolving' AND (
+  mr.status = 'decided'
+  OR (mr.status = 'proposed' AND mr.dispute_ends_at <= CURRENT_TIMESTAMP)
+)
+ORDER BY mr.dispute_ends_at ASC;
+
+
+
+
+-- UPDATE
+
+-- name: SetMarketResolutionDisputed :one
+-- only while the dispute window is open
+UPDATE market_resolutions
+SET status = 'disputed'
+WHERE market_id = $1 AND status IN ('proposed', 'disputed') AND dispute_ends_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DecideMarketResolution :one
+-- from_status guards against two deciders acting on the same resolution
+UPDATE market_resolutions
+SET
+  status = 'decided',
+  decision = sqlc.arg('decision'),
+  final_outcome = sqlc.arg('final_outcome'),
+  decided_by = sqlc.arg('decided_by'),
+  decision_reason = sqlc.narg('decision_reason'),
+  decided_at = CURRENT_TIMESTAMP
+WHERE market_id = sqlc.arg('market_id') AND status = sqlc.arg('from_status')::text
+RETURNING *;
+
+-- name: FinalizeMarketResolution :one
+UPDATE market_resolutions
+SET
+  status = 'finalized',
+  finalized_at = CURRENT_TIMESTAMP
+WHERE market_id = $1 AND status = 'decided'
+RETURNING *;
@@ -0,0 +1,95 @@
+-- CREATE
+
+-- name: CreateMarketTemplate :one
+INSERT INTO market_templates (name, statement_template, description_template, image_url, category_ids, resolution_source, resolution_config_template, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: CreateMarketSeries :one
+INSERT INTO market_series (template_id, name, net, cadence, lead_time_hours, parameter_generator, parameter_config, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: CreateMarketSeriesOccurrence :one
+-- returns no row if this occurrence has already been claimed
+INSERT INTO market_series_occurrences (series_id, closes_at, market_id, params, statement, description, resolution_config)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (series_id, closes_at) DO NOTHING
+RETURNING *;
+
+
+
+
+
+
+
+
+-- READ
+
+-- name: GetMarketTemplate :one
+SELECT * FROM market_templates
+WHERE id = $1;
+
+-- name: GetMarketSeries :one
+SELECT * FROM market_series
+WHERE id = $1;
+
+-- name: GetActiveMarketSeries :many
+SELECT * FROM market_series
+WHERE is_active = TRUE
+ORDER BY id ASC;
+
+-- name: GetLatestMarketSeriesOccurrence :one
+SELECT * FROM market_series_occurrences
+WHERE series_id = $1
+ORDER BY closes_at DESC
+LIMIT 1;
+
+-- name: GetPendingMarketSeriesOccurrences :many
+-- claimed but the market was not (fully) created yet - the cron retries them
+SELECT * FROM market_series_occurrences
+WHERE completed_at IS NULL
+ORDER BY created_at ASC
+LIMIT $1;
+
+-- name: GetMarketSeriesOccurrences :many
+-- series history, newest first, with the state of each generated market
+SELECT
+  o.series_id,
+  o.closes_at,
+  o.market_id,
+  o.statement,
+  o.completed_at,
+  COALESCE(m.status, '')::text AS market_status,
+  m.outcome
+FROM market_series_occurrences o
+LEFT JOIN markets m ON m.market_id = o.market_id
+WHERE o.series_id = sqlc.arg('series_id')
+ORDER BY o.closes_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+
+
+
+
+
+
+
+-- UPDATE
+
+-- name: SetMarketSeriesActive :one
+UPDATE market_series
+SET is_active = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: SetMarketSeriesLastError :exec
+-- NULL clears it after a successful run
+UPDATE market_series
+SET last_error = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CompleteMarketSeriesOccurrence :exec
+UPDATE market_series_occurrences
+SET completed_at = CURRENT_TIMESTAMP
+WHERE series_id = $1 AND closes_at = $2 AND completed_at IS NULL;
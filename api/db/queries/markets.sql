@@ -1,8 +1,31 @@
 -- CREATE
 
 -- name: CreateMarket :one
-INSERT INTO markets (market_id, net, statement, image_url, smart_contract_id, closes_at, description, is_paused, created_at, resolved_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, FALSE, CURRENT_TIMESTAMP, NULL)
+-- the row is only inserted once the market exists on-chain and on the CLOB, so it starts open
+INSERT INTO markets (market_id, net, statement, image_url, smart_contract_id, closes_at, description, status, created_at, resolved_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 'open', CURRENT_TIMESTAMP, NULL)
+RETURNING *;
+
+-- name: CreateMarketModerationLog :one
+INSERT INTO market_moderation_log (market_id, action, account_id, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CreateMarketRefund :one
+-- one refund per holder and market - a re-run void overwrites it with the same amounts
+INSERT INTO market_refunds (market_id, evm_address, n_yes, n_no, refund_amount)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (market_id, evm_address) DO UPDATE SET n_yes = EXCLUDED.n_yes, n_no = EXCLUDED.n_no, refund_amount = EXCLUDED.refund_amount
+RETURNING *;
+
+-- name: CreateMarketStatusHistory :one
+INSERT INTO market_status_history (market_id, from_status, to_status, changed_by, reason)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: CreateMarketRevision :one
+INSERT INTO market_revisions (market_id, field, old_value, new_value, account_id, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 
@@ -18,21 +41,172 @@ RETURNING *;
 SELECT * FROM markets
 WHERE market_id = $1 AND is_suspended = FALSE;
 
+-- name: GetMarketsByIds :many
+-- batch version of GetMarket (avoids one query per market)
+SELECT * FROM markets
+WHERE market_id = ANY(sqlc.arg('market_ids')::uuid[]) AND is_suspended = FALSE;
+
+-- name: GetMarketForUpdate :one
+-- includes suspended markets and locks the row for the rest of the transaction
+SELECT * FROM markets
+WHERE market_id = $1
+FOR UPDATE;
+
+-- name: MarketExists :one
+SELECT EXISTS(SELECT 1 FROM markets WHERE market_id = $1) AS exists;
+
 -- name: GetMarkets :many
 SELECT * FROM markets
 WHERE is_suspended = FALSE
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
+-- name: GetMarketsByCategoryId :many
+SELECT * FROM markets
+WHERE is_suspended = FALSE
+AND market_id IN (SELECT market_id FROM market_categories WHERE category_id = $1)
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: SearchMarkets :many
+-- full-text search + filters, keyset paginated on (sort_key, market_id) - sort_key is always ordered DESC:
+-- newest => created_at, closing_soonest => -closes_at (markets still to close only, soonest first), price => latest price, volume => matched volume (USD)
+WITH filtered AS (
+  SELECT m.*,
+    COALESCE((SELECT ph.price FROM price_history ph WHERE ph.market_id = m.market_id ORDER BY ph.ts DESC LIMIT 1), 0)::float8 AS latest_price_usd,
+    COALESCE((SELECT SUM(ma.qty1 * ABS(pi.price_usd)) FROM matches ma JOIN prediction_intents pi ON pi.tx_id = ma.tx_id1 WHERE ma.market_id = m.market_id), 0)::float8 AS volume_usd
+  FROM markets m
+  WHERE m.is_suspended = FALSE
+  AND (sqlc.narg('query')::text IS NULL OR to_tsvector('english', m.statement || ' ' || m.description) @@ websearch_to_tsquery('english', sqlc.narg('query')::text))
+  AND (sqlc.narg('net')::text IS NULL OR m.net = sqlc.narg('net')::text)
+  AND (sqlc.narg('status')::text IS NULL OR (CASE sqlc.narg('status')::text
+    WHEN 'open' THEN m.status = 'open' AND m.closes_at > CURRENT_TIMESTAMP
+    WHEN 'closed' THEN m.status = 'closed' OR (m.status IN ('open', 'paused') AND m.closes_at <= CURRENT_TIMESTAMP) -- the cron may not have closed it yet
+    ELSE m.status = sqlc.narg('status')::text END))
+  AND (sqlc.narg('closes_after')::timestamptz IS NULL OR m.closes_at >= sqlc.narg('closes_after')::timestamptz)
+  AND (sqlc.narg('closes_before')::timestamptz IS NULL OR m.closes_at < sqlc.narg('closes_before')::timestamptz)
+  AND (sqlc.arg('sort_by')::text <> 'closing_soonest' OR m.closes_at > CURRENT_TIMESTAMP)
+), keyed AS (
+  SELECT filtered.market_id,
+    (CASE sqlc.arg('sort_by')::text
+      WHEN 'closing_soonest' THEN -EXTRACT(EPOCH FROM filtered.closes_at)
+      WHEN 'price' THEN filtered.latest_price_usd
+      WHEN 'volume' THEN filtered.volume_usd
+      ELSE EXTRACT(EPOCH FROM filtered.created_at) END)::float8 AS sort_key
+  FROM filtered
+)
+-- the market row is embedded whole so columns added to markets can't be left out of the results
+SELECT sqlc.embed(markets), keyed.sort_key
+FROM keyed
+JOIN markets ON markets.market_id = keyed.market_id
+WHERE sqlc.narg('cursor_sort_key')::float8 IS NULL
+OR (keyed.sort_key, keyed.market_id) < (sqlc.narg('cursor_sort_key')::float8, sqlc.narg('cursor_market_id')::uuid)
+ORDER BY keyed.sort_key DESC, keyed.market_id DESC
+LIMIT sqlc.arg('row_limit');
+
 -- name: GetAllUnresolvedMarkets :many
+-- the markets that are (or should be) on the CLOB - paused and suspended markets too, halted
 SELECT * FROM markets
-WHERE resolved_at IS NULL AND closes_at > CURRENT_TIMESTAMP AND is_suspended = FALSE
+WHERE status IN ('open', 'paused') AND closes_at > CURRENT_TIMESTAMP
 ORDER BY created_at ASC;
 -- LIMIT $1 OFFSET $2;
 
+-- name: GetMarketsDueForResolution :many
+-- closed markets with an automatic resolution source - once an outcome is proposed the market moves on to resolving
+SELECT * FROM markets
+WHERE resolution_source <> 'manual' AND status = 'closed'
+ORDER BY closed_at ASC;
+
+-- name: GetMarketsDueForClose :many
+SELECT * FROM markets
+WHERE status IN ('open', 'paused') AND closes_at <= CURRENT_TIMESTAMP
+ORDER BY closes_at ASC;
+
+-- name: SetMarketClobRemovalFailed :exec
+-- keeps the time of the first failure
+UPDATE markets
+SET clob_removal_failed_at = COALESCE(clob_removal_failed_at, CURRENT_TIMESTAMP)
+WHERE market_id = $1;
+
+-- name: ClearMarketClobRemovalFailed :exec
+UPDATE markets
+SET clob_removal_failed_at = NULL
+WHERE market_id = $1;
+
+-- name: GetMarketsPendingClobRemoval :many
+SELECT * FROM markets
+WHERE clob_removal_failed_at IS NOT NULL
+ORDER BY clob_removal_failed_at ASC
+LIMIT 100;
+
+-- name: GetMarketRefundsByEvmAddress :many
+SELECT * FROM market_refunds
+WHERE evm_address = $1;
+
+-- name: GetMarketRevisions :many
+SELECT * FROM market_revisions
+WHERE market_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetMarketStats :many
+-- volume uses the same definition as SearchMarkets (qty * |price| of the tx_id1 side of each match)
+WITH ids AS (
+  SELECT m.market_id FROM markets m
+  WHERE m.market_id = ANY(sqlc.arg('market_ids')::uuid[]) AND m.is_suspended = FALSE
+),
+volumes AS (
+  SELECT ma.market_id,
+    SUM(ma.qty1 * ABS(pi.price_usd)) FILTER (WHERE ma.created_at > CURRENT_TIMESTAMP - INTERVAL '1 hour') AS volume_1h,
+    SUM(ma.qty1 * ABS(pi.price_usd)) FILTER (WHERE ma.created_at > CURRENT_TIMESTAMP - INTERVAL '24 hours') AS volume_24h,
+    SUM(ma.qty1 * ABS(pi.price_usd)) FILTER (WHERE ma.created_at > CURRENT_TIMESTAMP - INTERVAL '7 days') AS volume_7d,
+    SUM(ma.qty1 * ABS(pi.price_usd)) AS volume_all_time
+  FROM matches ma
+  JOIN prediction_intents pi ON pi.tx_id = ma.tx_id1
+  WHERE ma.market_id IN (SELECT market_id FROM ids)
+  GROUP BY ma.market_id
+),
+traders AS (
+  SELECT ma.market_id, COUNT(DISTINCT pi.evmaddress) AS n_traders
+  FROM matches ma
+  JOIN prediction_intents pi ON pi.tx_id IN (ma.tx_id1, ma.tx_id2)
+  WHERE ma.market_id IN (SELECT market_id FROM ids)
+  GROUP BY ma.market_id
+),
+open_interest AS (
+  -- every YES token is minted together with a NO token, so the YES supply is the number of outstanding pairs
+  SELECT p.market_id, SUM(p.n_yes) AS n_yes
+  FROM positions p
+  WHERE p.market_id IN (SELECT market_id FROM ids)
+  GROUP BY p.market_id
+)
+SELECT ids.market_id,
+  COALESCE(v.volume_1h, 0)::float8 AS volume_1h_usd,
+  COALESCE(v.volume_24h, 0)::float8 AS volume_24h_usd,
+  COALESCE(v.volume_7d, 0)::float8 AS volume_7d_usd,
+  COALESCE(v.volume_all_time, 0)::float8 AS volume_all_time_usd,
+  COALESCE(oi.n_yes, 0)::bigint AS open_interest,
+  COALESCE(t.n_traders, 0)::bigint AS n_traders,
+  COALESCE((SELECT ph.price FROM price_history ph WHERE ph.market_id = ids.market_id ORDER BY ph.ts DESC LIMIT 1), 0)::float8 AS latest_price_usd,
+  -- the last price at least 24h old - or the first ever price for markets younger than 24h
+  COALESCE(
+    (SELECT ph.price FROM price_history ph WHERE ph.market_id = ids.market_id AND ph.ts <= CURRENT_TIMESTAMP - INTERVAL '24 hours' ORDER BY ph.ts DESC LIMIT 1),
+    (SELECT ph.price FROM price_history ph WHERE ph.market_id = ids.market_id ORDER BY ph.ts ASC LIMIT 1),
+    0
+  )::float8 AS price_24h_ago_usd
+FROM ids
+LEFT JOIN volumes v ON v.market_id = ids.market_id
+LEFT JOIN traders t ON t.market_id = ids.market_id
+LEFT JOIN open_interest oi ON oi.market_id = ids.market_id;
+
 -- name: CountUnresolvedMarkets :one
 SELECT COUNT(*) FROM markets
-WHERE resolved_at IS NULL AND closes_at > CURRENT_TIMESTAMP AND is_suspended = FALSE;
+WHERE status IN ('open', 'paused') AND closes_at > CURRENT_TIMESTAMP AND is_suspended = FALSE;
+
+-- name: GetMarketStatusHistory :many
+SELECT * FROM market_status_history
+WHERE market_id = $1
+ORDER BY created_at ASC, id ASC;
 
 
 
@@ -40,6 +214,60 @@ WHERE resolved_at IS NULL AND closes_at > CURRENT_TIMESTAMP AND is_suspended = F
 
 -- UPDATE
 
+-- N.B. status changes are checked against lib.IsLegalMarketStatusTransition (with the row locked) before these run
+
+-- name: ResolveMarket :one
+UPDATE markets
+SET resolved_at = CURRENT_TIMESTAMP, outcome = $2, status = 'resolved'
+WHERE market_id = $1 AND status = 'resolving'
+RETURNING *;
+
+-- name: CloseMarket :one
+UPDATE markets
+SET closed_at = CURRENT_TIMESTAMP, status = 'closed'
+WHERE market_id = $1 AND status IN ('open', 'paused')
+RETURNING *;
+
+-- name: VoidMarket :one
+UPDATE markets
+SET voided_at = CURRENT_TIMESTAMP, void_reason = $2, status = 'voided'
+WHERE market_id = $1 AND status NOT IN ('resolved', 'voided')
+RETURNING *;
+
+-- name: SetMarketStatus :one
+-- for changes that only move the status (pause, unpause, resolving, ...)
+UPDATE markets
+SET status = sqlc.arg('to_status')
+WHERE market_id = sqlc.arg('market_id') AND status = sqlc.arg('from_status')::text
+RETURNING *;
+
+-- name: SetMarketIsSuspended :one
+-- only trading (open or paused) markets can be suspended - but a suspended market can always be unsuspended, e.g. once it has closed
+UPDATE markets
+SET is_suspended = $2
+WHERE market_id = $1 AND (status IN ('open', 'paused') OR $2 = FALSE)
+RETURNING *;
+
+-- name: SetMarketResolutionSource :one
+UPDATE markets
+SET resolution_source = $2, resolution_config = $3
+WHERE market_id = $1 AND status NOT IN ('resolved', 'voided')
+RETURNING *;
+
+-- name: SetMarketSeries :one
+-- links a market generated by a recurring series back to it
+UPDATE markets
+SET series_id = $2
+WHERE market_id = $1
+RETURNING *;
+
+-- name: UpdateMarketDetails :one
+-- off-chain fields only - the statement is immutable once the market is on-chain
+UPDATE markets
+SET description = $2, image_url = $3, closes_at = $4
+WHERE market_id = $1 AND status NOT IN ('resolved', 'voided')
+RETURNING *;
+
 
 
 
@@ -41,6 +41,18 @@ FROM positions
 WHERE evm_address = $1 AND market_id = $2;
 
 
+-- name: GetHolderPosition :one
+-- evm addresses are compared case-insensitively - the mirror node and the front-end do not agree on case
+SELECT *
+FROM positions
+WHERE market_id = $1 AND LOWER(evm_address) = LOWER(sqlc.arg('evm_address')::text) AND (n_yes > 0 OR n_no > 0);
+
+-- name: GetAllPositionsByMarketId :many
+SELECT *
+FROM positions
+WHERE market_id = $1 AND (n_yes > 0 OR n_no > 0);
+
+
 -- name: GetNumActiveTradersLast30days :one
 SELECT COUNT(DISTINCT evm_address) 
 FROM positions
@@ -1,8 +1,8 @@
 -- CREATE
 
 -- name: CreatePredictionIntent :one
-INSERT INTO prediction_intents (tx_id, net, market_id, account_id, market_limit, price_usd, qty, sig, public_key_hex, evmaddress, keytype, generated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+INSERT INTO prediction_intents (tx_id, net, market_id, account_id, market_limit, price_usd, qty, sig, public_key_hex, evmaddress, keytype, generated_at, expires_at, expiry_sig, time_in_force, replaces_tx_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 RETURNING *;
 
 
@@ -15,26 +15,40 @@ RETURNING *;
 SELECT *
 FROM prediction_intents
 WHERE market_id = $1 
-AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL;
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
 
 -- name: GetAllOpenPredictionIntentsByMarketIdAndAccountId :many
 SELECT *
 FROM prediction_intents
 WHERE market_id = $1 AND account_id = $2 
-AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL
 ORDER BY account_id;
 
 -- name: GetAllAccountIdsForMarketId :many
 SELECT DISTINCT account_id
 FROM prediction_intents
 WHERE market_id = $1 
-AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL;
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
+
+-- name: GetAllAccountIdsWithOpenPredictionIntents :many
+SELECT DISTINCT account_id
+FROM prediction_intents
+WHERE cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
+
+-- name: GetAllOpenPredictionIntentsByAccountId :many
+-- across all markets and networks, oldest first - with the smart contract that settles each intent (its allowance backs the collateral)
+SELECT pi.tx_id, pi.net, pi.market_id, pi.price_usd, pi.qty, m.smart_contract_id
+FROM prediction_intents pi
+JOIN markets m ON m.market_id = pi.market_id
+WHERE pi.account_id = $1
+AND pi.cancelled_at IS NULL AND pi.fully_matched_at IS NULL AND pi.evicted_at IS NULL AND pi.closed_at IS NULL AND pi.expired_at IS NULL
+ORDER BY pi.created_at, pi.tx_id;
 
 -- name: GetAllOpenPredictionIntentsByEvmAddress :many
 SELECT *
 FROM prediction_intents
 WHERE evmaddress = $1 
-AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL;
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
 
 
 -- name: GetAllPredictionIntents :many
@@ -44,6 +58,32 @@ ORDER BY generated_at DESC
 LIMIT $1 OFFSET $2;
 
 
+-- name: GetPredictionIntent :one
+SELECT *
+FROM prediction_intents
+WHERE tx_id = $1;
+
+-- name: GetPredictionIntentFills :many
+-- the fills of one intent, oldest first - the price of a match is that of its tx_id1 side (as in GetMarketStats)
+SELECT ma.id, ma.market_id, ma.tx_hash, ma.created_at,
+  (CASE WHEN ma.tx_id1 = sqlc.arg('tx_id') THEN ma.qty1 ELSE ma.qty2 END)::float8 AS qty,
+  (CASE WHEN ma.tx_id1 = sqlc.arg('tx_id') THEN ma.tx_id2 ELSE ma.tx_id1 END)::uuid AS counterparty_tx_id,
+  ABS(pi.price_usd)::float8 AS price_usd
+FROM matches ma
+JOIN prediction_intents pi ON pi.tx_id = ma.tx_id1
+WHERE ma.tx_id1 = sqlc.arg('tx_id') OR ma.tx_id2 = sqlc.arg('tx_id')
+ORDER BY ma.created_at, ma.id;
+
+
+-- name: GetDuePredictionIntents :many
+-- open good-till-time intents past their expires_at, oldest expiry first
+SELECT tx_id, market_id
+FROM prediction_intents
+WHERE expires_at <= CURRENT_TIMESTAMP
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL
+ORDER BY expires_at;
+
+
 -- name: IsDuplicateTxId :one
 SELECT COUNT(*) > 0 AS exists
 FROM prediction_intents
@@ -66,6 +106,27 @@ SET fully_matched_at = CURRENT_TIMESTAMP
 WHERE market_id = $1 AND tx_id = $2
 RETURNING *;
 
+-- name: MarkPredictionIntentsAsExpired :many
+UPDATE prediction_intents
+SET expired_at = CURRENT_TIMESTAMP
+WHERE tx_id = ANY(sqlc.arg('tx_ids')::uuid[])
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL
+RETURNING tx_id;
+
+-- name: LinkPredictionIntentReplacement :execrows
+-- the replacement is inserted (CreatePredictionIntent) in the same transaction - the intent stays open until the CLOB has swapped the orders
+-- returns no rows if the intent is no longer open, or another replacement of it is already under way
+UPDATE prediction_intents
+SET replaced_by_tx_id = sqlc.arg('replaced_by_tx_id')
+WHERE tx_id = sqlc.arg('tx_id') AND replaced_by_tx_id IS NULL
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
+
+-- name: UnlinkPredictionIntentReplacement :exec
+-- the CLOB did not swap the orders - the replacement is deleted (DeletePredictionIntentReplacement) in the same transaction
+UPDATE prediction_intents
+SET replaced_by_tx_id = NULL
+WHERE tx_id = sqlc.arg('tx_id') AND replaced_by_tx_id = sqlc.arg('replaced_by_tx_id');
+
 -- name: MarkPredictionIntentAsEvicted :exec
 UPDATE prediction_intents
 SET evicted_at = CURRENT_TIMESTAMP
@@ -75,7 +136,50 @@ WHERE tx_id = $1;
 
 -- DELETE
 
--- name: CancelPredictionIntent :exec
+-- name: CancelPredictionIntent :execrows
+-- cancel_sig is NULL when the system (not the account) cancels
+UPDATE prediction_intents
+SET cancelled_at = CURRENT_TIMESTAMP, cancel_sig = sqlc.narg('cancel_sig')
+WHERE tx_id = sqlc.arg('tx_id') AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
+
+-- name: CancelReplacedPredictionIntent :execrows
+-- once the CLOB has swapped the orders - the replacement was linked (LinkPredictionIntentReplacement) beforehand
+UPDATE prediction_intents
+SET cancelled_at = CURRENT_TIMESTAMP, cancel_sig = sqlc.arg('cancel_sig')
+WHERE tx_id = sqlc.arg('tx_id') AND replaced_by_tx_id = sqlc.arg('replaced_by_tx_id')
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
+
+-- name: DeletePredictionIntentReplacement :execrows
+-- a replacement that never reached the CLOB - it has no matches
+DELETE FROM prediction_intents
+WHERE tx_id = sqlc.arg('tx_id') AND replaces_tx_id = sqlc.arg('replaces_tx_id');
+
+-- name: GetCancellablePredictionIntentsByAccountId :many
+-- bulk cancel by the account - optionally one market and/or one side (buy: price_usd >= 0, sell: price_usd < 0)
+-- only intents generated before the cancel was signed, so a replayed cancel can not pull newer orders
+SELECT tx_id, market_id FROM prediction_intents
+WHERE net = sqlc.arg('net') AND account_id = sqlc.arg('account_id')
+AND (sqlc.narg('market_id')::uuid IS NULL OR market_id = sqlc.narg('market_id')::uuid)
+AND (sqlc.narg('side')::text IS NULL OR (sqlc.narg('side')::text = 'buy' AND price_usd >= 0) OR (sqlc.narg('side')::text = 'sell' AND price_usd < 0))
+AND generated_at <= sqlc.arg('generated_before')::timestamp
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL;
+
+-- name: CancelPredictionIntents :many
+-- the orders have already been removed from the CLOB (GetCancellablePredictionIntentsByAccountId)
+UPDATE prediction_intents
+SET cancelled_at = CURRENT_TIMESTAMP, cancel_sig = sqlc.arg('cancel_sig')
+WHERE tx_id = ANY(sqlc.arg('tx_ids')::uuid[])
+AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL
+RETURNING tx_id;
+
+-- name: CancelAllOpenPredictionIntentsByMarketId :many
 UPDATE prediction_intents
 SET cancelled_at = CURRENT_TIMESTAMP
-WHERE tx_id = $1 AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL;
+WHERE market_id = $1 AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL
+RETURNING tx_id;
+
+-- name: CloseAllOpenPredictionIntentsByMarketId :many
+UPDATE prediction_intents
+SET closed_at = CURRENT_TIMESTAMP
+WHERE market_id = $1 AND cancelled_at IS NULL AND fully_matched_at IS NULL AND evicted_at IS NULL AND closed_at IS NULL AND expired_at IS NULL
+RETURNING tx_id;
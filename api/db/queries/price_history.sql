@@ -61,7 +61,7 @@ ORDER BY ts ASC;
 WITH latest AS (
   SELECT DISTINCT ON (market_id) market_id, price, ts
   FROM price_history
-  WHERE market_id = ANY($1)
+  WHERE market_id = ANY(sqlc.arg('market_ids')::uuid[])
   ORDER BY market_id, ts DESC
 )
 SELECT market_id, price, ts
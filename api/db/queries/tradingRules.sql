@@ -0,0 +1,35 @@
+-- CREATE
+
+-- name: UpsertNetworkTradingRules :one
+INSERT INTO trading_rules (net, min_notional_usd, max_notional_usd, price_tick, qty_step, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (net) WHERE market_id IS NULL DO UPDATE
+SET min_notional_usd = EXCLUDED.min_notional_usd, max_notional_usd = EXCLUDED.max_notional_usd, price_tick = EXCLUDED.price_tick, qty_step = EXCLUDED.qty_step, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+RETURNING *;
+
+-- name: UpsertMarketTradingRules :one
+INSERT INTO trading_rules (net, market_id, min_notional_usd, max_notional_usd, price_tick, qty_step, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (market_id) WHERE market_id IS NOT NULL DO UPDATE
+SET min_notional_usd = EXCLUDED.min_notional_usd, max_notional_usd = EXCLUDED.max_notional_usd, price_tick = EXCLUDED.price_tick, qty_step = EXCLUDED.qty_step, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+RETURNING *;
+
+
+
+
+
+-- READ
+
+-- name: GetTradingRules :one
+-- the market's own rules, else its network's
+SELECT *
+FROM trading_rules
+WHERE net = sqlc.arg('net') AND (market_id = sqlc.narg('market_id') OR market_id IS NULL)
+ORDER BY market_id NULLS LAST
+LIMIT 1;
+
+-- name: GetAllNetworkTradingRules :many
+SELECT *
+FROM trading_rules
+WHERE market_id IS NULL
+ORDER BY net;
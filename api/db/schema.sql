@@ -198,6 +198,389 @@ CREATE TABLE public.market_categories (
 
 ALTER TABLE public.market_categories OWNER TO your_db_user;
 
+--
+-- Name: market_creations; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_creations (
+    market_id uuid NOT NULL,
+    net character varying(32) NOT NULL,
+    statement text NOT NULL,
+    description text NOT NULL,
+    image_url character varying(2048) DEFAULT ''::character varying NOT NULL,
+    closes_at timestamp with time zone NOT NULL,
+    category_ids integer[] DEFAULT '{}'::integer[] NOT NULL,
+    smart_contract_id character varying(256),
+    remaining_allowance bigint DEFAULT 0 NOT NULL,
+    step character varying(32) DEFAULT 'pending'::character varying NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    locked_until timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_creations_step_check CHECK (((step)::text = ANY ((ARRAY['pending'::character varying, 'contract_created'::character varying, 'clob_created'::character varying, 'completed'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_creations OWNER TO your_db_user;
+
+--
+-- Name: market_image_upload_chunks; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_image_upload_chunks (
+    upload_id uuid NOT NULL,
+    chunk_offset bigint NOT NULL,
+    data bytea NOT NULL
+);
+
+
+ALTER TABLE public.market_image_upload_chunks OWNER TO your_db_user;
+
+--
+-- Name: market_image_uploads; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_image_uploads (
+    upload_id uuid NOT NULL,
+    total_size bigint NOT NULL,
+    received_bytes bigint DEFAULT 0 NOT NULL,
+    status character varying(16) DEFAULT 'uploading'::character varying NOT NULL,
+    sha256 character varying(64),
+    image_url text,
+    last_error text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    account_id character varying(255) NOT NULL,
+    CONSTRAINT market_image_uploads_status_check CHECK (((status)::text = ANY ((ARRAY['uploading'::character varying, 'complete'::character varying, 'failed'::character varying])::text[]))),
+    CONSTRAINT market_image_uploads_total_size_check CHECK ((total_size > 0))
+);
+
+
+ALTER TABLE public.market_image_uploads OWNER TO your_db_user;
+
+--
+-- Name: market_moderation_log; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_moderation_log (
+    id integer NOT NULL,
+    market_id uuid NOT NULL,
+    action character varying(32) NOT NULL,
+    account_id character varying(255) NOT NULL,
+    reason text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_moderation_log_action_check CHECK (((action)::text = ANY ((ARRAY['pause'::character varying, 'unpause'::character varying, 'suspend'::character varying, 'unsuspend'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_moderation_log OWNER TO your_db_user;
+
+--
+-- Name: market_moderation_log_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.market_moderation_log_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.market_moderation_log_id_seq OWNER TO your_db_user;
+
+--
+-- Name: market_moderation_log_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.market_moderation_log_id_seq OWNED BY public.market_moderation_log.id;
+
+
+--
+-- Name: market_proposals; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_proposals (
+    proposal_id uuid NOT NULL,
+    net character varying(32) NOT NULL,
+    statement text NOT NULL,
+    description text NOT NULL,
+    image_url character varying(2048) DEFAULT ''::character varying NOT NULL,
+    closes_at timestamp with time zone,
+    category_ids integer[] DEFAULT '{}'::integer[] NOT NULL,
+    account_id character varying(32) NOT NULL,
+    public_key character varying(256) NOT NULL,
+    key_type integer NOT NULL,
+    sig character varying(256) NOT NULL,
+    status character varying(16) DEFAULT 'pending'::character varying NOT NULL,
+    reviewed_by character varying(32),
+    review_reason text,
+    reviewed_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_proposals_status_check CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'approved'::character varying, 'rejected'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_proposals OWNER TO your_db_user;
+
+--
+-- Name: market_refunds; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_refunds (
+    id integer NOT NULL,
+    market_id uuid NOT NULL,
+    evm_address text NOT NULL,
+    n_yes bigint NOT NULL,
+    n_no bigint NOT NULL,
+    refund_amount bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_refunds_n_no_check CHECK ((n_no >= 0)),
+    CONSTRAINT market_refunds_n_yes_check CHECK ((n_yes >= 0)),
+    CONSTRAINT market_refunds_refund_amount_check CHECK ((refund_amount >= 0))
+);
+
+
+ALTER TABLE public.market_refunds OWNER TO your_db_user;
+
+--
+-- Name: market_refunds_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.market_refunds_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.market_refunds_id_seq OWNER TO your_db_user;
+
+--
+-- Name: market_refunds_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.market_refunds_id_seq OWNED BY public.market_refunds.id;
+
+
+--
+-- Name: market_resolutions; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_resolutions (
+    market_id uuid NOT NULL,
+    proposed_outcome boolean NOT NULL,
+    proposed_by character varying(255) NOT NULL,
+    proposed_at timestamp with time zone DEFAULT now() NOT NULL,
+    dispute_ends_at timestamp with time zone NOT NULL,
+    status character varying(16) DEFAULT 'proposed'::character varying NOT NULL,
+    decision character varying(16),
+    final_outcome boolean,
+    decided_by character varying(255),
+    decision_reason text,
+    decided_at timestamp with time zone,
+    finalized_at timestamp with time zone,
+    CONSTRAINT market_resolutions_decision_check CHECK (((decision)::text = ANY ((ARRAY['undisputed'::character varying, 'upheld'::character varying, 'overturned'::character varying])::text[]))),
+    CONSTRAINT market_resolutions_status_check CHECK (((status)::text = ANY ((ARRAY['proposed'::character varying, 'disputed'::character varying, 'decided'::character varying, 'finalized'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_resolutions OWNER TO your_db_user;
+
+--
+-- Name: market_revisions; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_revisions (
+    id integer NOT NULL,
+    market_id uuid NOT NULL,
+    field character varying(32) NOT NULL,
+    old_value text NOT NULL,
+    new_value text NOT NULL,
+    account_id character varying(255) NOT NULL,
+    reason text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_revisions_field_check CHECK (((field)::text = ANY ((ARRAY['description'::character varying, 'image_url'::character varying, 'closes_at'::character varying, 'category_ids'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_revisions OWNER TO your_db_user;
+
+--
+-- Name: market_revisions_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.market_revisions_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.market_revisions_id_seq OWNER TO your_db_user;
+
+--
+-- Name: market_revisions_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.market_revisions_id_seq OWNED BY public.market_revisions.id;
+
+
+--
+-- Name: market_series; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_series (
+    id integer NOT NULL,
+    template_id integer NOT NULL,
+    name character varying(255) NOT NULL,
+    net character varying(16) NOT NULL,
+    cadence character varying(128) NOT NULL,
+    lead_time_hours integer DEFAULT 168 NOT NULL,
+    parameter_generator character varying(32) DEFAULT 'static'::character varying NOT NULL,
+    parameter_config jsonb DEFAULT '{}'::jsonb NOT NULL,
+    is_active boolean DEFAULT true NOT NULL,
+    last_error text,
+    created_by character varying(32) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_series_lead_time_hours_check CHECK ((lead_time_hours > 0)),
+    CONSTRAINT market_series_parameter_generator_check CHECK (((parameter_generator)::text = ANY ((ARRAY['static'::character varying, 'http_json_price'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_series OWNER TO your_db_user;
+
+--
+-- Name: market_series_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.market_series_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.market_series_id_seq OWNER TO your_db_user;
+
+--
+-- Name: market_series_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.market_series_id_seq OWNED BY public.market_series.id;
+
+
+--
+-- Name: market_series_occurrences; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_series_occurrences (
+    series_id integer NOT NULL,
+    closes_at timestamp with time zone NOT NULL,
+    market_id uuid NOT NULL,
+    params jsonb DEFAULT '{}'::jsonb NOT NULL,
+    statement text NOT NULL,
+    description text NOT NULL,
+    resolution_config jsonb DEFAULT '{}'::jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    completed_at timestamp with time zone
+);
+
+
+ALTER TABLE public.market_series_occurrences OWNER TO your_db_user;
+
+--
+-- Name: market_status_history; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_status_history (
+    id integer NOT NULL,
+    market_id uuid NOT NULL,
+    from_status character varying(16),
+    to_status character varying(16) NOT NULL,
+    changed_by character varying(255) NOT NULL,
+    reason text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.market_status_history OWNER TO your_db_user;
+
+--
+-- Name: market_status_history_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.market_status_history_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.market_status_history_id_seq OWNER TO your_db_user;
+
+--
+-- Name: market_status_history_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.market_status_history_id_seq OWNED BY public.market_status_history.id;
+
+
+--
+-- Name: market_templates; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.market_templates (
+    id integer NOT NULL,
+    name character varying(255) NOT NULL,
+    statement_template text NOT NULL,
+    description_template text DEFAULT ''::text NOT NULL,
+    image_url text NOT NULL,
+    category_ids integer[] DEFAULT '{}'::integer[] NOT NULL,
+    resolution_source character varying(32) DEFAULT 'manual'::character varying NOT NULL,
+    resolution_config_template text DEFAULT '{}'::text NOT NULL,
+    created_by character varying(32) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT market_templates_resolution_source_check CHECK (((resolution_source)::text = ANY ((ARRAY['manual'::character varying, 'http_json'::character varying, 'price_threshold'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.market_templates OWNER TO your_db_user;
+
+--
+-- Name: market_templates_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.market_templates_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.market_templates_id_seq OWNER TO your_db_user;
+
+--
+-- Name: market_templates_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.market_templates_id_seq OWNED BY public.market_templates.id;
+
+
 --
 -- Name: markets; Type: TABLE; Schema: public; Owner: your_db_user
 --
@@ -206,7 +589,6 @@ CREATE TABLE public.markets (
     market_id uuid NOT NULL,
     net character varying(32) NOT NULL,
     statement text NOT NULL,
-    is_paused boolean DEFAULT true CONSTRAINT markets_is_open_not_null NOT NULL,
     created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
     resolved_at timestamp without time zone,
     image_url character varying(2048),
@@ -215,6 +597,17 @@ CREATE TABLE public.markets (
     closes_at timestamp with time zone DEFAULT (now() + '30 days'::interval) NOT NULL,
     description text NOT NULL,
     is_suspended boolean DEFAULT false NOT NULL,
+    outcome boolean,
+    closed_at timestamp with time zone,
+    voided_at timestamp with time zone,
+    void_reason text,
+    resolution_source character varying(32) DEFAULT 'manual'::character varying NOT NULL,
+    resolution_config jsonb DEFAULT '{}'::jsonb NOT NULL,
+    status character varying(16) DEFAULT 'open'::character varying NOT NULL,
+    series_id integer,
+    clob_removal_failed_at timestamp with time zone,
+    CONSTRAINT markets_resolution_source_check CHECK (((resolution_source)::text = ANY ((ARRAY['manual'::character varying, 'http_json'::character varying, 'price_threshold'::character varying])::text[]))),
+    CONSTRAINT markets_status_check CHECK (((status)::text = ANY ((ARRAY['draft'::character varying, 'open'::character varying, 'paused'::character varying, 'closed'::character varying, 'resolving'::character varying, 'resolved'::character varying, 'voided'::character varying])::text[]))),
     CONSTRAINT smart_contract_id_check CHECK (((length((smart_contract_id)::text) >= 5) AND ((smart_contract_id)::text ~~ '%.%.%'::text)))
 );
 
@@ -363,6 +756,14 @@ CREATE TABLE public.prediction_intents (
     regenerated_at timestamp with time zone,
     fully_matched_at timestamp with time zone,
     evicted_at timestamp with time zone,
+    closed_at timestamp with time zone,
+    cancel_sig text,
+    expires_at timestamp with time zone,
+    expiry_sig text,
+    expired_at timestamp with time zone,
+    time_in_force character varying(3) DEFAULT 'GTC'::character varying NOT NULL,
+    replaced_by_tx_id uuid,
+    replaces_tx_id uuid,
     CONSTRAINT order_requests_account_id_check CHECK ((length(account_id) >= 5)),
     CONSTRAINT order_requests_evmaddress_check CHECK ((length(evmaddress) = 40)),
     CONSTRAINT order_requests_keytype_check CHECK ((keytype = ANY (ARRAY[1, 2, 3]))),
@@ -371,7 +772,8 @@ CREATE TABLE public.prediction_intents (
     CONSTRAINT order_requests_price_usd_check CHECK (((price_usd >= ('-1.0'::numeric)::double precision) AND (price_usd <= (1.0)::double precision))),
     CONSTRAINT order_requests_public_key_hex_check CHECK (((length(public_key_hex) > 10) AND (length(public_key_hex) <= 256))),
     CONSTRAINT order_requests_qty_check CHECK ((qty > (0.0)::double precision)),
-    CONSTRAINT order_requests_sig_check CHECK (((length(sig) > 10) AND (length(sig) < 256)))
+    CONSTRAINT order_requests_sig_check CHECK (((length(sig) > 10) AND (length(sig) < 256))),
+    CONSTRAINT prediction_intents_time_in_force_check CHECK (((time_in_force)::text = ANY ((ARRAY['GTC'::character varying, 'IOC'::character varying, 'FOK'::character varying])::text[])))
 );
 
 
@@ -588,6 +990,47 @@ CREATE TABLE public.price_history_p20260311 (
 
 ALTER TABLE public.price_history_p20260311 OWNER TO your_db_user;
 
+--
+-- Name: resolution_disputes; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.resolution_disputes (
+    id integer NOT NULL,
+    market_id uuid NOT NULL,
+    account_id character varying(32) NOT NULL,
+    evm_address character varying(42) NOT NULL,
+    evidence text NOT NULL,
+    public_key character varying(256) NOT NULL,
+    key_type integer NOT NULL,
+    sig character varying(256) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.resolution_disputes OWNER TO your_db_user;
+
+--
+-- Name: resolution_disputes_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.resolution_disputes_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.resolution_disputes_id_seq OWNER TO your_db_user;
+
+--
+-- Name: resolution_disputes_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.resolution_disputes_id_seq OWNED BY public.resolution_disputes.id;
+
+
 --
 -- Name: roles; Type: TABLE; Schema: public; Owner: your_db_user
 --
@@ -637,6 +1080,54 @@ CREATE TABLE public.schema_migrations (
 
 ALTER TABLE public.schema_migrations OWNER TO your_db_user;
 
+--
+-- Name: trading_rules; Type: TABLE; Schema: public; Owner: your_db_user
+--
+
+CREATE TABLE public.trading_rules (
+    id integer NOT NULL,
+    net character varying(16) NOT NULL,
+    market_id uuid,
+    min_notional_usd double precision DEFAULT 0 NOT NULL,
+    max_notional_usd double precision,
+    price_tick double precision NOT NULL,
+    qty_step double precision NOT NULL,
+    updated_by character varying(32) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT trading_rules_check CHECK (((max_notional_usd IS NULL) OR (max_notional_usd >= min_notional_usd))),
+    CONSTRAINT trading_rules_max_notional_usd_check CHECK ((max_notional_usd > (0)::double precision)),
+    CONSTRAINT trading_rules_min_notional_usd_check CHECK ((min_notional_usd >= (0)::double precision)),
+    CONSTRAINT trading_rules_net_check CHECK (((net)::text = ANY ((ARRAY['testnet'::character varying, 'mainnet'::character varying, 'previewnet'::character varying])::text[]))),
+    CONSTRAINT trading_rules_price_tick_check CHECK (((price_tick > (0)::double precision) AND (price_tick < (1)::double precision))),
+    CONSTRAINT trading_rules_qty_step_check CHECK ((qty_step > (0)::double precision))
+);
+
+
+ALTER TABLE public.trading_rules OWNER TO your_db_user;
+
+--
+-- Name: trading_rules_id_seq; Type: SEQUENCE; Schema: public; Owner: your_db_user
+--
+
+CREATE SEQUENCE public.trading_rules_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.trading_rules_id_seq OWNER TO your_db_user;
+
+--
+-- Name: trading_rules_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.trading_rules_id_seq OWNED BY public.trading_rules.id;
+
+
 --
 -- Name: user_roles; Type: TABLE; Schema: public; Owner: your_db_user
 --
@@ -705,199 +1196,383 @@ CREATE SEQUENCE public.users_id_seq
     CACHE 1;
 
 
-ALTER SEQUENCE public.users_id_seq OWNER TO your_db_user;
+ALTER SEQUENCE public.users_id_seq OWNER TO your_db_user;
+
+--
+-- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+--
+
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+
+
+--
+-- Name: price_history_default; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_default DEFAULT;
+
+
+--
+-- Name: price_history_p20251217; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20251217 FOR VALUES FROM ('2025-12-17 00:00:00+00') TO ('2025-12-24 00:00:00+00');
+
+
+--
+-- Name: price_history_p20251224; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20251224 FOR VALUES FROM ('2025-12-24 00:00:00+00') TO ('2025-12-31 00:00:00+00');
+
+
+--
+-- Name: price_history_p20251231; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20251231 FOR VALUES FROM ('2025-12-31 00:00:00+00') TO ('2026-01-07 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260107; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260107 FOR VALUES FROM ('2026-01-07 00:00:00+00') TO ('2026-01-14 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260114; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260114 FOR VALUES FROM ('2026-01-14 00:00:00+00') TO ('2026-01-21 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260121; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260121 FOR VALUES FROM ('2026-01-21 00:00:00+00') TO ('2026-01-28 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260128; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260128 FOR VALUES FROM ('2026-01-28 00:00:00+00') TO ('2026-02-04 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260204; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260204 FOR VALUES FROM ('2026-02-04 00:00:00+00') TO ('2026-02-11 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260211; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260211 FOR VALUES FROM ('2026-02-11 00:00:00+00') TO ('2026-02-18 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260218; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260218 FOR VALUES FROM ('2026-02-18 00:00:00+00') TO ('2026-02-25 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260225; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260225 FOR VALUES FROM ('2026-02-25 00:00:00+00') TO ('2026-03-04 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260304; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260304 FOR VALUES FROM ('2026-03-04 00:00:00+00') TO ('2026-03-11 00:00:00+00');
+
+
+--
+-- Name: price_history_p20260311; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260311 FOR VALUES FROM ('2026-03-11 00:00:00+00') TO ('2026-03-18 00:00:00+00');
+
+
+--
+-- Name: categories id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.categories ALTER COLUMN id SET DEFAULT nextval('public.categories_id_seq'::regclass);
+
+
+--
+-- Name: comments comment_id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.comments ALTER COLUMN comment_id SET DEFAULT nextval('public.comments_comment_id_seq'::regclass);
+
+
+--
+-- Name: market_moderation_log id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_moderation_log ALTER COLUMN id SET DEFAULT nextval('public.market_moderation_log_id_seq'::regclass);
+
+
+--
+-- Name: market_refunds id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_refunds ALTER COLUMN id SET DEFAULT nextval('public.market_refunds_id_seq'::regclass);
+
+
+--
+-- Name: market_revisions id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_revisions ALTER COLUMN id SET DEFAULT nextval('public.market_revisions_id_seq'::regclass);
+
+
+--
+-- Name: market_series id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_series ALTER COLUMN id SET DEFAULT nextval('public.market_series_id_seq'::regclass);
+
+
+--
+-- Name: market_status_history id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_status_history ALTER COLUMN id SET DEFAULT nextval('public.market_status_history_id_seq'::regclass);
+
+
+--
+-- Name: market_templates id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_templates ALTER COLUMN id SET DEFAULT nextval('public.market_templates_id_seq'::regclass);
+
+
+--
+-- Name: matches id; Type: DEFAULT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.matches ALTER COLUMN id SET DEFAULT nextval('public.matches_id_seq'::regclass);
+
 
 --
--- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: your_db_user
+-- Name: newsletter id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+ALTER TABLE ONLY public.newsletter ALTER COLUMN id SET DEFAULT nextval('public.newsletter_id_seq'::regclass);
 
 
 --
--- Name: price_history_default; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: positions id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_default DEFAULT;
+ALTER TABLE ONLY public.positions ALTER COLUMN id SET DEFAULT nextval('public.positions_id_seq'::regclass);
 
 
 --
--- Name: price_history_p20251217; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: resolution_disputes id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20251217 FOR VALUES FROM ('2025-12-17 00:00:00+00') TO ('2025-12-24 00:00:00+00');
+ALTER TABLE ONLY public.resolution_disputes ALTER COLUMN id SET DEFAULT nextval('public.resolution_disputes_id_seq'::regclass);
 
 
 --
--- Name: price_history_p20251224; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: roles id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20251224 FOR VALUES FROM ('2025-12-24 00:00:00+00') TO ('2025-12-31 00:00:00+00');
+ALTER TABLE ONLY public.roles ALTER COLUMN id SET DEFAULT nextval('public.roles_id_seq'::regclass);
 
 
 --
--- Name: price_history_p20251231; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: trading_rules id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20251231 FOR VALUES FROM ('2025-12-31 00:00:00+00') TO ('2026-01-07 00:00:00+00');
+ALTER TABLE ONLY public.trading_rules ALTER COLUMN id SET DEFAULT nextval('public.trading_rules_id_seq'::regclass);
 
 
 --
--- Name: price_history_p20260107; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: user_roles id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260107 FOR VALUES FROM ('2026-01-07 00:00:00+00') TO ('2026-01-14 00:00:00+00');
+ALTER TABLE ONLY public.user_roles ALTER COLUMN id SET DEFAULT nextval('public.user_roles_id_seq'::regclass);
 
 
 --
--- Name: price_history_p20260114; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: users id; Type: DEFAULT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260114 FOR VALUES FROM ('2026-01-14 00:00:00+00') TO ('2026-01-21 00:00:00+00');
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
 
 
 --
--- Name: price_history_p20260121; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: categories categories_name_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260121 FOR VALUES FROM ('2026-01-21 00:00:00+00') TO ('2026-01-28 00:00:00+00');
+ALTER TABLE ONLY public.categories
+    ADD CONSTRAINT categories_name_key UNIQUE (name);
 
 
 --
--- Name: price_history_p20260128; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: categories categories_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260128 FOR VALUES FROM ('2026-01-28 00:00:00+00') TO ('2026-02-04 00:00:00+00');
+ALTER TABLE ONLY public.categories
+    ADD CONSTRAINT categories_pkey PRIMARY KEY (id);
 
 
 --
--- Name: price_history_p20260204; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: comments comments_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260204 FOR VALUES FROM ('2026-02-04 00:00:00+00') TO ('2026-02-11 00:00:00+00');
+ALTER TABLE ONLY public.comments
+    ADD CONSTRAINT comments_pkey PRIMARY KEY (comment_id);
 
 
 --
--- Name: price_history_p20260211; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: market_categories market_categories_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260211 FOR VALUES FROM ('2026-02-11 00:00:00+00') TO ('2026-02-18 00:00:00+00');
+ALTER TABLE ONLY public.market_categories
+    ADD CONSTRAINT market_categories_pkey PRIMARY KEY (market_id, category_id);
 
 
 --
--- Name: price_history_p20260218; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: market_creations market_creations_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260218 FOR VALUES FROM ('2026-02-18 00:00:00+00') TO ('2026-02-25 00:00:00+00');
+ALTER TABLE ONLY public.market_creations
+    ADD CONSTRAINT market_creations_pkey PRIMARY KEY (market_id);
 
 
 --
--- Name: price_history_p20260225; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: market_image_upload_chunks market_image_upload_chunks_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260225 FOR VALUES FROM ('2026-02-25 00:00:00+00') TO ('2026-03-04 00:00:00+00');
+ALTER TABLE ONLY public.market_image_upload_chunks
+    ADD CONSTRAINT market_image_upload_chunks_pkey PRIMARY KEY (upload_id, chunk_offset);
 
 
 --
--- Name: price_history_p20260304; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: market_image_uploads market_image_uploads_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260304 FOR VALUES FROM ('2026-03-04 00:00:00+00') TO ('2026-03-11 00:00:00+00');
+ALTER TABLE ONLY public.market_image_uploads
+    ADD CONSTRAINT market_image_uploads_pkey PRIMARY KEY (upload_id);
 
 
 --
--- Name: price_history_p20260311; Type: TABLE ATTACH; Schema: public; Owner: your_db_user
+-- Name: market_moderation_log market_moderation_log_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.price_history ATTACH PARTITION public.price_history_p20260311 FOR VALUES FROM ('2026-03-11 00:00:00+00') TO ('2026-03-18 00:00:00+00');
+ALTER TABLE ONLY public.market_moderation_log
+    ADD CONSTRAINT market_moderation_log_pkey PRIMARY KEY (id);
 
 
 --
--- Name: categories id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_proposals market_proposals_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.categories ALTER COLUMN id SET DEFAULT nextval('public.categories_id_seq'::regclass);
+ALTER TABLE ONLY public.market_proposals
+    ADD CONSTRAINT market_proposals_pkey PRIMARY KEY (proposal_id);
 
 
 --
--- Name: comments comment_id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_refunds market_refunds_market_id_evm_address_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.comments ALTER COLUMN comment_id SET DEFAULT nextval('public.comments_comment_id_seq'::regclass);
+ALTER TABLE ONLY public.market_refunds
+    ADD CONSTRAINT market_refunds_market_id_evm_address_key UNIQUE (market_id, evm_address);
 
 
 --
--- Name: matches id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_refunds market_refunds_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.matches ALTER COLUMN id SET DEFAULT nextval('public.matches_id_seq'::regclass);
+ALTER TABLE ONLY public.market_refunds
+    ADD CONSTRAINT market_refunds_pkey PRIMARY KEY (id);
 
 
 --
--- Name: newsletter id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_resolutions market_resolutions_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.newsletter ALTER COLUMN id SET DEFAULT nextval('public.newsletter_id_seq'::regclass);
+ALTER TABLE ONLY public.market_resolutions
+    ADD CONSTRAINT market_resolutions_pkey PRIMARY KEY (market_id);
 
 
 --
--- Name: positions id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_revisions market_revisions_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.positions ALTER COLUMN id SET DEFAULT nextval('public.positions_id_seq'::regclass);
+ALTER TABLE ONLY public.market_revisions
+    ADD CONSTRAINT market_revisions_pkey PRIMARY KEY (id);
 
 
 --
--- Name: roles id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_series market_series_name_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.roles ALTER COLUMN id SET DEFAULT nextval('public.roles_id_seq'::regclass);
+ALTER TABLE ONLY public.market_series
+    ADD CONSTRAINT market_series_name_key UNIQUE (name);
 
 
 --
--- Name: user_roles id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_series market_series_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.user_roles ALTER COLUMN id SET DEFAULT nextval('public.user_roles_id_seq'::regclass);
+ALTER TABLE ONLY public.market_series
+    ADD CONSTRAINT market_series_pkey PRIMARY KEY (id);
 
 
 --
--- Name: users id; Type: DEFAULT; Schema: public; Owner: your_db_user
+-- Name: market_series_occurrences market_series_occurrences_market_id_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+ALTER TABLE ONLY public.market_series_occurrences
+    ADD CONSTRAINT market_series_occurrences_market_id_key UNIQUE (market_id);
 
 
 --
--- Name: categories categories_name_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+-- Name: market_series_occurrences market_series_occurrences_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.categories
-    ADD CONSTRAINT categories_name_key UNIQUE (name);
+ALTER TABLE ONLY public.market_series_occurrences
+    ADD CONSTRAINT market_series_occurrences_pkey PRIMARY KEY (series_id, closes_at);
 
 
 --
--- Name: categories categories_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+-- Name: market_status_history market_status_history_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.categories
-    ADD CONSTRAINT categories_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.market_status_history
+    ADD CONSTRAINT market_status_history_pkey PRIMARY KEY (id);
 
 
 --
--- Name: comments comments_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+-- Name: market_templates market_templates_name_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.comments
-    ADD CONSTRAINT comments_pkey PRIMARY KEY (comment_id);
+ALTER TABLE ONLY public.market_templates
+    ADD CONSTRAINT market_templates_name_key UNIQUE (name);
 
 
 --
--- Name: market_categories market_categories_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+-- Name: market_templates market_templates_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
 
-ALTER TABLE ONLY public.market_categories
-    ADD CONSTRAINT market_categories_pkey PRIMARY KEY (market_id, category_id);
+ALTER TABLE ONLY public.market_templates
+    ADD CONSTRAINT market_templates_pkey PRIMARY KEY (id);
 
 
 --
@@ -1076,6 +1751,22 @@ ALTER TABLE ONLY public.price_history_p20260311
     ADD CONSTRAINT price_history_p20260311_pkey PRIMARY KEY (market_id, ts);
 
 
+--
+-- Name: resolution_disputes resolution_disputes_market_id_account_id_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.resolution_disputes
+    ADD CONSTRAINT resolution_disputes_market_id_account_id_key UNIQUE (market_id, account_id);
+
+
+--
+-- Name: resolution_disputes resolution_disputes_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.resolution_disputes
+    ADD CONSTRAINT resolution_disputes_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: roles roles_name_key; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
@@ -1116,6 +1807,14 @@ ALTER TABLE ONLY public.prediction_intents
     ADD CONSTRAINT unique_tx_id UNIQUE (tx_id);
 
 
+--
+-- Name: trading_rules trading_rules_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.trading_rules
+    ADD CONSTRAINT trading_rules_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: user_roles user_roles_pkey; Type: CONSTRAINT; Schema: public; Owner: your_db_user
 --
@@ -1162,6 +1861,146 @@ CREATE INDEX idx_comments_account_id ON public.comments USING btree (account_id)
 CREATE INDEX idx_comments_market_id ON public.comments USING btree (market_id);
 
 
+--
+-- Name: idx_market_creations_step; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_creations_step ON public.market_creations USING btree (step) WHERE ((step)::text <> 'completed'::text);
+
+
+--
+-- Name: idx_market_image_uploads_account_id_created_at; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_image_uploads_account_id_created_at ON public.market_image_uploads USING btree (account_id, created_at);
+
+
+--
+-- Name: idx_market_image_uploads_expires_at; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_image_uploads_expires_at ON public.market_image_uploads USING btree (expires_at);
+
+
+--
+-- Name: idx_market_moderation_log_market_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_moderation_log_market_id ON public.market_moderation_log USING btree (market_id);
+
+
+--
+-- Name: idx_market_proposals_account_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_proposals_account_id ON public.market_proposals USING btree (account_id);
+
+
+--
+-- Name: idx_market_proposals_status; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_proposals_status ON public.market_proposals USING btree (status, created_at);
+
+
+--
+-- Name: idx_market_refunds_evm_address; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_refunds_evm_address ON public.market_refunds USING btree (evm_address);
+
+
+--
+-- Name: idx_market_resolutions_status; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_resolutions_status ON public.market_resolutions USING btree (status, dispute_ends_at) WHERE ((status)::text <> 'finalized'::text);
+
+
+--
+-- Name: idx_market_revisions_market_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_revisions_market_id ON public.market_revisions USING btree (market_id, created_at);
+
+
+--
+-- Name: idx_market_series_occurrences_pending; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_series_occurrences_pending ON public.market_series_occurrences USING btree (created_at) WHERE (completed_at IS NULL);
+
+
+--
+-- Name: idx_market_status_history_market_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_market_status_history_market_id ON public.market_status_history USING btree (market_id, created_at);
+
+
+--
+-- Name: idx_markets_resolution_source; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_markets_resolution_source ON public.markets USING btree (resolution_source) WHERE (((resolution_source)::text <> 'manual'::text) AND (resolved_at IS NULL) AND (voided_at IS NULL));
+
+
+--
+-- Name: idx_markets_search; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_markets_search ON public.markets USING gin (to_tsvector('english'::regconfig, ((statement || ' '::text) || description)));
+
+
+--
+-- Name: idx_markets_series_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_markets_series_id ON public.markets USING btree (series_id) WHERE (series_id IS NOT NULL);
+
+
+--
+-- Name: idx_markets_status; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_markets_status ON public.markets USING btree (status);
+
+
+--
+-- Name: idx_matches_market_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_matches_market_id ON public.matches USING btree (market_id);
+
+
+--
+-- Name: idx_prediction_intents_account_id_open; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_prediction_intents_account_id_open ON public.prediction_intents USING btree (account_id) WHERE ((cancelled_at IS NULL) AND (fully_matched_at IS NULL) AND (evicted_at IS NULL) AND (closed_at IS NULL) AND (expired_at IS NULL));
+
+
+--
+-- Name: idx_prediction_intents_expires_at; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE INDEX idx_prediction_intents_expires_at ON public.prediction_intents USING btree (expires_at) WHERE ((expires_at IS NOT NULL) AND (expired_at IS NULL));
+
+
+--
+-- Name: idx_trading_rules_market_id; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE UNIQUE INDEX idx_trading_rules_market_id ON public.trading_rules USING btree (market_id) WHERE (market_id IS NOT NULL);
+
+
+--
+-- Name: idx_trading_rules_net; Type: INDEX; Schema: public; Owner: your_db_user
+--
+
+CREATE UNIQUE INDEX idx_trading_rules_net ON public.trading_rules USING btree (net) WHERE (market_id IS NULL);
+
+
 --
 -- Name: price_history_market_id_ts_idx; Type: INDEX; Schema: public; Owner: your_db_user
 --
@@ -1522,6 +2361,94 @@ ALTER TABLE ONLY public.market_categories
     ADD CONSTRAINT market_categories_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
 
 
+--
+-- Name: market_image_upload_chunks market_image_upload_chunks_upload_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_image_upload_chunks
+    ADD CONSTRAINT market_image_upload_chunks_upload_id_fkey FOREIGN KEY (upload_id) REFERENCES public.market_image_uploads(upload_id) ON DELETE CASCADE;
+
+
+--
+-- Name: market_moderation_log market_moderation_log_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_moderation_log
+    ADD CONSTRAINT market_moderation_log_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
+
+
+--
+-- Name: market_refunds market_refunds_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_refunds
+    ADD CONSTRAINT market_refunds_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
+
+
+--
+-- Name: market_resolutions market_resolutions_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_resolutions
+    ADD CONSTRAINT market_resolutions_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
+
+
+--
+-- Name: market_revisions market_revisions_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_revisions
+    ADD CONSTRAINT market_revisions_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
+
+
+--
+-- Name: market_series market_series_template_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_series
+    ADD CONSTRAINT market_series_template_id_fkey FOREIGN KEY (template_id) REFERENCES public.market_templates(id);
+
+
+--
+-- Name: market_series_occurrences market_series_occurrences_series_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_series_occurrences
+    ADD CONSTRAINT market_series_occurrences_series_id_fkey FOREIGN KEY (series_id) REFERENCES public.market_series(id) ON DELETE CASCADE;
+
+
+--
+-- Name: market_status_history market_status_history_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.market_status_history
+    ADD CONSTRAINT market_status_history_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
+
+
+--
+-- Name: markets markets_series_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.markets
+    ADD CONSTRAINT markets_series_id_fkey FOREIGN KEY (series_id) REFERENCES public.market_series(id);
+
+
+--
+-- Name: resolution_disputes resolution_disputes_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.resolution_disputes
+    ADD CONSTRAINT resolution_disputes_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.market_resolutions(market_id) ON DELETE CASCADE;
+
+
+--
+-- Name: trading_rules trading_rules_market_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
+--
+
+ALTER TABLE ONLY public.trading_rules
+    ADD CONSTRAINT trading_rules_market_id_fkey FOREIGN KEY (market_id) REFERENCES public.markets(market_id) ON DELETE CASCADE;
+
+
 --
 -- Name: user_roles user_roles_role_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: your_db_user
 --
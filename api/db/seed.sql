@@ -18,11 +18,13 @@ ON CONFLICT (name) DO NOTHING;
 
 
 -- Seed data for the users/roles/user_roles tables:
--- roles: ADMIN, USER
+-- roles: ADMIN, USER, ORACLE, MODERATOR
 INSERT INTO roles (name, description)
 VALUES
   ('ADMIN', 'Administrator with full access to all resources'),
-  ('USER', 'Regular user with limited access to resources')
+  ('USER', 'Regular user with limited access to resources'),
+  ('ORACLE', 'Oracle permitted to resolve markets'),
+  ('MODERATOR', 'Moderator permitted to review market proposals')
 ON CONFLICT (name) DO NOTHING;
 
 
@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+/*
+*
+Where uploaded objects (e.g. market images) are stored. Put returns the public URL of the object.
+Keys are content hashes, so putting the same key twice stores the same bytes.
+*/
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+}
+
+/*
+*
+Pick the blob store from the BLOB_STORE env var:
+- s3 (default): S3_BUCKET_NAME, credentials from the usual AWS config (IAM role on EC2/ECS)
+- local: files under LOCAL_BLOB_DIR (default ./blobs), served from LOCAL_BLOB_BASE_URL (default file://<dir>) - dev and tests only
+*/
+func NewBlobStore() (BlobStore, error) {
+	switch strings.ToLower(os.Getenv("BLOB_STORE")) {
+	case "", "s3":
+		return NewS3BlobStore(os.Getenv("S3_BUCKET_NAME"))
+	case "local":
+		return NewLocalBlobStore(os.Getenv("LOCAL_BLOB_DIR"), os.Getenv("LOCAL_BLOB_BASE_URL"))
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE: %s (must be s3 or local)", os.Getenv("BLOB_STORE"))
+	}
+}
+
+/////
+// s3
+/////
+
+type S3BlobStore struct {
+	bucket string
+	client *s3.Client
+}
+
+func NewS3BlobStore(bucket string) (*S3BlobStore, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3_BUCKET_NAME environment variable is not set")
+	}
+
+	// Load AWS config (N.B. uses IAM role if running on EC2/ECS)
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS config: %w", err)
+	}
+
+	return &S3BlobStore{
+		bucket: bucket,
+		client: s3.NewFromConfig(cfg),
+	}, nil
+}
+
+func (bs *S3BlobStore) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	_, err := bs.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(bs.bucket),
+		Key:          aws.String(key),
+		Body:         bytes.NewReader(data),
+		ContentType:  aws.String(contentType),
+		CacheControl: aws.String("public, max-age=31536000, immutable"), // content-hash keys never change
+		ACL:          "public-read",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s to S3: %w", key, err)
+	}
+
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bs.bucket, key), nil
+}
+
+/////
+// local filesystem
+/////
+
+type LocalBlobStore struct {
+	dir     string
+	baseUrl string
+}
+
+func NewLocalBlobStore(dir string, baseUrl string) (*LocalBlobStore, error) {
+	if dir == "" {
+		dir = "./blobs"
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOCAL_BLOB_DIR %s: %v", dir, err)
+	}
+	if err := os.MkdirAll(absDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create LOCAL_BLOB_DIR %s: %v", absDir, err)
+	}
+	if baseUrl == "" {
+		baseUrl = "file://" + filepath.ToSlash(absDir)
+	}
+
+	return &LocalBlobStore{
+		dir:     absDir,
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+	}, nil
+}
+
+func (bs *LocalBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	path := filepath.Join(bs.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, bs.dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key: %s", key)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %v", key, err)
+	}
+
+	// write then rename so a reader never sees a partial object
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", key, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write %s: %v", key, err)
+	}
+
+	return bs.baseUrl + "/" + key, nil
+}
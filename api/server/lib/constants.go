@@ -7,4 +7,31 @@ const (
 	NATS_CLOB_MATCHES_PARTIAL  = "clob.matches.partial"
 	NATS_CLOB_MATCHES_WILDCARD = "clob.matches.*"
 	NATS_CLOB_CANCEL_ORDERS    = "clob.orders.cancel"
+	NATS_MARKETS_CLOSED        = "markets.closed" // published once a market passes closes_at and stops trading
+
+	RESOLUTION_SOURCE_HTTP_TIMEOUT_SECONDS = 10
+	RESOLUTION_DISPUTE_WINDOW_HOURS        = 24 // default - override with the RESOLUTION_DISPUTE_WINDOW_HOURS env var
+
+	MARKET_IMAGE_MAX_PIXELS   = 40_000_000 // decompression bomb guard - a 5MB upload can claim a huge canvas
+	MARKET_IMAGE_JPEG_QUALITY = 90
+
+	MARKET_IMAGE_GIF_MAX_FRAMES       = 200         // an animated gif's frames are all decoded - checked before decoding
+	MARKET_IMAGE_GIF_MAX_TOTAL_PIXELS = 100_000_000 // frames x canvas
+
+	MARKET_IMAGE_UPLOAD_MAX_BYTES    = 20 << 20 // chunked uploads (UploadMarketImage) - CreateMarketv2's img_chunk stays capped at 5MB
+	MARKET_IMAGE_UPLOAD_EXPIRY_HOURS = 24       // an upload must complete and be referenced by CreateMarketv2 within this time
+
+	MARKET_IMAGE_UPLOADS_MAX_PER_ACCOUNT      = 20        // uploads an account may start per MARKET_IMAGE_UPLOAD_EXPIRY_HOURS
+	MARKET_IMAGE_UPLOAD_MAX_BYTES_PER_ACCOUNT = 100 << 20 // total declared size of those uploads
+
+	MARKET_SERIES_MAX_MARKETS_PER_RUN = 10 // per series and cron run - a too-frequent cadence can not flood the CLOB
+	MARKET_SERIES_PENDING_RETRY_LIMIT = 20 // occurrences whose market creation failed, retried per cron run
+
+	MARKET_CREATIONS_MAX_LIMIT = 100 // rows per GetStuckMarketCreations page
+
+	TRADING_RULES_DEFAULT_PRICE_TICK = 0.001    // when neither the market nor its network has a trading_rules row
+	TRADING_RULES_DEFAULT_QTY_STEP   = 0.000001 // USDC has 6 decimals
 )
+
+// thumbnails generated for every market image (images wider than these only)
+var MARKET_IMAGE_THUMBNAIL_WIDTHS = []int{160, 480}
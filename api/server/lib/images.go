@@ -0,0 +1,370 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+)
+
+/*
+*
+Process an uploaded market image and store it (plus thumbnails) in the blob store. Returns the URL of the full-size image.
+- the format is sniffed from the magic bytes - the client's file name and MIME type are not trusted
+- only JPEG, PNG and GIF are accepted (SVG can carry scripts and is rejected)
+- the image is decoded and re-encoded, which drops EXIF/XMP and any other metadata (JPEG orientation is applied first)
+- objects are stored under content-hash keys: images/<sha256>.<ext>, with thumbnails at images/<sha256>_w<width>.<ext>
+*/
+func SaveMarketImage(ctx context.Context, store BlobStore, data []byte) (string, error) {
+	// guards
+	if len(data) == 0 {
+		return "", fmt.Errorf("image data is empty")
+	}
+
+	format, err := sniffImageFormat(data)
+	if err != nil {
+		return "", err
+	}
+
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s image header: %v", format, err)
+	}
+	if imgConfig.Width <= 0 || imgConfig.Height <= 0 || imgConfig.Width*imgConfig.Height > MARKET_IMAGE_MAX_PIXELS {
+		return "", fmt.Errorf("image is %dx%d - at most %d pixels are allowed", imgConfig.Width, imgConfig.Height, MARKET_IMAGE_MAX_PIXELS)
+	}
+
+	// OK
+	// Step 1: decode and re-encode (strips metadata)
+	var encoded []byte
+	var firstFrame image.Image
+	switch format {
+	case "gif":
+		// every frame is decoded (each up to the canvas size) - count them first, highly compressed frames are cheap to send
+		nFrames, err := gifFrameCount(data)
+		if err != nil {
+			return "", err
+		}
+		if nFrames > MARKET_IMAGE_GIF_MAX_FRAMES || nFrames*imgConfig.Width*imgConfig.Height > MARKET_IMAGE_GIF_MAX_TOTAL_PIXELS {
+			return "", fmt.Errorf("gif has %d frames of %dx%d - at most %d frames and %d pixels in total are allowed", nFrames, imgConfig.Width, imgConfig.Height, MARKET_IMAGE_GIF_MAX_FRAMES, MARKET_IMAGE_GIF_MAX_TOTAL_PIXELS)
+		}
+
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode gif: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, anim); err != nil {
+			return "", fmt.Errorf("failed to encode gif: %v", err)
+		}
+		encoded = buf.Bytes()
+		firstFrame = anim.Image[0]
+
+	default:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode %s: %v", format, err)
+		}
+		rgba := toRGBA(img)
+		if format == "jpeg" {
+			rgba = applyExifOrientation(rgba, jpegExifOrientation(data))
+		}
+		encoded, err = encodeImage(rgba, format)
+		if err != nil {
+			return "", err
+		}
+		firstFrame = rgba
+	}
+
+	// Step 2: store the full-size image under its content hash
+	hash := sha256.Sum256(encoded)
+	keyPrefix := "images/" + hex.EncodeToString(hash[:])
+	ext, contentType := imageExtAndContentType(format)
+
+	imageUrl, err := store.Put(ctx, keyPrefix+"."+ext, encoded, contentType)
+	if err != nil {
+		return "", fmt.Errorf("failed to store image: %v", err)
+	}
+
+	// Step 3: thumbnails - JPEG stays JPEG, anything else becomes PNG (keeps transparency)
+	thumbnailFormat := "png"
+	if format == "jpeg" {
+		thumbnailFormat = "jpeg"
+	}
+	thumbnailExt, thumbnailContentType := imageExtAndContentType(thumbnailFormat)
+	source := toRGBA(firstFrame)
+	for _, width := range MARKET_IMAGE_THUMBNAIL_WIDTHS {
+		if source.Bounds().Dx() <= width {
+			continue // never upscale
+		}
+		thumbnail, err := encodeImage(resizeToWidth(source, width), thumbnailFormat)
+		if err != nil {
+			return "", err
+		}
+		_, err = store.Put(ctx, fmt.Sprintf("%s_w%d.%s", keyPrefix, width, thumbnailExt), thumbnail, thumbnailContentType)
+		if err != nil {
+			return "", fmt.Errorf("failed to store %dpx thumbnail: %v", width, err)
+		}
+	}
+
+	return imageUrl, nil
+}
+
+// the number of frames (image descriptors) in a GIF - walks the block structure without decoding any pixels
+func gifFrameCount(data []byte) (int, error) {
+	// header (6) + logical screen descriptor (7), then the global color table if there is one
+	if len(data) < 13 {
+		return 0, fmt.Errorf("gif is truncated")
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << ((data[10] & 0x07) + 1)
+	}
+
+	// data sub-blocks: a length byte, then that many bytes - up to a zero length
+	skipSubBlocks := func() error {
+		for {
+			if pos >= len(data) {
+				return fmt.Errorf("gif is truncated")
+			}
+			n := int(data[pos])
+			pos += 1 + n
+			if n == 0 {
+				return nil
+			}
+		}
+	}
+
+	nFrames := 0
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // extension: label, then sub-blocks
+			pos += 2
+			if err := skipSubBlocks(); err != nil {
+				return 0, err
+			}
+		case 0x2C: // image descriptor (10), local color table, LZW minimum code size (1), then sub-blocks
+			if pos+10 > len(data) {
+				return 0, fmt.Errorf("gif is truncated")
+			}
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << ((flags & 0x07) + 1)
+			}
+			pos++
+			if err := skipSubBlocks(); err != nil {
+				return 0, err
+			}
+			nFrames++
+		case 0x3B: // trailer
+			return nFrames, nil
+		default:
+			return 0, fmt.Errorf("gif has an unknown block 0x%02x", data[pos])
+		}
+	}
+	return nFrames, nil // no trailer - gif.DecodeAll decides
+}
+
+// jpeg, png or gif - anything else (notably SVG) is rejected
+func sniffImageFormat(data []byte) (string, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "jpeg", nil
+	case "image/png":
+		return "png", nil
+	case "image/gif":
+		return "gif", nil
+	}
+
+	head := strings.ToLower(string(data[:min(len(data), 1024)]))
+	if strings.Contains(head, "<svg") {
+		return "", fmt.Errorf("SVG images are not accepted - upload a JPEG, PNG or GIF")
+	}
+	return "", fmt.Errorf("unsupported image format %s - upload a JPEG, PNG or GIF", http.DetectContentType(data))
+}
+
+func imageExtAndContentType(format string) (string, string) {
+	switch format {
+	case "jpeg":
+		return "jpg", "image/jpeg"
+	case "gif":
+		return "gif", "image/gif"
+	default:
+		return "png", "image/png"
+	}
+}
+
+func encodeImage(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: MARKET_IMAGE_JPEG_QUALITY})
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %v", format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// box filter downscale - each destination pixel is the average of the source pixels it covers
+func resizeToWidth(src *image.RGBA, width int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	height := max(1, srcH*width/srcW)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		sy0 := y * srcH / height
+		sy1 := max(sy0+1, (y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			sx0 := x * srcW / width
+			sx1 := max(sx0+1, (x+1)*srcW/width)
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				i := src.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint64(src.Pix[i])
+					g += uint64(src.Pix[i+1])
+					b += uint64(src.Pix[i+2])
+					a += uint64(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+/////
+// EXIF orientation - applied before the metadata is dropped so photos taken in portrait stay upright
+/////
+
+// the EXIF orientation (1-8) of a JPEG - 1 (as stored) if there is none
+func jpegExifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan / end of image - no more metadata
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var byteOrder binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		byteOrder = binary.LittleEndian
+	case "MM":
+		byteOrder = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(byteOrder.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	nEntries := int(byteOrder.Uint16(tiff[ifd:]))
+	for k := 0; k < nEntries; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if byteOrder.Uint16(tiff[entry:]) == 0x0112 { // Orientation
+			orientation := int(byteOrder.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// rotate/flip so the image displays as intended once the EXIF orientation is gone
+func applyExifOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 { // 5-8 swap width and height
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
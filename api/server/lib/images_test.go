@@ -0,0 +1,293 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// a w x h image, red on the left half and blue on the right
+func testImage(w int, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func encodeTestImage(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95})
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatalf("failed to encode test %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// insert an APP1 EXIF segment (big-endian TIFF, one Orientation entry) right after the JPEG SOI marker
+func withExifOrientation(jpegData []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08") // header, IFD at offset 8
+	entry := make([]byte, 2+12+4)
+	binary.BigEndian.PutUint16(entry[0:], 1)      // 1 entry
+	binary.BigEndian.PutUint16(entry[2:], 0x0112) // Orientation
+	binary.BigEndian.PutUint16(entry[4:], 3)      // SHORT
+	binary.BigEndian.PutUint32(entry[6:], 1)      // count
+	binary.BigEndian.PutUint16(entry[10:], orientation)
+	segment := append([]byte("Exif\x00\x00"), append(tiff, entry...)...)
+
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, app1...)
+	return append(out, jpegData[2:]...)
+}
+
+func newTestBlobStore(t *testing.T) (*LocalBlobStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := NewLocalBlobStore(dir, "http://blobs.test")
+	if err != nil {
+		t.Fatalf("NewLocalBlobStore() error = %v", err)
+	}
+	return store, dir
+}
+
+func TestSniffImageFormat(t *testing.T) {
+	img := testImage(8, 8)
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr string
+	}{
+		{"jpeg", encodeTestImage(t, img, "jpeg"), "jpeg", ""},
+		{"png", encodeTestImage(t, img, "png"), "png", ""},
+		{"gif", encodeTestImage(t, img, "gif"), "gif", ""},
+		{"svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), "", "SVG"},
+		{"svg without xml declaration", []byte(`<SVG onload="alert(1)"></SVG>`), "", "SVG"},
+		{"html", []byte(`<html><body>hello</body></html>`), "", "unsupported"},
+		{"text", []byte("not an image"), "", "unsupported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sniffImageFormat(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("sniffImageFormat() = %s, %v - want an error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("sniffImageFormat() = %s, %v - want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveMarketImageRejects(t *testing.T) {
+	store, dir := newTestBlobStore(t)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)},
+		{"truncated png", encodeTestImage(t, testImage(8, 8), "png")[:40]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SaveMarketImage(context.Background(), store, tt.data); err == nil {
+				t.Error("SaveMarketImage() should fail")
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("rejected images must not be stored, found %d entries", len(entries))
+	}
+}
+
+func TestSaveMarketImageContentHashKeysAndThumbnails(t *testing.T) {
+	store, dir := newTestBlobStore(t)
+	data := encodeTestImage(t, testImage(600, 300), "png")
+
+	url, err := SaveMarketImage(context.Background(), store, data)
+	if err != nil {
+		t.Fatalf("SaveMarketImage() error = %v", err)
+	}
+	if !strings.HasPrefix(url, "http://blobs.test/images/") || !strings.HasSuffix(url, ".png") {
+		t.Fatalf("SaveMarketImage() = %s, want http://blobs.test/images/<sha256>.png", url)
+	}
+	key := strings.TrimSuffix(strings.TrimPrefix(url, "http://blobs.test/images/"), ".png")
+	if len(key) != 64 {
+		t.Errorf("key %s is not a sha256 hex digest", key)
+	}
+
+	// the same image is stored under the same key
+	again, err := SaveMarketImage(context.Background(), store, data)
+	if err != nil || again != url {
+		t.Errorf("SaveMarketImage() again = %s, %v - want %s", again, err, url)
+	}
+
+	for _, width := range MARKET_IMAGE_THUMBNAIL_WIDTHS {
+		f, err := os.Open(filepath.Join(dir, "images", key+"_w"+strconv.Itoa(width)+".png"))
+		if err != nil {
+			t.Fatalf("missing %dpx thumbnail: %v", width, err)
+		}
+		cfg, err := png.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%dpx thumbnail is not a png: %v", width, err)
+		}
+		if cfg.Width != width || cfg.Height != width/2 {
+			t.Errorf("%dpx thumbnail is %dx%d, want %dx%d", width, cfg.Width, cfg.Height, width, width/2)
+		}
+	}
+}
+
+func TestSaveMarketImageNeverUpscales(t *testing.T) {
+	store, dir := newTestBlobStore(t)
+
+	if _, err := SaveMarketImage(context.Background(), store, encodeTestImage(t, testImage(100, 50), "png")); err != nil {
+		t.Fatalf("SaveMarketImage() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("a 100px image should be stored without thumbnails, found %d objects", len(entries))
+	}
+}
+
+func TestSaveMarketImageStripsExifAndAppliesOrientation(t *testing.T) {
+	store, dir := newTestBlobStore(t)
+	data := withExifOrientation(encodeTestImage(t, testImage(40, 20), "jpeg"), 6) // rotated 90 clockwise
+	if jpegExifOrientation(data) != 6 {
+		t.Fatalf("test image has no EXIF orientation")
+	}
+
+	url, err := SaveMarketImage(context.Background(), store, data)
+	if err != nil {
+		t.Fatalf("SaveMarketImage() error = %v", err)
+	}
+
+	stored, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(url, "http://blobs.test/"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, []byte("Exif\x00\x00")) {
+		t.Error("stored image still carries EXIF")
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(stored))
+	if err != nil {
+		t.Fatalf("stored image is not a jpeg: %v", err)
+	}
+	if cfg.Width != 20 || cfg.Height != 40 {
+		t.Errorf("stored image is %dx%d, want 20x40 (orientation applied)", cfg.Width, cfg.Height)
+	}
+}
+
+func TestSaveMarketImagePixelLimit(t *testing.T) {
+	store, _ := newTestBlobStore(t)
+
+	// a png header claiming a huge canvas - rejected before anything is decoded
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 100_000) // IHDR width
+	binary.BigEndian.PutUint32(data[20:], 100_000) // IHDR height
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := SaveMarketImage(context.Background(), store, data)
+	if err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Errorf("SaveMarketImage() error = %v, want a pixel limit error", err)
+	}
+}
+
+// an animated gif of n frames, each covering the whole w x h canvas
+func testAnimatedGif(t *testing.T, w int, h int, n int) []byte {
+	t.Helper()
+	palette := color.Palette{color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	anim := &gif.GIF{}
+	for i := 0; i < n; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, w, h), palette)
+		frame.SetColorIndex(i%w, 0, 1)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("failed to encode test gif: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGifFrameCount(t *testing.T) {
+	for _, n := range []int{1, 3, 25} {
+		got, err := gifFrameCount(testAnimatedGif(t, 16, 8, n))
+		if err != nil || got != n {
+			t.Errorf("gifFrameCount() = %d, %v - want %d", got, err, n)
+		}
+	}
+
+	data := testAnimatedGif(t, 16, 8, 3)
+	if _, err := gifFrameCount(data[:len(data)-10]); err == nil {
+		t.Error("gifFrameCount() of a truncated gif should fail")
+	}
+}
+
+// frames are counted before any of them is decoded
+func TestSaveMarketImageGifFrameLimits(t *testing.T) {
+	store, _ := newTestBlobStore(t)
+
+	if _, err := SaveMarketImage(context.Background(), store, testAnimatedGif(t, 16, 8, 5)); err != nil {
+		t.Errorf("SaveMarketImage() of a 5 frame gif error = %v", err)
+	}
+
+	_, err := SaveMarketImage(context.Background(), store, testAnimatedGif(t, 4, 4, MARKET_IMAGE_GIF_MAX_FRAMES+1))
+	if err == nil || !strings.Contains(err.Error(), "frames") {
+		t.Errorf("SaveMarketImage() of %d frames error = %v, want the frame limit", MARKET_IMAGE_GIF_MAX_FRAMES+1, err)
+	}
+
+	// few frames, but frames x canvas is over the total
+	side := 6000 // 36MP canvas, under MARKET_IMAGE_MAX_PIXELS
+	nFrames := MARKET_IMAGE_GIF_MAX_TOTAL_PIXELS/(side*side) + 1
+	_, err = SaveMarketImage(context.Background(), store, testAnimatedGif(t, side, side, nFrames))
+	if err == nil || !strings.Contains(err.Error(), "in total") {
+		t.Errorf("SaveMarketImage() of %d frames of %dx%d error = %v, want the total pixel limit", nFrames, side, side, err)
+	}
+}
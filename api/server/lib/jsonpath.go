@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+*
+Evaluate a (small) subset of JSONPath against a document decoded with encoding/json:
+$ root, .key child, ['key'] / ["key"] quoted child and [n] array index, e.g. $.data.events[0]['home team'].score
+*/
+func JsonPathLookup(doc interface{}, path string) (interface{}, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("jsonPath must start with '$': %s", path)
+	}
+	p = p[1:]
+
+	current := doc
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			key := p[:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in jsonPath: %s", path)
+			}
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("jsonPath %s: '%s' is not an object key", path, key)
+			}
+			current, ok = obj[key]
+			if !ok {
+				return nil, fmt.Errorf("jsonPath %s: key '%s' not found", path, key)
+			}
+			p = p[end:]
+		case '[':
+			end := strings.Index(p, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated '[' in jsonPath: %s", path)
+			}
+			selector := p[1:end]
+			p = p[end+1:]
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				key := selector[1 : len(selector)-1]
+				obj, ok := current.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("jsonPath %s: '%s' is not an object key", path, key)
+				}
+				current, ok = obj[key]
+				if !ok {
+					return nil, fmt.Errorf("jsonPath %s: key '%s' not found", path, key)
+				}
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("jsonPath %s: invalid array index '%s'", path, selector)
+			}
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("jsonPath %s: [%d] applied to a non-array", path, index)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("jsonPath %s: index %d out of range (len %d)", path, index, len(arr))
+			}
+			current = arr[index]
+		default:
+			return nil, fmt.Errorf("unexpected '%c' in jsonPath: %s", p[0], path)
+		}
+	}
+
+	return current, nil
+}
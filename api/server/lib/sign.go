@@ -82,6 +82,105 @@ func AssemblePayloadHexForSigning(req *pb_api.PredictionIntentRequest, usdcDecim
 	return payloadHex, nil
 }
 
+/**
+* Assembles the message an account signs for the expires_at of a good-till-time PredictionIntentRequest
+* Kept apart from AssemblePayloadHexForSigning, whose payload is also verified on-chain by the smart contract
+* Fields are joined with '\n' in a fixed order, after the literal "expiresAt"
+* @param req PredictionIntentRequest object from front-end
+* @returns the utf8 payload (hex-encode with Utf82hex before verifying)
+ */
+func AssemblePredictionIntentExpiryPayload(req *pb_api.PredictionIntentRequest) string {
+	return strings.Join([]string{
+		"expiresAt",
+		strings.ToLower(req.TxId),
+		req.GetExpiresAt(),
+	}, "\n")
+}
+
+/**
+* Assembles the message a proposer signs for a ProposeMarketRequest
+* Fields are joined with '\n' in a fixed order - an omitted closesAt is an empty line
+* and categoryIds are comma-separated in the order sent
+* @param req ProposeMarketRequest object from front-end
+* @returns the utf8 payload (hex-encode with Utf82hex before verifying)
+ */
+func AssembleMarketProposalPayload(req *pb_api.ProposeMarketRequest) string {
+	categoryIds := make([]string, len(req.CategoryIds))
+	for i, id := range req.CategoryIds {
+		categoryIds[i] = strconv.Itoa(int(id))
+	}
+
+	return strings.Join([]string{
+		strings.ToLower(req.MarketId),
+		req.Net,
+		req.Statement,
+		req.Description,
+		req.ImageUrl,
+		req.GetClosesAt(),
+		strings.Join(categoryIds, ","),
+	}, "\n")
+}
+
+/**
+* Assembles the message a holder signs for a FileResolutionDisputeRequest
+* Fields are joined with '\n' in a fixed order
+* @param req FileResolutionDisputeRequest object from front-end
+* @returns the utf8 payload (hex-encode with Utf82hex before verifying)
+ */
+func AssembleResolutionDisputePayload(req *pb_api.FileResolutionDisputeRequest) string {
+	return strings.Join([]string{
+		strings.ToLower(req.MarketId),
+		req.AccountId,
+		req.Evidence,
+	}, "\n")
+}
+
+/**
+* Assembles the message an account signs to cancel one of its prediction intents
+* Fields are joined with '\n' in a fixed order, after the literal "cancel" so the signature can not be mistaken for any other payload
+* @param req CancelOrderRequest object from front-end
+* @returns the utf8 payload (hex-encode with Utf82hex before verifying)
+ */
+func AssembleCancelPredictionIntentPayload(req *pb_api.CancelOrderRequest) string {
+	return strings.Join([]string{
+		"cancel",
+		strings.ToLower(req.MarketId),
+		strings.ToLower(req.TxId),
+	}, "\n")
+}
+
+/**
+* Assembles the message an account signs to cancel all of its open prediction intents
+* Fields are joined with '\n' in a fixed order, after the literal "cancelAll" - an omitted marketId or side is an empty line
+* @param req CancelAllPredictionIntentsRequest object from front-end
+* @returns the utf8 payload (hex-encode with Utf82hex before verifying)
+ */
+func AssembleCancelAllPredictionIntentsPayload(req *pb_api.CancelAllPredictionIntentsRequest) string {
+	return strings.Join([]string{
+		"cancelAll",
+		req.Net,
+		req.AccountId,
+		strings.ToLower(req.GetMarketId()),
+		req.GetSide(),
+		req.GeneratedAt,
+	}, "\n")
+}
+
+/**
+* Assembles the message an account signs to replace one of its open prediction intents with another
+* Fields are joined with '\n' in a fixed order, after the literal "replace" - the replacement carries its own (on-chain) signature
+* @param req ReplacePredictionIntentRequest object from front-end
+* @returns the utf8 payload (hex-encode with Utf82hex before verifying)
+ */
+func AssembleReplacePredictionIntentPayload(req *pb_api.ReplacePredictionIntentRequest) string {
+	return strings.Join([]string{
+		"replace",
+		strings.ToLower(req.Replacement.GetMarketId()),
+		strings.ToLower(req.TxId),
+		strings.ToLower(req.Replacement.GetTxId()),
+	}, "\n")
+}
+
 func Uuid7_to_bigint(uuid7 string) (*big.Int, error) {
 	// Remove all hyphens from the UUID7 string
 	uuid7Cleaned := strings.ReplaceAll(uuid7, "-", "")
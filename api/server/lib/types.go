@@ -21,7 +21,132 @@ const (
 type RolesType string
 
 const (
-	ADMIN RolesType = "ADMIN"
-	USER  RolesType = "USER"
+	ADMIN     RolesType = "ADMIN"
+	USER      RolesType = "USER"
+	ORACLE    RolesType = "ORACLE"    // can resolve markets
+	MODERATOR RolesType = "MODERATOR" // can review market proposals
 	// Future roles
 )
+
+type MarketModerationActionType string
+
+const (
+	MARKET_PAUSE     MarketModerationActionType = "pause"
+	MARKET_UNPAUSE   MarketModerationActionType = "unpause"
+	MARKET_SUSPEND   MarketModerationActionType = "suspend"
+	MARKET_UNSUSPEND MarketModerationActionType = "unsuspend"
+)
+
+// markets.status - the lifecycle of a market (suspension is a separate flag: it hides a market whatever its status)
+const (
+	MARKET_STATUS_DRAFT     = "draft"     // not on-chain / on the CLOB yet
+	MARKET_STATUS_OPEN      = "open"      // trading
+	MARKET_STATUS_PAUSED    = "paused"    // an ADMIN stopped matching
+	MARKET_STATUS_CLOSED    = "closed"    // passed closes_at (or an outcome is about to be proposed) - no more trading
+	MARKET_STATUS_RESOLVING = "resolving" // outcome proposed, dispute window running
+	MARKET_STATUS_RESOLVED  = "resolved"  // outcome resolved on-chain
+	MARKET_STATUS_VOIDED    = "voided"    // cancelled - holders are refunded 50/50
+)
+
+// market_status_history.changed_by for changes nobody in particular asked for (cron, undisputed resolutions)
+const MARKET_STATUS_CHANGED_BY_SYSTEM = "system"
+
+// market_creations.step - the last completed step of the market creation saga
+const (
+	MARKET_CREATION_PENDING          = "pending"
+	MARKET_CREATION_CONTRACT_CREATED = "contract_created"
+	MARKET_CREATION_CLOB_CREATED     = "clob_created"
+	MARKET_CREATION_COMPLETED        = "completed"
+)
+
+// market_revisions.field - the off-chain market fields an ADMIN can edit
+const (
+	MARKET_REVISION_DESCRIPTION  = "description"
+	MARKET_REVISION_IMAGE_URL    = "image_url"
+	MARKET_REVISION_CLOSES_AT    = "closes_at"
+	MARKET_REVISION_CATEGORY_IDS = "category_ids"
+)
+
+// markets.resolution_source - where the outcome of a market comes from
+const (
+	RESOLUTION_SOURCE_MANUAL          = "manual"          // an ADMIN or ORACLE calls ResolveMarket
+	RESOLUTION_SOURCE_HTTP_JSON       = "http_json"       // a value read from an HTTP JSON endpoint with a JSONPath rule
+	RESOLUTION_SOURCE_PRICE_THRESHOLD = "price_threshold" // a price read from an HTTP JSON endpoint is above a threshold at time T
+)
+
+// market_resolutions.status - proposed -> (disputed ->) decided -> finalized (resolved on-chain)
+const (
+	MARKET_RESOLUTION_PROPOSED  = "proposed"
+	MARKET_RESOLUTION_DISPUTED  = "disputed"
+	MARKET_RESOLUTION_DECIDED   = "decided"
+	MARKET_RESOLUTION_FINALIZED = "finalized"
+)
+
+// market_resolutions.decision
+const (
+	RESOLUTION_DECISION_UNDISPUTED = "undisputed" // the dispute window passed without a dispute
+	RESOLUTION_DECISION_UPHELD     = "upheld"     // an ADMIN kept the proposed outcome
+	RESOLUTION_DECISION_OVERTURNED = "overturned" // an ADMIN flipped the proposed outcome
+)
+
+// market_proposals.status
+const (
+	MARKET_PROPOSAL_PENDING  = "pending"
+	MARKET_PROPOSAL_APPROVED = "approved"
+	MARKET_PROPOSAL_REJECTED = "rejected"
+)
+
+// market_series.parameter_generator - where the parameters of each generated market come from
+const (
+	SERIES_PARAMETERS_STATIC          = "static"          // the same params for every market
+	SERIES_PARAMETERS_HTTP_JSON_PRICE = "http_json_price" // a price read from an HTTP JSON endpoint when the market is generated
+)
+
+// market_image_uploads.status
+const (
+	MARKET_IMAGE_UPLOAD_UPLOADING = "uploading" // waiting for the chunk at received_bytes
+	MARKET_IMAGE_UPLOAD_COMPLETE  = "complete"  // checksum verified and the image stored - CreateMarketv2 can reference it
+	MARKET_IMAGE_UPLOAD_FAILED    = "failed"    // checksum mismatch or not a valid image - start a new upload
+)
+
+// the status of a prediction intent - derived from the *_at columns of prediction_intents (see GetPredictionIntent)
+const (
+	PREDICTION_INTENT_OPEN             = "open"             // on the book, nothing matched yet
+	PREDICTION_INTENT_PARTIALLY_FILLED = "partially_filled" // on the book, part of qty matched - or an IOC whose unfilled remainder was cancelled
+	PREDICTION_INTENT_FILLED           = "filled"           // fully_matched_at
+	PREDICTION_INTENT_CANCELLED        = "cancelled"        // cancelled_at
+	PREDICTION_INTENT_EVICTED          = "evicted"          // evicted_at - the account could no longer fund it
+	PREDICTION_INTENT_CLOSED           = "closed"           // closed_at - the market closed before it was filled
+	PREDICTION_INTENT_EXPIRED          = "expired"          // expired_at - passed its (good-till-time) expires_at
+)
+
+// prediction_intents.time_in_force
+const (
+	TIME_IN_FORCE_GTC = "GTC" // good till cancelled - rests on the book (default for limit orders)
+	TIME_IN_FORCE_IOC = "IOC" // immediate or cancel - whatever does not fill at once is cancelled (default for market orders)
+	TIME_IN_FORCE_FOK = "FOK" // fill or kill - fills in full at once or not at all
+)
+
+// trading_rules - an intent that breaks one is rejected (INVALID_ARGUMENT) with its code as the message prefix
+const (
+	TRADING_RULE_MIN_NOTIONAL = "MIN_NOTIONAL" // |price_usd| * qty below min_notional_usd
+	TRADING_RULE_MAX_NOTIONAL = "MAX_NOTIONAL" // |price_usd| * qty above max_notional_usd
+	TRADING_RULE_PRICE_TICK   = "PRICE_TICK"   // |price_usd| not a multiple of price_tick
+	TRADING_RULE_QTY_STEP     = "QTY_STEP"     // qty not a multiple of qty_step
+)
+
+// where the rules that apply to a market come from
+const (
+	TRADING_RULES_SOURCE_MARKET  = "market"
+	TRADING_RULES_SOURCE_NETWORK = "network"
+	TRADING_RULES_SOURCE_DEFAULT = "default" // MIN_ORDER_SIZE_USD and TRADING_RULES_DEFAULT_*
+)
+
+// payload published on NATS_MARKETS_CLOSED
+type MarketClosedEvent struct {
+	MarketId    string   `json:"marketId"`
+	Net         string   `json:"net"`
+	ClosesAt    string   `json:"closesAt"`
+	ClosedAt    string   `json:"closedAt"`
+	ClosedTxIds []string `json:"closedTxIds"` // prediction intents that were still open when the market closed
+}
@@ -26,10 +26,6 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 type HTTPMethod string
@@ -134,6 +130,27 @@ func Utf82hex(utf8Str string) string {
 	return hexStr
 }
 
+/*
+*
+The legal market status transitions - resolved and voided are final
+*/
+func IsLegalMarketStatusTransition(from string, to string) bool {
+	var legalTransitions = map[string][]string{
+		MARKET_STATUS_DRAFT:     {MARKET_STATUS_OPEN, MARKET_STATUS_VOIDED},
+		MARKET_STATUS_OPEN:      {MARKET_STATUS_PAUSED, MARKET_STATUS_CLOSED, MARKET_STATUS_VOIDED},
+		MARKET_STATUS_PAUSED:    {MARKET_STATUS_OPEN, MARKET_STATUS_CLOSED, MARKET_STATUS_VOIDED},
+		MARKET_STATUS_CLOSED:    {MARKET_STATUS_RESOLVING, MARKET_STATUS_VOIDED},
+		MARKET_STATUS_RESOLVING: {MARKET_STATUS_RESOLVED, MARKET_STATUS_VOIDED},
+	}
+
+	for _, legal := range legalTransitions[from] {
+		if legal == to {
+			return true
+		}
+	}
+	return false
+}
+
 func IsValidNetwork(s string) bool {
 	var validNetworks = map[ValidNetworksType]struct{}{
 		TESTNET:    {},
@@ -330,42 +347,171 @@ func CreateMarketOnClob(marketId string) error {
 	return nil
 }
 
-func SaveImageToS3(imageData []byte, fileName string, mimeType string) (string, error) {
-	// guards
-	if len(imageData) == 0 {
-		return "", fmt.Errorf("image data is empty")
+/*
+*
+Delete a market (and its book) from the clob
+*/
+func DeleteMarketOnClob(marketId string) error {
+	// TODO - use NATS
+
+	clobAddr := os.Getenv("CLOB_HOST") + ":" + os.Getenv("CLOB_PORT")
+
+	conn, err := grpc.NewClient(clobAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to delete market (marketId=%s) - connect to CLOB gRPC server failed: %w", marketId, err)
 	}
-	if fileName == "" {
-		return "", fmt.Errorf("file name is empty")
+	defer conn.Close()
+
+	clobClient := pb_clob.NewClobInternalClient(conn)
+	_, err = clobClient.DeleteMarket(
+		context.Background(),
+		&pb_clob.MarketIdRequest{
+			MarketId: marketId,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete a market (marketId=%s) on the CLOB (%s): %w", marketId, clobAddr, err)
 	}
-	if mimeType == "" {
-		return "", fmt.Errorf("MIME type is empty")
+
+	return nil
+}
+
+/*
+*
+Stop (isPaused = true) or restore (isPaused = false) matching for a market on the clob
+*/
+func PauseMarketOnClob(marketId string, isPaused bool) error {
+	// TODO - use NATS
+
+	clobAddr := os.Getenv("CLOB_HOST") + ":" + os.Getenv("CLOB_PORT")
+
+	conn, err := grpc.NewClient(clobAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to pause market (marketId=%s) - connect to CLOB gRPC server failed: %w", marketId, err)
 	}
+	defer conn.Close()
+
+	clobClient := pb_clob.NewClobInternalClient(conn)
+	_, err = clobClient.PauseMarket(
+		context.Background(),
+		&pb_clob.PauseMarketRequest{
+			MarketId: marketId,
+			IsPaused: isPaused,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set isPaused=%t for market (marketId=%s) on the CLOB (%s): %w", isPaused, marketId, clobAddr, err)
+	}
+
+	return nil
+}
+
+/*
+*
+Place an order on the clob and match it synchronously - for IOC/FOK orders, whose outcome goes back to the caller
+(GTC orders are published on NATS)
+*/
+func CreateOrderOnClob(order *pb_clob.CreateOrderRequestClob) (*pb_clob.CreateOrderResponse, error) {
+	clobAddr := os.Getenv("CLOB_HOST") + ":" + os.Getenv("CLOB_PORT")
 
-	s3BucketName := os.Getenv("S3_BUCKET_NAME")
-	if s3BucketName == "" {
-		return "", fmt.Errorf("S3_BUCKET_NAME environment variable is not set")
+	conn, err := grpc.NewClient(clobAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order (txId=%s) - connect to CLOB gRPC server failed: %w", order.TxId, err)
 	}
+	defer conn.Close()
 
-	// OK now we can save the image to S3 and return the URL
-	// Load AWS config (N.B. uses IAM role if running on EC2/ECS)
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	clobClient := pb_clob.NewClobInternalClient(conn)
+	response, err := clobClient.CreateOrder(context.Background(), order)
 	if err != nil {
-		return "", fmt.Errorf("unable to load AWS config: %w", err)
+		return nil, fmt.Errorf("failed to create order (txId=%s) on the CLOB (%s): %w", order.TxId, clobAddr, err)
 	}
 
-	client := s3.NewFromConfig(cfg)
+	return response, nil
+}
+
+/*
+*
+Cancel an order and place its replacement in one step on the clob - fails (FailedPrecondition) if the old order is no longer on the book
+*/
+func ReplaceOrderOnClob(cancelTxId string, order *pb_clob.CreateOrderRequestClob) (*pb_clob.CreateOrderResponse, error) {
+	clobAddr := os.Getenv("CLOB_HOST") + ":" + os.Getenv("CLOB_PORT")
+
+	conn, err := grpc.NewClient(clobAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace order (txId=%s) - connect to CLOB gRPC server failed: %w", cancelTxId, err)
+	}
+	defer conn.Close()
 
-	_, err = client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(s3BucketName),
-		Key:         aws.String(fileName),
-		Body:        bytes.NewReader(imageData),
-		ContentType: aws.String(mimeType),
-		ACL:         "public-read", // Optional: make public if you want public access
+	clobClient := pb_clob.NewClobInternalClient(conn)
+	response, err := clobClient.ReplaceOrder(context.Background(), &pb_clob.ReplaceOrderRequest{
+		CancelTxId: cancelTxId,
+		Order:      order,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload to S3: %w", err)
+		return nil, fmt.Errorf("failed to replace order (txId=%s) with %s on the CLOB (%s): %w", cancelTxId, order.TxId, clobAddr, err)
+	}
+
+	return response, nil
+}
+
+/*
+*
+Remove many orders from the clob in one call - returns the txIds that were on a book
+*/
+func CancelOrdersOnClob(orders []*pb_clob.CancelOrderRequest) ([]string, error) {
+	// TODO - use NATS
+
+	clobAddr := os.Getenv("CLOB_HOST") + ":" + os.Getenv("CLOB_PORT")
+
+	conn, err := grpc.NewClient(clobAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel %d orders - connect to CLOB gRPC server failed: %w", len(orders), err)
+	}
+	defer conn.Close()
+
+	clobClient := pb_clob.NewClobInternalClient(conn)
+	response, err := clobClient.CancelOrders(
+		context.Background(),
+		&pb_clob.CancelOrdersRequest{
+			Orders: orders,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel %d orders on the CLOB (%s): %w", len(orders), clobAddr, err)
+	}
+
+	return response.CancelledTxIds, nil
+}
+
+/*
+*
+Best bid/ask for each market from the clob (one connection for all markets).
+Markets the clob does not know about (e.g. closed markets) are left out of the result.
+*/
+func GetPricesFromClob(marketIds []string) (map[string]*pb_clob.PriceUpdate, error) {
+	clobAddr := os.Getenv("CLOB_HOST") + ":" + os.Getenv("CLOB_PORT")
+
+	conn, err := grpc.NewClient(clobAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices - connect to CLOB gRPC server failed: %w", err)
+	}
+	defer conn.Close()
+
+	clobClient := pb_clob.NewClobPublicClient(conn)
+	prices := make(map[string]*pb_clob.PriceUpdate, len(marketIds))
+	for _, marketId := range marketIds {
+		priceUpdate, err := clobClient.GetPrice(
+			context.Background(),
+			&pb_clob.MarketIdRequest{
+				MarketId: marketId,
+			},
+		)
+		if err != nil {
+			log.Printf("failed to get price for market (marketId=%s) from the CLOB (%s): %v", marketId, clobAddr, err)
+			continue
+		}
+		prices[marketId] = priceUpdate
 	}
 
-	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s3BucketName, fileName), nil
+	return prices, nil
 }
@@ -25,20 +25,31 @@ type server struct {
 	pb_api.UnimplementedApiServicePublicServer
 	pb_api.UnimplementedApiAuthServer
 
-	commentsRepository          repositories.CommentsRepository
-	dbRepository                repositories.DbRepository
-	marketsRepository           repositories.MarketsRepository
-	matchesRepository           repositories.MatchesRepository
-	positionsRepository         repositories.PositionsRepository
-	predictionIntentsRepository repositories.PredictionIntentsRepository
-	priceRepository             repositories.PriceRepository
-	userRoleRepository          repositories.UserRoleRepository
+	categoriesRepository         repositories.CategoriesRepository
+	commentsRepository           repositories.CommentsRepository
+	dbRepository                 repositories.DbRepository
+	marketCreationsRepository    repositories.MarketCreationsRepository
+	marketImageUploadsRepository repositories.MarketImageUploadsRepository
+	marketProposalsRepository    repositories.MarketProposalsRepository
+	marketResolutionsRepository  repositories.MarketResolutionsRepository
+	marketSeriesRepository       repositories.MarketSeriesRepository
+	marketsRepository            repositories.MarketsRepository
+	matchesRepository            repositories.MatchesRepository
+	positionsRepository          repositories.PositionsRepository
+	predictionIntentsRepository  repositories.PredictionIntentsRepository
+	priceRepository              repositories.PriceRepository
+	tradingRulesRepository       repositories.TradingRulesRepository
+	userRoleRepository           repositories.UserRoleRepository
 
 	authService              services.AuthService
+	categoriesService        services.CategoriesService
 	commentsService          services.CommentsService
 	cronService              services.CronService
 	hederaService            services.HederaService
 	logService               services.LogService
+	marketImagesService      services.MarketImagesService
+	marketProposalsService   services.MarketProposalsService
+	marketSeriesService      services.MarketSeriesService
 	marketsService           services.MarketsService
 	matchesService           services.MatchesService
 	natsService              services.NatsService
@@ -47,6 +58,9 @@ type server struct {
 	predictionIntentsService services.PredictionIntentsService
 	prismService             services.Prism
 	priceService             services.PriceService
+	resolutionsService       services.ResolutionsService
+	riskService              services.RiskService
+	tradingRulesService      services.TradingRulesService
 
 	// don't forget to register in RegisterApiServiceServer grpc call in main()
 }
@@ -57,16 +71,12 @@ func (s *server) Health(ctx context.Context, req *pb_api.Empty) (*pb_api.StdResp
 	}, nil
 }
 
-func (s *server) CreatePredictionIntent(ctx context.Context, req *pb_api.PredictionIntentRequest) (*pb_api.StdResponse, error) {
+func (s *server) CreatePredictionIntent(ctx context.Context, req *pb_api.PredictionIntentRequest) (*pb_api.PredictionIntentResponse, error) {
 	if err := req.ValidateAll(); err != nil { // PGV validation
-		return &pb_api.StdResponse{Message: fmt.Sprintf("Invalid request: %v", err)}, err
+		return &pb_api.PredictionIntentResponse{Message: fmt.Sprintf("Invalid request: %v", err)}, err
 	}
 
-	response, err := s.predictionIntentsService.CreatePredictionIntent(req)
-
-	return &pb_api.StdResponse{
-		Message: response,
-	}, err
+	return s.predictionIntentsService.CreatePredictionIntent(req)
 }
 
 func (s *server) GetMarketById(ctx context.Context, req *pb_api.MarketIdRequest) (*pb_api.MarketResponse, error) {
@@ -74,21 +84,142 @@ func (s *server) GetMarketById(ctx context.Context, req *pb_api.MarketIdRequest)
 	return result, err
 }
 
-func (s *server) GetMarkets(ctx context.Context, req *pb_api.LimitOffsetRequest) (*pb_api.MarketsResponse, error) {
-	result, err := s.marketsService.GetMarkets(req.GetLimit(), req.GetOffset())
+func (s *server) GetMarkets(ctx context.Context, req *pb_api.GetMarketsRequest) (*pb_api.MarketsResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.GetMarkets(req.GetLimit(), req.GetOffset(), req.CategoryId)
+	return result, err
+}
+
+func (s *server) SearchMarkets(ctx context.Context, req *pb_api.SearchMarketsRequest) (*pb_api.SearchMarketsResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.SearchMarkets(req)
+	return result, err
+}
+
+func (s *server) GetCategories(ctx context.Context, req *pb_api.Empty) (*pb_api.CategoriesResponse, error) {
+	result, err := s.categoriesService.GetCategories(true)
 	return result, err
 }
 
 func (s *server) CreateMarket(ctx context.Context, req *pb_api.CreateMarketRequest) (*pb_api.CreateMarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user - others propose markets (ProposeMarket)
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
 	result, err := s.marketsService.CreateMarket(req)
 	return result, err
 }
 
 func (s *server) CreateMarketv2(ctx context.Context, req *pb_api.CreateMarketv2Request) (*pb_api.CreateMarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user - others propose markets (ProposeMarket)
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
 	result, err := s.marketsService.CreateMarketv2(req)
 	return result, err
 }
 
+func (s *server) UploadMarketImage(stream pb_api.ApiServicePublic_UploadMarketImageServer) error {
+	accountId, err := s.authService.GetAccountId(stream.Context()) // MUST be logged in - uploads count against the account's quota
+	if err != nil {
+		return err
+	}
+
+	result, err := s.marketImagesService.UploadMarketImage(stream, accountId)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(result)
+}
+
+func (s *server) UploadMarketImageChunk(ctx context.Context, req *pb_api.MarketImageChunk) (*pb_api.MarketImageUpload, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	accountId, err := s.authService.GetAccountId(ctx) // MUST be logged in - uploads count against the account's quota
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketImagesService.UploadMarketImageChunk(req, accountId)
+	return result, err
+}
+
+func (s *server) GetMarketImageUpload(ctx context.Context, req *pb_api.MarketImageUploadRequest) (*pb_api.MarketImageUpload, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	accountId, err := s.authService.GetAccountId(ctx) // MUST be logged in - only the account that started the upload sees it
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketImagesService.GetMarketImageUpload(req.UploadId, accountId)
+	return result, err
+}
+
+func (s *server) ProposeMarket(ctx context.Context, req *pb_api.ProposeMarketRequest) (*pb_api.MarketProposal, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketProposalsService.ProposeMarket(req)
+	return result, err
+}
+
+func (s *server) GetMarketRevisions(ctx context.Context, req *pb_api.GetMarketRevisionsRequest) (*pb_api.MarketRevisionsResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.GetMarketRevisions(req.MarketId, req.Limit, req.Offset)
+	return result, err
+}
+
+func (s *server) GetMarketStats(ctx context.Context, req *pb_api.MarketStatsRequest) (*pb_api.MarketStatsResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.GetMarketStats(req.MarketIds)
+	return result, err
+}
+
+func (s *server) GetMarketResolution(ctx context.Context, req *pb_api.MarketIdRequest) (*pb_api.MarketResolution, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.resolutionsService.GetMarketResolution(req.MarketId)
+	return result, err
+}
+
+func (s *server) GetMarketStatusHistory(ctx context.Context, req *pb_api.MarketIdRequest) (*pb_api.MarketStatusHistoryResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.GetMarketStatusHistory(req.MarketId)
+	return result, err
+}
+
+func (s *server) FileResolutionDispute(ctx context.Context, req *pb_api.FileResolutionDisputeRequest) (*pb_api.ResolutionDispute, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.resolutionsService.FileDispute(req)
+	return result, err
+}
+
 func (s *server) PriceHistory(ctx context.Context, req *pb_api.PriceHistoryRequest) (*pb_api.PriceHistoryResponse, error) {
 	result, err := s.marketsService.PriceHistory(req)
 	return result, err
@@ -133,11 +264,312 @@ func (s *server) TriggerRecreateClob(ctx context.Context, req *pb_api.Empty) (*p
 	}, err
 }
 
+func (s *server) ResolveMarket(ctx context.Context, req *pb_api.ResolveMarketRequest) (*pb_api.MarketResolution, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) && !s.authService.HasRole(ctx, lib.ORACLE) { // MUST be ADMIN or ORACLE user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN or ORACLE role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	proposedBy, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.resolutionsService.ProposeResolution(req.MarketId, req.Outcome, proposedBy)
+	return result, err
+}
+
+func (s *server) DecideMarketResolution(ctx context.Context, req *pb_api.DecideMarketResolutionRequest) (*pb_api.MarketResolution, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	decidedBy, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.resolutionsService.DecideResolution(req.MarketId, req.Decision, decidedBy, req.Reason)
+	return result, err
+}
+
+func (s *server) PauseMarket(ctx context.Context, req *pb_api.MarketModerationRequest) (*pb_api.MarketResponse, error) {
+	return s.moderateMarket(ctx, req, lib.MARKET_PAUSE)
+}
+
+func (s *server) UnpauseMarket(ctx context.Context, req *pb_api.MarketModerationRequest) (*pb_api.MarketResponse, error) {
+	return s.moderateMarket(ctx, req, lib.MARKET_UNPAUSE)
+}
+
+func (s *server) SuspendMarket(ctx context.Context, req *pb_api.MarketModerationRequest) (*pb_api.MarketResponse, error) {
+	return s.moderateMarket(ctx, req, lib.MARKET_SUSPEND)
+}
+
+func (s *server) UnsuspendMarket(ctx context.Context, req *pb_api.MarketModerationRequest) (*pb_api.MarketResponse, error) {
+	return s.moderateMarket(ctx, req, lib.MARKET_UNSUSPEND)
+}
+
+func (s *server) moderateMarket(ctx context.Context, req *pb_api.MarketModerationRequest, action lib.MarketModerationActionType) (*pb_api.MarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	// record who did it
+	accountId, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketsService.ModerateMarket(req.MarketId, action, accountId, req.Reason)
+	return result, err
+}
+
+func (s *server) VoidMarket(ctx context.Context, req *pb_api.VoidMarketRequest) (*pb_api.MarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	// record who did it
+	accountId, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketsService.VoidMarket(req.MarketId, accountId, req.Reason)
+	return result, err
+}
+
+func (s *server) UpdateMarket(ctx context.Context, req *pb_api.UpdateMarketRequest) (*pb_api.MarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	accountId, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketsService.UpdateMarket(req, accountId)
+	return result, err
+}
+
+func (s *server) SetMarketResolutionSource(ctx context.Context, req *pb_api.SetMarketResolutionSourceRequest) (*pb_api.MarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.SetMarketResolutionSource(req.MarketId, req.ResolutionSource, req.ResolutionConfig)
+	return result, err
+}
+
+func (s *server) GetStuckMarketCreations(ctx context.Context, req *pb_api.LimitOffsetRequest) (*pb_api.MarketCreationsResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.GetStuckMarketCreations(req.Limit, req.Offset)
+	return result, err
+}
+
+func (s *server) ResumeMarketCreation(ctx context.Context, req *pb_api.MarketIdRequest) (*pb_api.CreateMarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketsService.ResumeMarketCreation(req.MarketId)
+	return result, err
+}
+
+func (s *server) GetMarketProposals(ctx context.Context, req *pb_api.GetMarketProposalsRequest) (*pb_api.MarketProposalsResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) && !s.authService.HasRole(ctx, lib.MODERATOR) { // MUST be ADMIN or MODERATOR user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN or MODERATOR role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketProposalsService.GetMarketProposals(req.Status, req.Limit, req.Offset)
+	return result, err
+}
+
+func (s *server) ApproveMarketProposal(ctx context.Context, req *pb_api.ReviewMarketProposalRequest) (*pb_api.CreateMarketResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) && !s.authService.HasRole(ctx, lib.MODERATOR) { // MUST be ADMIN or MODERATOR user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN or MODERATOR role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	reviewedBy, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketProposalsService.ApproveMarketProposal(req.ProposalId, reviewedBy, req.Reason)
+	return result, err
+}
+
+func (s *server) RejectMarketProposal(ctx context.Context, req *pb_api.ReviewMarketProposalRequest) (*pb_api.MarketProposal, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) && !s.authService.HasRole(ctx, lib.MODERATOR) { // MUST be ADMIN or MODERATOR user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN or MODERATOR role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	reviewedBy, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketProposalsService.RejectMarketProposal(req.ProposalId, reviewedBy, req.Reason)
+	return result, err
+}
+
+func (s *server) CreateMarketTemplate(ctx context.Context, req *pb_api.CreateMarketTemplateRequest) (*pb_api.MarketTemplate, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	accountId, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketSeriesService.CreateMarketTemplate(req, accountId)
+	return result, err
+}
+
+func (s *server) CreateMarketSeries(ctx context.Context, req *pb_api.CreateMarketSeriesRequest) (*pb_api.MarketSeries, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	accountId, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.marketSeriesService.CreateMarketSeries(req, accountId)
+	return result, err
+}
+
+func (s *server) SetMarketSeriesActive(ctx context.Context, req *pb_api.SetMarketSeriesActiveRequest) (*pb_api.MarketSeries, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketSeriesService.SetMarketSeriesActive(req.SeriesId, req.IsActive)
+	return result, err
+}
+
+func (s *server) SetTradingRules(ctx context.Context, req *pb_api.SetTradingRulesRequest) (*pb_api.TradingRules, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	accountId, err := s.authService.GetAccountId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.tradingRulesService.SetTradingRules(req, accountId)
+	return result, err
+}
+
+func (s *server) GetMarketSeries(ctx context.Context, req *pb_api.GetMarketSeriesRequest) (*pb_api.MarketSeries, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.marketSeriesService.GetMarketSeries(req.SeriesId, req.Limit, req.Offset)
+	return result, err
+}
+
+func (s *server) GetPredictionIntent(ctx context.Context, req *pb_api.PredictionIntentIdRequest) (*pb_api.PredictionIntentStatus, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	result, err := s.predictionIntentsService.GetPredictionIntent(req.TxId)
+	return result, err
+}
+
 func (s *server) CancelPredictionIntent(ctx context.Context, req *pb_api.CancelOrderRequest) (*pb_api.StdResponse, error) {
-	cancelResp, err := s.predictionIntentsService.CancelPredictionIntent(req.MarketId, req.TxId)
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	cancelResp, err := s.predictionIntentsService.CancelPredictionIntent(req)
 	return cancelResp, err
 }
 
+func (s *server) CancelAllPredictionIntents(ctx context.Context, req *pb_api.CancelAllPredictionIntentsRequest) (*pb_api.CancelAllPredictionIntentsResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	return s.predictionIntentsService.CancelAllPredictionIntents(req)
+}
+
+func (s *server) ReplacePredictionIntent(ctx context.Context, req *pb_api.ReplacePredictionIntentRequest) (*pb_api.PredictionIntentResponse, error) {
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	return s.predictionIntentsService.ReplacePredictionIntent(req)
+}
+
 func (s *server) GetChallenge(ctx context.Context, req *pb_api.ChallengeRequest) (*pb_api.StdResponse, error) {
 	challengesResp, err := s.authService.GetChallenge(req.AccountId, req.Network)
 	return &pb_api.StdResponse{
@@ -250,6 +682,49 @@ func (s *server) GetAllPredictionIntents(ctx context.Context, req *pb_api.LimitO
 	}, nil
 }
 
+func (s *server) CreateCategory(ctx context.Context, req *pb_api.CreateCategoryRequest) (*pb_api.Category, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	return s.categoriesService.CreateCategory(req)
+}
+
+func (s *server) UpdateCategory(ctx context.Context, req *pb_api.UpdateCategoryRequest) (*pb_api.Category, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return nil, err
+	}
+
+	return s.categoriesService.UpdateCategory(req)
+}
+
+func (s *server) DeleteCategory(ctx context.Context, req *pb_api.CategoryIdRequest) (*pb_api.StdResponse, error) {
+	if !s.authService.HasRole(ctx, lib.ADMIN) { // MUST be ADMIN user
+		return nil, s.logService.Log(services.ERROR, "unauthorized: ADMIN role required")
+	}
+
+	if err := req.ValidateAll(); err != nil { // PGV validation
+		return &pb_api.StdResponse{Message: fmt.Sprintf("Invalid request: %v", err), ErrorCode: 1}, err
+	}
+
+	_, err := s.categoriesService.DeleteCategory(req.Id)
+	if err != nil {
+		return &pb_api.StdResponse{Message: "Failed to delete category", ErrorCode: 1}, err
+	}
+
+	return &pb_api.StdResponse{
+		Message: "Category deleted",
+	}, nil
+}
+
 func main() {
 	// check env vars are available (.config.ENV and .secrets.ENV are loaded):
 	vars := []string{
@@ -296,7 +771,6 @@ func main() {
 		"MIN_ORDER_SIZE_USD",
 		"CRON_STR",
 		"JWT_EXPIRY_HOURS",
-		"S3_BUCKET_NAME",
 		// secrets:
 		"DB_PWORD",
 		"PREVIEWNET_HEDERA_OPERATOR_KEY",
@@ -326,6 +800,13 @@ func main() {
 	// data layer
 	/////
 	// initialize database
+	categoriesRepository := repositories.CategoriesRepository{}
+	err = categoriesRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer categoriesRepository.CloseDb()
+
 	commentsRepository := repositories.CommentsRepository{}
 	err = commentsRepository.InitDb()
 	if err != nil {
@@ -340,6 +821,41 @@ func main() {
 	}
 	defer dbRepository.CloseDb()
 
+	marketCreationsRepository := repositories.MarketCreationsRepository{}
+	err = marketCreationsRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer marketCreationsRepository.CloseDb()
+
+	marketProposalsRepository := repositories.MarketProposalsRepository{}
+	err = marketProposalsRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer marketProposalsRepository.CloseDb()
+
+	marketImageUploadsRepository := repositories.MarketImageUploadsRepository{}
+	err = marketImageUploadsRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer marketImageUploadsRepository.CloseDb()
+
+	marketResolutionsRepository := repositories.MarketResolutionsRepository{}
+	err = marketResolutionsRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer marketResolutionsRepository.CloseDb()
+
+	marketSeriesRepository := repositories.MarketSeriesRepository{}
+	err = marketSeriesRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer marketSeriesRepository.CloseDb()
+
 	marketsRepository := repositories.MarketsRepository{}
 	err = marketsRepository.InitDb()
 	if err != nil {
@@ -375,6 +891,13 @@ func main() {
 	}
 	defer matchesRepository.CloseDb()
 
+	tradingRulesRepository := repositories.TradingRulesRepository{}
+	err = tradingRulesRepository.InitDb()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer tradingRulesRepository.CloseDb()
+
 	userRoleRepository := repositories.UserRoleRepository{}
 	err = userRoleRepository.InitDb()
 	if err != nil {
@@ -411,13 +934,47 @@ func main() {
 		log.Fatalf("Failed to initialize Price service: %v", err)
 	}
 
+	// initialize blob store (market images) - S3 unless BLOB_STORE=local
+	blobStore, err := lib.NewBlobStore()
+	if err != nil {
+		log.Fatalf("Failed to initialize blob store: %v", err)
+	}
+
+	// initialize MarketImages service
+	marketImagesService := services.MarketImagesService{}
+	err = marketImagesService.Init(&logService, &marketImageUploadsRepository, blobStore)
+	if err != nil {
+		log.Fatalf("Failed to initialize MarketImages service: %v", err)
+	}
+
+	// initialize TradingRules service
+	tradingRulesService := services.TradingRulesService{}
+	err = tradingRulesService.Init(&logService, &tradingRulesRepository, &marketsRepository)
+	if err != nil {
+		log.Fatalf("Failed to initialize TradingRules service: %v", err)
+	}
+
 	// initialize Markets service
 	marketsService := services.MarketsService{}
-	err = marketsService.Init(&logService, &marketsRepository, &hederaService, &priceService)
+	err = marketsService.Init(&logService, &marketsRepository, &marketCreationsRepository, &hederaService, &priceService, &marketImagesService, &tradingRulesService)
 	if err != nil {
 		log.Fatalf("Failed to initialize Markets service: %v", err)
 	}
 
+	// initialize MarketProposals service
+	marketProposalsService := services.MarketProposalsService{}
+	err = marketProposalsService.Init(&logService, &marketProposalsRepository, &marketsService, &hederaService)
+	if err != nil {
+		log.Fatalf("Failed to initialize MarketProposals service: %v", err)
+	}
+
+	// initialize Categories service
+	categoriesService := services.CategoriesService{}
+	err = categoriesService.Init(&logService, &categoriesRepository)
+	if err != nil {
+		log.Fatalf("Failed to initialize Categories service: %v", err)
+	}
+
 	// initialize Matches service
 	matchesService := services.MatchesService{}
 	err = matchesService.Init(&logService, &matchesRepository)
@@ -456,22 +1013,43 @@ func main() {
 	// NATS start listening for matches
 	natsService.HandleOrderMatches()
 
+	// initialize Risk service
+	riskService := services.RiskService{}
+	err = riskService.Init(&logService, &predictionIntentsRepository, &hederaService)
+	if err != nil {
+		log.Fatalf("Failed to initialize Risk service: %v", err)
+	}
+
 	// initialize PredictionIntents service
 	predictionIntentsService := services.PredictionIntentsService{}
-	err = predictionIntentsService.Init(&logService, &dbRepository, &marketsRepository, &natsService, &hederaService, &predictionIntentsRepository)
+	err = predictionIntentsService.Init(&logService, &dbRepository, &marketsRepository, &natsService, &hederaService, &riskService, &tradingRulesService, &predictionIntentsRepository)
 	if err != nil {
 		log.Fatalf("Failed to initialize PredictionIntents service: %v", err)
 	}
 
+	// initialize Resolutions service
+	resolutionsService := services.ResolutionsService{}
+	err = resolutionsService.Init(&logService, &marketResolutionsRepository, &marketsRepository, &positionsRepository, &marketsService, &hederaService, &natsService)
+	if err != nil {
+		log.Fatalf("Failed to initialize Resolutions service: %v", err)
+	}
+
+	// initialize MarketSeries service
+	marketSeriesService := services.MarketSeriesService{}
+	err = marketSeriesService.Init(&logService, &marketSeriesRepository, &marketsRepository, &marketsService)
+	if err != nil {
+		log.Fatalf("Failed to initialize MarketSeries service: %v", err)
+	}
+
 	cronService := services.CronService{}
-	err = cronService.Init(&logService, &marketsRepository, &predictionIntentsRepository, &hederaService, &predictionIntentsService)
+	err = cronService.Init(&logService, &marketsRepository, &predictionIntentsRepository, &hederaService, &riskService, &predictionIntentsService, &natsService, &resolutionsService, &marketImagesService, &marketSeriesService)
 	if err != nil {
 		log.Fatalf("Failed to initialize Cron service: %v", err)
 	}
 
 	// initialize prism service
 	prismService := services.Prism{}
-	err = prismService.InitPrism(&logService, &dbRepository, &marketsRepository, &matchesRepository, &natsService, &hederaService, &marketsService, &predictionIntentsService)
+	err = prismService.InitPrism(&logService, &dbRepository, &marketsRepository, &matchesRepository, &natsService, &hederaService, &marketsService, &predictionIntentsService, &tradingRulesService)
 	if err != nil {
 		log.Fatalf("Failed to initialize Prism service: %v", err)
 	}
@@ -489,20 +1067,31 @@ func main() {
 
 	grpcServer := grpc.NewServer()
 	sharedServer := &server{
-		commentsRepository:          commentsRepository,
-		dbRepository:                dbRepository,
-		marketsRepository:           marketsRepository,
-		matchesRepository:           matchesRepository,
-		positionsRepository:         positionsRepository,
-		predictionIntentsRepository: predictionIntentsRepository,
-		priceRepository:             priceRepository,
-		userRoleRepository:          userRoleRepository,
+		categoriesRepository:         categoriesRepository,
+		commentsRepository:           commentsRepository,
+		dbRepository:                 dbRepository,
+		marketCreationsRepository:    marketCreationsRepository,
+		marketImageUploadsRepository: marketImageUploadsRepository,
+		marketProposalsRepository:    marketProposalsRepository,
+		marketResolutionsRepository:  marketResolutionsRepository,
+		marketSeriesRepository:       marketSeriesRepository,
+		marketsRepository:            marketsRepository,
+		matchesRepository:            matchesRepository,
+		positionsRepository:          positionsRepository,
+		predictionIntentsRepository:  predictionIntentsRepository,
+		priceRepository:              priceRepository,
+		tradingRulesRepository:       tradingRulesRepository,
+		userRoleRepository:           userRoleRepository,
 
 		authService:              authService,
+		categoriesService:        categoriesService,
 		commentsService:          commentsService,
 		cronService:              cronService,
 		hederaService:            hederaService,
 		logService:               logService,
+		marketImagesService:      marketImagesService,
+		marketProposalsService:   marketProposalsService,
+		marketSeriesService:      marketSeriesService,
 		marketsService:           marketsService,
 		matchesService:           matchesService,
 		natsService:              natsService,
@@ -511,6 +1100,9 @@ func main() {
 		predictionIntentsService: predictionIntentsService,
 		priceService:             priceService,
 		prismService:             prismService,
+		resolutionsService:       resolutionsService,
+		riskService:              riskService,
+		tradingRulesService:      tradingRulesService,
 	}
 	// must pass the grpc server to bother internal and the public servers!
 	pb_api.RegisterApiServiceInternalServer(grpcServer, sharedServer)
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+type CategoriesRepository struct {
+	db *sql.DB
+}
+
+func (categoriesRepository *CategoriesRepository) CloseDb() error {
+	var err = categoriesRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (categoriesRepository *CategoriesRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	categoriesRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: CategoriesRepository connected successfully")
+	return nil
+}
+
+func (categoriesRepository *CategoriesRepository) GetCategories(activeOnly bool) ([]sqlc.Category, error) {
+	if categoriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(categoriesRepository.db)
+	if activeOnly {
+		categories, err := q.GetActiveCategories(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("GetActiveCategories failed: %v", err)
+		}
+		return categories, nil
+	}
+
+	categories, err := q.GetCategories(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetCategories failed: %v", err)
+	}
+	return categories, nil
+}
+
+func (categoriesRepository *CategoriesRepository) CreateCategory(_name string, _description string, isActive bool, sortOrder int32) (*sqlc.Category, error) {
+	if categoriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	name := strings.TrimSpace(_name)
+	if name == "" {
+		return nil, fmt.Errorf("category name is empty")
+	}
+	description := strings.TrimSpace(_description)
+
+	q := sqlc.New(categoriesRepository.db)
+	category, err := q.CreateCategory(context.Background(), sqlc.CreateCategoryParams{
+		Name:        name,
+		Description: sql.NullString{String: description, Valid: description != ""},
+		IsActive:    sql.NullBool{Bool: isActive, Valid: true},
+		SortOrder:   sortOrder,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateCategory failed: %v", err)
+	}
+
+	log.Printf("Created new category in database: %d (%s)", category.ID, category.Name)
+	return &category, nil
+}
+
+func (categoriesRepository *CategoriesRepository) UpdateCategory(id int32, _name string, _description string, isActive bool, sortOrder int32) (*sqlc.Category, error) {
+	if categoriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	name := strings.TrimSpace(_name)
+	if name == "" {
+		return nil, fmt.Errorf("category name is empty")
+	}
+	description := strings.TrimSpace(_description)
+
+	q := sqlc.New(categoriesRepository.db)
+	category, err := q.UpdateCategory(context.Background(), sqlc.UpdateCategoryParams{
+		ID:          id,
+		Name:        name,
+		Description: sql.NullString{String: description, Valid: description != ""},
+		IsActive:    sql.NullBool{Bool: isActive, Valid: true},
+		SortOrder:   sortOrder,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("UpdateCategory failed: %v", err)
+	}
+
+	log.Printf("Updated category in database: %d (%s)", category.ID, category.Name)
+	return &category, nil
+}
+
+func (categoriesRepository *CategoriesRepository) DeleteCategory(id int32) (bool, error) {
+	if categoriesRepository.db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(categoriesRepository.db)
+	nRows, err := q.DeleteCategory(context.Background(), id)
+	if err != nil {
+		return false, fmt.Errorf("DeleteCategory failed: %v", err)
+	}
+
+	log.Printf("Deleted category %d from database (%d rows)", id, nRows)
+	return nRows > 0, nil
+}
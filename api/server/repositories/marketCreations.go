@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MarketCreationsRepository struct {
+	db *sql.DB
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) CloseDb() error {
+	var err = marketCreationsRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	marketCreationsRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: MarketCreationsRepository connected successfully")
+	return nil
+}
+
+/*
+*
+Start (or restart) the creation saga for a market. Retrying with the same marketId returns the existing row unchanged,
+apart from the attempts counter.
+*/
+func (marketCreationsRepository *MarketCreationsRepository) StartMarketCreation(marketId string, _net string, _statement string, _description string, _imageUrl string, closesAt time.Time, categoryIds []int32) (*sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	net := strings.ToLower(_net)
+	if !lib.IsValidNetwork(net) {
+		return nil, fmt.Errorf("invalid network: %s", net)
+	}
+
+	if categoryIds == nil {
+		categoryIds = []int32{} // NOT NULL column
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creation, err := q.StartMarketCreation(context.Background(), sqlc.StartMarketCreationParams{
+		MarketID:    marketUUID,
+		Net:         net,
+		Statement:   strings.TrimSpace(_statement),
+		Description: strings.TrimSpace(_description),
+		ImageUrl:    strings.TrimSpace(_imageUrl),
+		ClosesAt:    closesAt,
+		CategoryIds: categoryIds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("StartMarketCreation failed: %v", err)
+	}
+
+	return &creation, nil
+}
+
+// returns nil (and no error) if there is no creation saga for this marketId
+func (marketCreationsRepository *MarketCreationsRepository) GetMarketCreation(marketId string) (*sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creation, err := q.GetMarketCreation(context.Background(), marketUUID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketCreation failed: %v", err)
+	}
+
+	return &creation, nil
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) GetStuckMarketCreations(limit int32, offset int32) ([]sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creations, err := q.GetStuckMarketCreations(context.Background(), sqlc.GetStuckMarketCreationsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetStuckMarketCreations failed: %v", err)
+	}
+
+	return creations, nil
+}
+
+// lock the creation for the caller - returns nil (and no error) if it is completed or already being driven by someone else
+func (marketCreationsRepository *MarketCreationsRepository) ClaimMarketCreation(marketId uuid.UUID) (*sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creation, err := q.ClaimMarketCreation(context.Background(), marketId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ClaimMarketCreation failed: %v", err)
+	}
+
+	return &creation, nil
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) SetMarketCreationContractCreated(marketId uuid.UUID, smartContractId string, remainingAllowance uint64) (*sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creation, err := q.SetMarketCreationContractCreated(context.Background(), sqlc.SetMarketCreationContractCreatedParams{
+		MarketID:           marketId,
+		SmartContractID:    sql.NullString{String: smartContractId, Valid: true},
+		RemainingAllowance: int64(remainingAllowance),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketCreationContractCreated failed: %v", err)
+	}
+
+	return &creation, nil
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) SetMarketCreationClobCreated(marketId uuid.UUID) (*sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creation, err := q.SetMarketCreationClobCreated(context.Background(), marketId)
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketCreationClobCreated failed: %v", err)
+	}
+
+	return &creation, nil
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) SetMarketCreationCompleted(marketId uuid.UUID) (*sqlc.MarketCreation, error) {
+	if marketCreationsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	creation, err := q.SetMarketCreationCompleted(context.Background(), marketId)
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketCreationCompleted failed: %v", err)
+	}
+
+	log.Printf("Market creation completed: %s", marketId.String())
+	return &creation, nil
+}
+
+func (marketCreationsRepository *MarketCreationsRepository) SetMarketCreationFailed(marketId uuid.UUID, lastError string) error {
+	if marketCreationsRepository.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketCreationsRepository.db)
+	err := q.SetMarketCreationFailed(context.Background(), sqlc.SetMarketCreationFailedParams{
+		MarketID:  marketId,
+		LastError: sql.NullString{String: lastError, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("SetMarketCreationFailed failed: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MarketImageUploadsRepository struct {
+	db *sql.DB
+}
+
+func (marketImageUploadsRepository *MarketImageUploadsRepository) CloseDb() error {
+	var err = marketImageUploadsRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (marketImageUploadsRepository *MarketImageUploadsRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	marketImageUploadsRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: MarketImageUploadsRepository connected successfully")
+	return nil
+}
+
+// returns nil (and no error) if the uploadId is already taken
+func (marketImageUploadsRepository *MarketImageUploadsRepository) CreateMarketImageUpload(uploadId uuid.UUID, totalSize int64, expiresAt time.Time, accountId string) (*sqlc.MarketImageUpload, error) {
+	if marketImageUploadsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketImageUploadsRepository.db)
+	upload, err := q.CreateMarketImageUpload(context.Background(), sqlc.CreateMarketImageUploadParams{
+		UploadID:  uploadId,
+		TotalSize: totalSize,
+		ExpiresAt: expiresAt,
+		AccountID: accountId,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CreateMarketImageUpload failed: %v", err)
+	}
+
+	return &upload, nil
+}
+
+// returns nil (and no error) if there is no such upload
+func (marketImageUploadsRepository *MarketImageUploadsRepository) GetMarketImageUpload(uploadId string) (*sqlc.MarketImageUpload, error) {
+	if marketImageUploadsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	uploadUUID, err := uuid.Parse(uploadId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uploadId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketImageUploadsRepository.db)
+	upload, err := q.GetMarketImageUpload(context.Background(), uploadUUID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketImageUpload failed: %v", err)
+	}
+
+	return &upload, nil
+}
+
+// number of uploads (and the bytes they declared) the account started since the given time
+func (marketImageUploadsRepository *MarketImageUploadsRepository) GetMarketImageUploadUsageByAccountId(accountId string, since time.Time) (int64, int64, error) {
+	if marketImageUploadsRepository.db == nil {
+		return 0, 0, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketImageUploadsRepository.db)
+	usage, err := q.GetMarketImageUploadUsageByAccountId(context.Background(), sqlc.GetMarketImageUploadUsageByAccountIdParams{
+		AccountID: accountId,
+		Since:     since,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetMarketImageUploadUsageByAccountId failed: %v", err)
+	}
+
+	return usage.NUploads, usage.TotalBytes, nil
+}
+
+/*
+*
+Store a chunk and move received_bytes on, in one transaction.
+Returns nil (and no error) if the upload is no longer waiting for a chunk at this offset (e.g. another stream got there first).
+*/
+func (marketImageUploadsRepository *MarketImageUploadsRepository) AppendMarketImageUploadChunk(uploadId uuid.UUID, offset int64, data []byte) (*sqlc.MarketImageUpload, error) {
+	if marketImageUploadsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	tx, err := marketImageUploadsRepository.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	q := sqlc.New(tx)
+
+	upload, err := q.GetMarketImageUploadForUpdate(context.Background(), uploadId)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketImageUploadForUpdate failed: %v", err)
+	}
+	if upload.ReceivedBytes != offset || upload.Status != lib.MARKET_IMAGE_UPLOAD_UPLOADING {
+		return nil, nil
+	}
+	if offset+int64(len(data)) > upload.TotalSize {
+		return nil, fmt.Errorf("chunk at offset %d (%d bytes) runs past the declared size of %d bytes", offset, len(data), upload.TotalSize)
+	}
+
+	err = q.CreateMarketImageUploadChunk(context.Background(), sqlc.CreateMarketImageUploadChunkParams{
+		UploadID:    uploadId,
+		ChunkOffset: offset,
+		Data:        data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateMarketImageUploadChunk failed: %v", err)
+	}
+
+	upload, err = q.AdvanceMarketImageUpload(context.Background(), sqlc.AdvanceMarketImageUploadParams{
+		ReceivedBytes:     offset + int64(len(data)),
+		UploadID:          uploadId,
+		FromReceivedBytes: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AdvanceMarketImageUpload failed: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &upload, nil
+}
+
+// the bytes received so far, in order
+func (marketImageUploadsRepository *MarketImageUploadsRepository) GetMarketImageUploadData(uploadId uuid.UUID) ([]byte, error) {
+	if marketImageUploadsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketImageUploadsRepository.db)
+	chunks, err := q.GetMarketImageUploadChunks(context.Background(), uploadId)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketImageUploadChunks failed: %v", err)
+	}
+
+	return bytes.Join(chunks, nil), nil
+}
+
+// returns nil (and no error) if the upload is no longer uploading - the chunks are dropped once the image is stored
+func (marketImageUploadsRepository *MarketImageUploadsRepository) CompleteMarketImageUpload(uploadId uuid.UUID, sha256 string, imageUrl string) (*sqlc.MarketImageUpload, error) {
+	if marketImageUploadsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	tx, err := marketImageUploadsRepository.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	q := sqlc.New(tx)
+
+	upload, err := q.CompleteMarketImageUpload(context.Background(), sqlc.CompleteMarketImageUploadParams{
+		UploadID: uploadId,
+		Sha256:   sql.NullString{String: sha256, Valid: true},
+		ImageUrl: sql.NullString{String: imageUrl, Valid: true},
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CompleteMarketImageUpload failed: %v", err)
+	}
+
+	if err = q.DeleteMarketImageUploadChunks(context.Background(), uploadId); err != nil {
+		return nil, fmt.Errorf("DeleteMarketImageUploadChunks failed: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Completed market image upload %s (%d bytes): %s", uploadId.String(), upload.TotalSize, imageUrl)
+	return &upload, nil
+}
+
+// returns nil (and no error) if the upload is no longer uploading - a failed upload can not be resumed, so its chunks are dropped
+func (marketImageUploadsRepository *MarketImageUploadsRepository) FailMarketImageUpload(uploadId uuid.UUID, lastError string) (*sqlc.MarketImageUpload, error) {
+	if marketImageUploadsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	tx, err := marketImageUploadsRepository.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	q := sqlc.New(tx)
+
+	upload, err := q.FailMarketImageUpload(context.Background(), sqlc.FailMarketImageUploadParams{
+		UploadID:  uploadId,
+		LastError: sql.NullString{String: lastError, Valid: true},
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FailMarketImageUpload failed: %v", err)
+	}
+
+	if err = q.DeleteMarketImageUploadChunks(context.Background(), uploadId); err != nil {
+		return nil, fmt.Errorf("DeleteMarketImageUploadChunks failed: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &upload, nil
+}
+
+func (marketImageUploadsRepository *MarketImageUploadsRepository) DeleteExpiredMarketImageUploads() (int64, error) {
+	if marketImageUploadsRepository.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketImageUploadsRepository.db)
+	nDeleted, err := q.DeleteExpiredMarketImageUploads(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("DeleteExpiredMarketImageUploads failed: %v", err)
+	}
+
+	return nDeleted, nil
+}
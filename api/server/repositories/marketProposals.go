@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MarketProposalsRepository struct {
+	db *sql.DB
+}
+
+func (marketProposalsRepository *MarketProposalsRepository) CloseDb() error {
+	var err = marketProposalsRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (marketProposalsRepository *MarketProposalsRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	marketProposalsRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: MarketProposalsRepository connected successfully")
+	return nil
+}
+
+// closesAt is optional - nil means the default duration is applied when the proposal is approved
+func (marketProposalsRepository *MarketProposalsRepository) CreateMarketProposal(proposalId string, _net string, _statement string, _description string, _imageUrl string, closesAt *time.Time, categoryIds []int32, accountId string, publicKey string, keyType uint32, sig string) (*sqlc.MarketProposal, error) {
+	if marketProposalsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	proposalUUID, err := uuid.Parse(proposalId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposalId uuid: %v", err)
+	}
+
+	net := strings.ToLower(_net)
+	if !lib.IsValidNetwork(net) {
+		return nil, fmt.Errorf("invalid network: %s", net)
+	}
+
+	if categoryIds == nil {
+		categoryIds = []int32{} // NOT NULL column
+	}
+
+	var closesAtNull sql.NullTime
+	if closesAt != nil {
+		closesAtNull = sql.NullTime{Time: *closesAt, Valid: true}
+	}
+
+	q := sqlc.New(marketProposalsRepository.db)
+	proposal, err := q.CreateMarketProposal(context.Background(), sqlc.CreateMarketProposalParams{
+		ProposalID:  proposalUUID,
+		Net:         net,
+		Statement:   strings.TrimSpace(_statement),
+		Description: strings.TrimSpace(_description),
+		ImageUrl:    strings.TrimSpace(_imageUrl),
+		ClosesAt:    closesAtNull,
+		CategoryIds: categoryIds,
+		AccountID:   accountId,
+		PublicKey:   publicKey,
+		KeyType:     int32(keyType),
+		Sig:         sig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateMarketProposal failed: %v", err)
+	}
+
+	log.Printf("Created new market proposal in database: %s (account %s)", proposal.ProposalID.String(), proposal.AccountID)
+	return &proposal, nil
+}
+
+// returns nil (and no error) if there is no proposal with this proposalId
+func (marketProposalsRepository *MarketProposalsRepository) GetMarketProposal(proposalId string) (*sqlc.MarketProposal, error) {
+	if marketProposalsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	proposalUUID, err := uuid.Parse(proposalId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposalId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketProposalsRepository.db)
+	proposal, err := q.GetMarketProposal(context.Background(), proposalUUID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketProposal failed: %v", err)
+	}
+
+	return &proposal, nil
+}
+
+func (marketProposalsRepository *MarketProposalsRepository) GetMarketProposalsByStatus(status string, limit int32, offset int32) ([]sqlc.MarketProposal, error) {
+	if marketProposalsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketProposalsRepository.db)
+	proposals, err := q.GetMarketProposalsByStatus(context.Background(), sqlc.GetMarketProposalsByStatusParams{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketProposalsByStatus failed: %v", err)
+	}
+
+	return proposals, nil
+}
+
+// move a pending proposal to approved/rejected - returns nil (and no error) if the proposal is no longer pending
+func (marketProposalsRepository *MarketProposalsRepository) ReviewMarketProposal(proposalId string, status string, reviewedBy string, _reason string) (*sqlc.MarketProposal, error) {
+	if marketProposalsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	proposalUUID, err := uuid.Parse(proposalId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposalId uuid: %v", err)
+	}
+
+	if status != lib.MARKET_PROPOSAL_APPROVED && status != lib.MARKET_PROPOSAL_REJECTED {
+		return nil, fmt.Errorf("invalid review status: %s", status)
+	}
+
+	reason := strings.TrimSpace(_reason)
+
+	q := sqlc.New(marketProposalsRepository.db)
+	proposal, err := q.ReviewMarketProposal(context.Background(), sqlc.ReviewMarketProposalParams{
+		Status:       status,
+		ReviewedBy:   sql.NullString{String: reviewedBy, Valid: reviewedBy != ""},
+		ReviewReason: sql.NullString{String: reason, Valid: reason != ""},
+		ProposalID:   proposalUUID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReviewMarketProposal failed: %v", err)
+	}
+
+	log.Printf("Market proposal %s %s by %s", proposal.ProposalID.String(), proposal.Status, reviewedBy)
+	return &proposal, nil
+}
@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MarketResolutionsRepository struct {
+	db *sql.DB
+}
+
+func (marketResolutionsRepository *MarketResolutionsRepository) CloseDb() error {
+	var err = marketResolutionsRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (marketResolutionsRepository *MarketResolutionsRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	marketResolutionsRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: MarketResolutionsRepository connected successfully")
+	return nil
+}
+
+/*
+*
+Propose the outcome of a market in a single transaction: close the market (and its open prediction intents) if it is still trading,
+move it to resolving and record the proposal - a market is never left closed or resolving without its proposal, or the reverse.
+Returns the market and the txIds of the prediction intents that were closed here (nil if the market was already closed).
+Returns nil (and no error) if an outcome has already been proposed for this market.
+*/
+func (marketResolutionsRepository *MarketResolutionsRepository) ProposeMarketResolution(marketId uuid.UUID, outcome bool, proposedBy string, disputeEndsAt time.Time) (*sqlc.MarketResolution, *sqlc.Market, []uuid.UUID, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	// Start a transaction
+	tx, err := marketResolutionsRepository.db.Begin()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	market, err := q.GetMarketForUpdate(context.Background(), marketId)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, fmt.Errorf("GetMarketForUpdate failed: %v", err)
+	}
+
+	// close the market (and its open prediction intents) if it is still trading
+	var closedMarket *sqlc.Market
+	var closedTxIds []uuid.UUID
+	if market.Status != lib.MARKET_STATUS_CLOSED {
+		fromStatus, err := lockMarketForStatusChange(q, marketId, lib.MARKET_STATUS_CLOSED)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, nil, err
+		}
+		closed, err := q.CloseMarket(context.Background(), marketId)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, nil, fmt.Errorf("CloseMarket failed: %v", err)
+		}
+		err = recordMarketStatusChange(q, marketId, fromStatus, closed.Status, proposedBy, "outcome proposed")
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, nil, err
+		}
+		closedTxIds, err = q.CloseAllOpenPredictionIntentsByMarketId(context.Background(), marketId)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, nil, fmt.Errorf("CloseAllOpenPredictionIntentsByMarketId failed: %v", err)
+		}
+		closedMarket = &closed
+	}
+
+	// closed -> resolving
+	fromStatus, err := lockMarketForStatusChange(q, marketId, lib.MARKET_STATUS_RESOLVING)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, err
+	}
+	_, err = q.SetMarketStatus(context.Background(), sqlc.SetMarketStatusParams{
+		ToStatus:   lib.MARKET_STATUS_RESOLVING,
+		MarketID:   marketId,
+		FromStatus: fromStatus,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, fmt.Errorf("SetMarketStatus failed: %v", err)
+	}
+	err = recordMarketStatusChange(q, marketId, fromStatus, lib.MARKET_STATUS_RESOLVING, proposedBy, fmt.Sprintf("outcome=%t proposed, dispute window ends %s", outcome, disputeEndsAt.Format(time.RFC3339)))
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, err
+	}
+
+	// record the proposal - only one per market
+	resolution, err := q.ProposeMarketResolution(context.Background(), sqlc.ProposeMarketResolutionParams{
+		MarketID:        marketId,
+		ProposedOutcome: outcome,
+		ProposedBy:      proposedBy,
+		DisputeEndsAt:   disputeEndsAt,
+	})
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, nil, nil, nil
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, fmt.Errorf("ProposeMarketResolution failed: %v", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Proposed outcome=%t for market %s by %s (dispute window ends %s)", resolution.ProposedOutcome, resolution.MarketID.String(), resolution.ProposedBy, resolution.DisputeEndsAt.Format(time.RFC3339))
+	return &resolution, closedMarket, closedTxIds, nil
+}
+
+// returns nil (and no error) if no outcome has been proposed for this market
+func (marketResolutionsRepository *MarketResolutionsRepository) GetMarketResolution(marketId string) (*sqlc.MarketResolution, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketResolutionsRepository.db)
+	resolution, err := q.GetMarketResolution(context.Background(), marketUUID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketResolution failed: %v", err)
+	}
+
+	return &resolution, nil
+}
+
+func (marketResolutionsRepository *MarketResolutionsRepository) GetResolutionDisputes(marketId uuid.UUID) ([]sqlc.ResolutionDispute, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketResolutionsRepository.db)
+	disputes, err := q.GetResolutionDisputes(context.Background(), marketId)
+	if err != nil {
+		return nil, fmt.Errorf("GetResolutionDisputes failed: %v", err)
+	}
+
+	return disputes, nil
+}
+
+func (marketResolutionsRepository *MarketResolutionsRepository) GetMarketResolutionsDueForFinalization() ([]sqlc.MarketResolution, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketResolutionsRepository.db)
+	resolutions, err := q.GetMarketResolutionsDueForFinalization(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketResolutionsDueForFinalization failed: %v", err)
+	}
+
+	return resolutions, nil
+}
+
+/*
+*
+Record a holder's dispute and move the resolution to disputed, in one transaction.
+Returns nil (and no error) if the dispute window is closed.
+*/
+func (marketResolutionsRepository *MarketResolutionsRepository) CreateResolutionDispute(marketId uuid.UUID, accountId string, evmAddress string, _evidence string, publicKey string, keyType uint32, sig string) (*sqlc.ResolutionDispute, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	tx, err := marketResolutionsRepository.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	q := sqlc.New(tx)
+
+	_, err = q.SetMarketResolutionDisputed(context.Background(), marketId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketResolutionDisputed failed: %v", err)
+	}
+
+	dispute, err := q.CreateResolutionDispute(context.Background(), sqlc.CreateResolutionDisputeParams{
+		MarketID:   marketId,
+		AccountID:  accountId,
+		EvmAddress: evmAddress,
+		Evidence:   strings.TrimSpace(_evidence),
+		PublicKey:  publicKey,
+		KeyType:    int32(keyType),
+		Sig:        sig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateResolutionDispute failed: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Resolution of market %s disputed by account %s", marketId.String(), accountId)
+	return &dispute, nil
+}
+
+// move a resolution from fromStatus to decided - returns nil (and no error) if it is no longer in fromStatus
+func (marketResolutionsRepository *MarketResolutionsRepository) DecideMarketResolution(marketId uuid.UUID, fromStatus string, decision string, finalOutcome bool, decidedBy string, _reason string) (*sqlc.MarketResolution, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	if decision != lib.RESOLUTION_DECISION_UNDISPUTED && decision != lib.RESOLUTION_DECISION_UPHELD && decision != lib.RESOLUTION_DECISION_OVERTURNED {
+		return nil, fmt.Errorf("invalid decision: %s", decision)
+	}
+
+	reason := strings.TrimSpace(_reason)
+
+	q := sqlc.New(marketResolutionsRepository.db)
+	resolution, err := q.DecideMarketResolution(context.Background(), sqlc.DecideMarketResolutionParams{
+		Decision:       sql.NullString{String: decision, Valid: true},
+		FinalOutcome:   sql.NullBool{Bool: finalOutcome, Valid: true},
+		DecidedBy:      sql.NullString{String: decidedBy, Valid: decidedBy != ""},
+		DecisionReason: sql.NullString{String: reason, Valid: reason != ""},
+		MarketID:       marketId,
+		FromStatus:     fromStatus,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DecideMarketResolution failed: %v", err)
+	}
+
+	log.Printf("Resolution of market %s %s by %s (final outcome=%t)", resolution.MarketID.String(), decision, decidedBy, finalOutcome)
+	return &resolution, nil
+}
+
+// mark a decided resolution as resolved on-chain - returns nil (and no error) if it is not decided
+func (marketResolutionsRepository *MarketResolutionsRepository) FinalizeMarketResolution(marketId uuid.UUID) (*sqlc.MarketResolution, error) {
+	if marketResolutionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketResolutionsRepository.db)
+	resolution, err := q.FinalizeMarketResolution(context.Background(), marketId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FinalizeMarketResolution failed: %v", err)
+	}
+
+	return &resolution, nil
+}
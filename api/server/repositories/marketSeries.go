@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MarketSeriesRepository struct {
+	db *sql.DB
+}
+
+func (marketSeriesRepository *MarketSeriesRepository) CloseDb() error {
+	var err = marketSeriesRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (marketSeriesRepository *MarketSeriesRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	marketSeriesRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: MarketSeriesRepository connected successfully")
+	return nil
+}
+
+/////
+// templates
+/////
+
+func (marketSeriesRepository *MarketSeriesRepository) CreateMarketTemplate(_name string, statementTemplate string, descriptionTemplate string, _imageUrl string, categoryIds []int32, resolutionSource string, resolutionConfigTemplate string, createdBy string) (*sqlc.MarketTemplate, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	name := strings.TrimSpace(_name)
+	if name == "" {
+		return nil, fmt.Errorf("template name is empty")
+	}
+	if categoryIds == nil {
+		categoryIds = []int32{} // NOT NULL column
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	template, err := q.CreateMarketTemplate(context.Background(), sqlc.CreateMarketTemplateParams{
+		Name:                     name,
+		StatementTemplate:        strings.TrimSpace(statementTemplate),
+		DescriptionTemplate:      strings.TrimSpace(descriptionTemplate),
+		ImageUrl:                 strings.TrimSpace(_imageUrl),
+		CategoryIds:              categoryIds,
+		ResolutionSource:         resolutionSource,
+		ResolutionConfigTemplate: resolutionConfigTemplate,
+		CreatedBy:                createdBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateMarketTemplate failed: %v", err)
+	}
+
+	log.Printf("Created new market template in database: %d (%s)", template.ID, template.Name)
+	return &template, nil
+}
+
+// returns nil (and no error) if there is no such template
+func (marketSeriesRepository *MarketSeriesRepository) GetMarketTemplate(id int32) (*sqlc.MarketTemplate, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	template, err := q.GetMarketTemplate(context.Background(), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketTemplate failed: %v", err)
+	}
+
+	return &template, nil
+}
+
+/////
+// series
+/////
+
+func (marketSeriesRepository *MarketSeriesRepository) CreateMarketSeries(templateId int32, _name string, _net string, cadence string, leadTimeHours int32, parameterGenerator string, parameterConfig []byte, createdBy string) (*sqlc.MarketSeries, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	name := strings.TrimSpace(_name)
+	if name == "" {
+		return nil, fmt.Errorf("series name is empty")
+	}
+	net := strings.ToLower(_net)
+	if !lib.IsValidNetwork(net) {
+		return nil, fmt.Errorf("invalid network: %s", net)
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	series, err := q.CreateMarketSeries(context.Background(), sqlc.CreateMarketSeriesParams{
+		TemplateID:         templateId,
+		Name:               name,
+		Net:                net,
+		Cadence:            strings.TrimSpace(cadence),
+		LeadTimeHours:      leadTimeHours,
+		ParameterGenerator: parameterGenerator,
+		ParameterConfig:    parameterConfig,
+		CreatedBy:          createdBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateMarketSeries failed: %v", err)
+	}
+
+	log.Printf("Created new market series in database: %d (%s, cadence %s)", series.ID, series.Name, series.Cadence)
+	return &series, nil
+}
+
+// returns nil (and no error) if there is no such series
+func (marketSeriesRepository *MarketSeriesRepository) GetMarketSeries(id int32) (*sqlc.MarketSeries, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	series, err := q.GetMarketSeries(context.Background(), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketSeries failed: %v", err)
+	}
+
+	return &series, nil
+}
+
+func (marketSeriesRepository *MarketSeriesRepository) GetActiveMarketSeries() ([]sqlc.MarketSeries, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	series, err := q.GetActiveMarketSeries(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetActiveMarketSeries failed: %v", err)
+	}
+
+	return series, nil
+}
+
+// returns nil (and no error) if there is no such series
+func (marketSeriesRepository *MarketSeriesRepository) SetMarketSeriesActive(id int32, isActive bool) (*sqlc.MarketSeries, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	series, err := q.SetMarketSeriesActive(context.Background(), sqlc.SetMarketSeriesActiveParams{
+		ID:       id,
+		IsActive: isActive,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketSeriesActive failed: %v", err)
+	}
+
+	log.Printf("Market series %d (%s) is_active=%t", series.ID, series.Name, series.IsActive)
+	return &series, nil
+}
+
+// lastError == "" clears it
+func (marketSeriesRepository *MarketSeriesRepository) SetMarketSeriesLastError(id int32, lastError string) error {
+	if marketSeriesRepository.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	err := q.SetMarketSeriesLastError(context.Background(), sqlc.SetMarketSeriesLastErrorParams{
+		ID:        id,
+		LastError: sql.NullString{String: lastError, Valid: lastError != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("SetMarketSeriesLastError failed: %v", err)
+	}
+
+	return nil
+}
+
+/////
+// occurrences
+/////
+
+// returns nil (and no error) if this occurrence has already been claimed
+func (marketSeriesRepository *MarketSeriesRepository) CreateMarketSeriesOccurrence(seriesId int32, closesAt time.Time, marketId uuid.UUID, params map[string]interface{}, statement string, description string, resolutionConfig []byte) (*sqlc.MarketSeriesOccurrence, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	paramsJson, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %v", err)
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	occurrence, err := q.CreateMarketSeriesOccurrence(context.Background(), sqlc.CreateMarketSeriesOccurrenceParams{
+		SeriesID:         seriesId,
+		ClosesAt:         closesAt,
+		MarketID:         marketId,
+		Params:           paramsJson,
+		Statement:        statement,
+		Description:      description,
+		ResolutionConfig: resolutionConfig,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CreateMarketSeriesOccurrence failed: %v", err)
+	}
+
+	log.Printf("Claimed occurrence of market series %d closing %s: market %s", occurrence.SeriesID, occurrence.ClosesAt.UTC().Format(time.RFC3339), occurrence.MarketID.String())
+	return &occurrence, nil
+}
+
+// returns nil (and no error) if the series has no occurrences yet
+func (marketSeriesRepository *MarketSeriesRepository) GetLatestMarketSeriesOccurrence(seriesId int32) (*sqlc.MarketSeriesOccurrence, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	occurrence, err := q.GetLatestMarketSeriesOccurrence(context.Background(), seriesId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestMarketSeriesOccurrence failed: %v", err)
+	}
+
+	return &occurrence, nil
+}
+
+func (marketSeriesRepository *MarketSeriesRepository) GetPendingMarketSeriesOccurrences(limit int32) ([]sqlc.MarketSeriesOccurrence, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	occurrences, err := q.GetPendingMarketSeriesOccurrences(context.Background(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingMarketSeriesOccurrences failed: %v", err)
+	}
+
+	return occurrences, nil
+}
+
+func (marketSeriesRepository *MarketSeriesRepository) GetMarketSeriesOccurrences(seriesId int32, limit int32, offset int32) ([]sqlc.GetMarketSeriesOccurrencesRow, error) {
+	if marketSeriesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	occurrences, err := q.GetMarketSeriesOccurrences(context.Background(), sqlc.GetMarketSeriesOccurrencesParams{
+		SeriesID: seriesId,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketSeriesOccurrences failed: %v", err)
+	}
+
+	return occurrences, nil
+}
+
+func (marketSeriesRepository *MarketSeriesRepository) CompleteMarketSeriesOccurrence(seriesId int32, closesAt time.Time) error {
+	if marketSeriesRepository.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketSeriesRepository.db)
+	err := q.CompleteMarketSeriesOccurrence(context.Background(), sqlc.CompleteMarketSeriesOccurrenceParams{
+		SeriesID: seriesId,
+		ClosesAt: closesAt,
+	})
+	if err != nil {
+		return fmt.Errorf("CompleteMarketSeriesOccurrence failed: %v", err)
+	}
+
+	return nil
+}
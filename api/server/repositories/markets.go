@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -64,6 +66,35 @@ func (marketsRepository *MarketsRepository) GetMarketById(marketId string) (*sql
 	return &market, nil
 }
 
+// batch version of GetMarketById - suspended and unknown markets are left out of the result
+func (marketsRepository *MarketsRepository) GetMarketsByIds(marketIds []uuid.UUID) ([]sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	markets, err := q.GetMarketsByIds(context.Background(), marketIds)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketsByIds failed: %v", err)
+	}
+
+	return markets, nil
+}
+
+func (marketsRepository *MarketsRepository) MarketExists(marketId uuid.UUID) (bool, error) {
+	if marketsRepository.db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	exists, err := q.MarketExists(context.Background(), marketId)
+	if err != nil {
+		return false, fmt.Errorf("MarketExists failed: %v", err)
+	}
+
+	return exists, nil
+}
+
 func (marketsRepository *MarketsRepository) GetMarkets(limit int32, offset int32) ([]sqlc.Market, error) {
 	if marketsRepository.db == nil {
 		return nil, fmt.Errorf("database not initialized")
@@ -82,7 +113,67 @@ func (marketsRepository *MarketsRepository) GetMarkets(limit int32, offset int32
 	return markets, nil
 }
 
-func (marketsRepository *MarketsRepository) CreateMarket(marketId string, _net string, _imageUrl string, _statement string, _closesAt string, _description string, smartContractId string) (*sqlc.Market, error) {
+func (marketsRepository *MarketsRepository) GetMarketsByCategoryId(categoryId int32, limit int32, offset int32) ([]sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	markets, err := q.GetMarketsByCategoryId(context.Background(), sqlc.GetMarketsByCategoryIdParams{
+		CategoryID: categoryId,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketsByCategoryId failed: %v", err)
+	}
+
+	return markets, nil
+}
+
+func (marketsRepository *MarketsRepository) SearchMarkets(query *string, net *string, status *string, closesAfter *time.Time, closesBefore *time.Time, sortBy string, cursorSortKey *float64, cursorMarketId string, limit int32) ([]sqlc.SearchMarketsRow, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	params := sqlc.SearchMarketsParams{
+		SortBy:   sortBy,
+		RowLimit: limit,
+	}
+	if query != nil {
+		params.Query = sql.NullString{String: *query, Valid: true}
+	}
+	if net != nil {
+		params.Net = sql.NullString{String: strings.ToLower(*net), Valid: true}
+	}
+	if status != nil {
+		params.Status = sql.NullString{String: *status, Valid: true}
+	}
+	if closesAfter != nil {
+		params.ClosesAfter = sql.NullTime{Time: *closesAfter, Valid: true}
+	}
+	if closesBefore != nil {
+		params.ClosesBefore = sql.NullTime{Time: *closesBefore, Valid: true}
+	}
+	if cursorSortKey != nil { // continue after the last row of the previous page
+		cursorMarketUUID, err := uuid.Parse(cursorMarketId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor marketId uuid: %v", err)
+		}
+		params.CursorSortKey = sql.NullFloat64{Float64: *cursorSortKey, Valid: true}
+		params.CursorMarketID = uuid.NullUUID{UUID: cursorMarketUUID, Valid: true}
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	markets, err := q.SearchMarkets(context.Background(), params)
+	if err != nil {
+		return nil, fmt.Errorf("SearchMarkets failed: %v", err)
+	}
+
+	return markets, nil
+}
+
+func (marketsRepository *MarketsRepository) CreateMarket(marketId string, _net string, _imageUrl string, _statement string, _closesAt string, _description string, smartContractId string, categoryIds []int32) (*sqlc.Market, error) {
 	if marketsRepository.db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
@@ -141,6 +232,24 @@ func (marketsRepository *MarketsRepository) CreateMarket(marketId string, _net s
 		return nil, fmt.Errorf("CreateMarket failed: %v", err)
 	}
 
+	err = recordMarketStatusChange(q, marketUUID, "", market.Status, lib.MARKET_STATUS_CHANGED_BY_SYSTEM, "market created")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// assign the market to its categories
+	for _, categoryId := range categoryIds {
+		err = q.AddMarketCategory(context.Background(), sqlc.AddMarketCategoryParams{
+			MarketID:   marketUUID,
+			CategoryID: categoryId,
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("AddMarketCategory (categoryId=%d) failed: %v", categoryId, err)
+		}
+	}
+
 	// Commit the transaction
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %v", err)
@@ -177,3 +286,670 @@ func (marketsRepository *MarketsRepository) GetAllUnresolvedMarkets() ([]sqlc.Ma
 
 	return markets, nil
 }
+
+func (marketsRepository *MarketsRepository) GetMarketsDueForClose() ([]sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	markets, err := q.GetMarketsDueForClose(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketsDueForClose failed: %v", err)
+	}
+
+	return markets, nil
+}
+
+// a market whose book is still on the CLOB after it was closed, resolved or voided
+func (marketsRepository *MarketsRepository) SetMarketClobRemovalFailed(marketId uuid.UUID) error {
+	if marketsRepository.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	err := q.SetMarketClobRemovalFailed(context.Background(), marketId)
+	if err != nil {
+		return fmt.Errorf("SetMarketClobRemovalFailed failed: %v", err)
+	}
+
+	return nil
+}
+
+func (marketsRepository *MarketsRepository) ClearMarketClobRemovalFailed(marketId uuid.UUID) error {
+	if marketsRepository.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	err := q.ClearMarketClobRemovalFailed(context.Background(), marketId)
+	if err != nil {
+		return fmt.Errorf("ClearMarketClobRemovalFailed failed: %v", err)
+	}
+
+	return nil
+}
+
+func (marketsRepository *MarketsRepository) GetMarketsPendingClobRemoval() ([]sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	markets, err := q.GetMarketsPendingClobRemoval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketsPendingClobRemoval failed: %v", err)
+	}
+
+	return markets, nil
+}
+
+func (marketsRepository *MarketsRepository) GetMarketsDueForResolution() ([]sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	markets, err := q.GetMarketsDueForResolution(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketsDueForResolution failed: %v", err)
+	}
+
+	return markets, nil
+}
+
+func (marketsRepository *MarketsRepository) SetMarketResolutionSource(marketId string, resolutionSource string, resolutionConfig []byte) (*sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	market, err := q.SetMarketResolutionSource(context.Background(), sqlc.SetMarketResolutionSourceParams{
+		MarketID:         marketUUID,
+		ResolutionSource: resolutionSource,
+		ResolutionConfig: resolutionConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketResolutionSource failed: %v", err)
+	}
+
+	log.Printf("Market %s resolution source set to %s", market.MarketID.String(), market.ResolutionSource)
+	return &market, nil
+}
+
+func (marketsRepository *MarketsRepository) SetMarketSeries(marketId uuid.UUID, seriesId int32) (*sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	market, err := q.SetMarketSeries(context.Background(), sqlc.SetMarketSeriesParams{
+		MarketID: marketId,
+		SeriesID: sql.NullInt32{Int32: seriesId, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SetMarketSeries failed: %v", err)
+	}
+
+	return &market, nil
+}
+
+func (marketsRepository *MarketsRepository) CloseMarket(marketId uuid.UUID, changedBy string, reason string) (*sqlc.Market, []uuid.UUID, error) {
+	if marketsRepository.db == nil {
+		return nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	// Start a transaction
+	tx, err := marketsRepository.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	fromStatus, err := lockMarketForStatusChange(q, marketId, lib.MARKET_STATUS_CLOSED)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	market, err := q.CloseMarket(context.Background(), marketId)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("CloseMarket failed: %v", err)
+	}
+
+	err = recordMarketStatusChange(q, marketId, fromStatus, market.Status, changedBy, reason)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	closedTxIds, err := q.CloseAllOpenPredictionIntentsByMarketId(context.Background(), marketId)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("CloseAllOpenPredictionIntentsByMarketId failed: %v", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Closed market in database: %s (closed %d prediction intents)", market.MarketID.String(), len(closedTxIds))
+	return &market, closedTxIds, nil
+}
+
+/*
+*
+Resolve a market and cancel all of its open prediction intents in a single transaction
+Returns the resolved market and the txIds of the prediction intents that were cancelled
+*/
+func (marketsRepository *MarketsRepository) ResolveMarket(marketId string, outcome bool, changedBy string) (*sqlc.Market, []uuid.UUID, error) {
+	if marketsRepository.db == nil {
+		return nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	// Start a transaction
+	tx, err := marketsRepository.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	fromStatus, err := lockMarketForStatusChange(q, marketUUID, lib.MARKET_STATUS_RESOLVED)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	market, err := q.ResolveMarket(context.Background(), sqlc.ResolveMarketParams{
+		MarketID: marketUUID,
+		Outcome:  sql.NullBool{Bool: outcome, Valid: true},
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("ResolveMarket failed: %v", err)
+	}
+
+	err = recordMarketStatusChange(q, marketUUID, fromStatus, market.Status, changedBy, fmt.Sprintf("outcome=%t", outcome))
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	cancelledTxIds, err := q.CancelAllOpenPredictionIntentsByMarketId(context.Background(), marketUUID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("CancelAllOpenPredictionIntentsByMarketId failed: %v", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Resolved market in database: %s (outcome=%t, cancelled %d prediction intents)", market.MarketID.String(), outcome, len(cancelledTxIds))
+	return &market, cancelledTxIds, nil
+}
+
+/*
+*
+Void a market, cancel all of its open prediction intents and record the refunds in a single transaction
+Returns the voided market, the txIds of the prediction intents that were cancelled and the refunds
+*/
+func (marketsRepository *MarketsRepository) VoidMarket(marketId string, accountId string, reason string) (*sqlc.Market, []uuid.UUID, []sqlc.MarketRefund, error) {
+	if marketsRepository.db == nil {
+		return nil, nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	// Start a transaction
+	tx, err := marketsRepository.db.Begin()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	fromStatus, err := lockMarketForStatusChange(q, marketUUID, lib.MARKET_STATUS_VOIDED)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, err
+	}
+
+	market, err := q.VoidMarket(context.Background(), sqlc.VoidMarketParams{
+		MarketID:   marketUUID,
+		VoidReason: sql.NullString{String: strings.TrimSpace(reason), Valid: true},
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, fmt.Errorf("VoidMarket failed: %v", err)
+	}
+
+	err = recordMarketStatusChange(q, marketUUID, fromStatus, market.Status, accountId, reason)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, err
+	}
+
+	cancelledTxIds, err := q.CancelAllOpenPredictionIntentsByMarketId(context.Background(), marketUUID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, fmt.Errorf("CancelAllOpenPredictionIntentsByMarketId failed: %v", err)
+	}
+
+	// record the refund owed to every position holder - YES and NO tokens are refunded 50/50
+	positions, err := q.GetAllPositionsByMarketId(context.Background(), marketUUID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, nil, fmt.Errorf("GetAllPositionsByMarketId failed: %v", err)
+	}
+
+	var refunds []sqlc.MarketRefund
+	for _, position := range positions {
+		refund, err := q.CreateMarketRefund(context.Background(), sqlc.CreateMarketRefundParams{
+			MarketID:     marketUUID,
+			EvmAddress:   position.EvmAddress,
+			NYes:         position.NYes,
+			NNo:          position.NNo,
+			RefundAmount: (position.NYes + position.NNo) / 2, // same integer division as Prism.sol redeem()
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, nil, fmt.Errorf("CreateMarketRefund (evmAddress=%s) failed: %v", position.EvmAddress, err)
+		}
+		refunds = append(refunds, refund)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Voided market in database: %s (cancelled %d prediction intents, recorded %d refunds)", market.MarketID.String(), len(cancelledTxIds), len(refunds))
+	return &market, cancelledTxIds, refunds, nil
+}
+
+// all refunds owed to an evm address, across voided markets
+func (marketsRepository *MarketsRepository) GetMarketRefundsByEvmAddress(evmAddress string) ([]sqlc.MarketRefund, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	refunds, err := q.GetMarketRefundsByEvmAddress(context.Background(), evmAddress)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketRefundsByEvmAddress failed: %v", err)
+	}
+
+	return refunds, nil
+}
+
+/*
+*
+Pause/unpause/suspend/unsuspend a market and record who did it (and why) in a single transaction
+*/
+func (marketsRepository *MarketsRepository) ModerateMarket(marketId string, action lib.MarketModerationActionType, accountId string, reason string) (*sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	// Start a transaction
+	tx, err := marketsRepository.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	var market sqlc.Market
+	switch action {
+	case lib.MARKET_PAUSE, lib.MARKET_UNPAUSE:
+		toStatus := lib.MARKET_STATUS_OPEN
+		if action == lib.MARKET_PAUSE {
+			toStatus = lib.MARKET_STATUS_PAUSED
+		}
+		var fromStatus string
+		fromStatus, err = lockMarketForStatusChange(q, marketUUID, toStatus)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		market, err = q.SetMarketStatus(context.Background(), sqlc.SetMarketStatusParams{
+			ToStatus:   toStatus,
+			MarketID:   marketUUID,
+			FromStatus: fromStatus,
+		})
+		if err == nil {
+			err = recordMarketStatusChange(q, marketUUID, fromStatus, toStatus, accountId, reason)
+		}
+	case lib.MARKET_SUSPEND, lib.MARKET_UNSUSPEND:
+		market, err = q.SetMarketIsSuspended(context.Background(), sqlc.SetMarketIsSuspendedParams{
+			MarketID:    marketUUID,
+			IsSuspended: action == lib.MARKET_SUSPEND,
+		})
+	default:
+		tx.Rollback()
+		return nil, fmt.Errorf("invalid moderation action: %s", action)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to %s market: %v", action, err)
+	}
+
+	_, err = q.CreateMarketModerationLog(context.Background(), sqlc.CreateMarketModerationLogParams{
+		MarketID:  marketUUID,
+		Action:    string(action),
+		AccountID: accountId,
+		Reason:    strings.TrimSpace(reason),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("CreateMarketModerationLog failed: %v", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Market %s: %s by %s (reason: %s)", market.MarketID.String(), action, accountId, reason)
+	return &market, nil
+}
+
+/*
+*
+Update the off-chain fields of a market and record one market_revisions row per field that actually changed.
+nil params (and a nil categoryIds slice) leave the field unchanged.
+*/
+func (marketsRepository *MarketsRepository) UpdateMarket(marketId string, description *string, imageUrl *string, closesAt *time.Time, categoryIds []int32, accountId string, reason string) (*sqlc.Market, []sqlc.MarketRevision, error) {
+	if marketsRepository.db == nil {
+		return nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	// Start a transaction
+	tx, err := marketsRepository.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	current, err := q.GetMarketForUpdate(context.Background(), marketUUID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("GetMarketForUpdate failed: %v", err)
+	}
+	if current.ResolvedAt.Valid || current.VoidedAt.Valid {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("market %s is resolved or voided and can no longer be edited", marketId)
+	}
+
+	type change struct{ field, oldValue, newValue string }
+	var changes []change
+
+	newDescription := current.Description
+	if description != nil && strings.TrimSpace(*description) != current.Description {
+		newDescription = strings.TrimSpace(*description)
+		changes = append(changes, change{lib.MARKET_REVISION_DESCRIPTION, current.Description, newDescription})
+	}
+
+	newImageUrl := current.ImageUrl
+	if imageUrl != nil && strings.TrimSpace(*imageUrl) != current.ImageUrl.String {
+		trimmed := strings.TrimSpace(*imageUrl)
+		newImageUrl = sql.NullString{String: trimmed, Valid: trimmed != ""}
+		changes = append(changes, change{lib.MARKET_REVISION_IMAGE_URL, current.ImageUrl.String, trimmed})
+	}
+
+	newClosesAt := current.ClosesAt
+	if closesAt != nil && !closesAt.Equal(current.ClosesAt) {
+		if current.ClosedAt.Valid {
+			tx.Rollback()
+			return nil, nil, fmt.Errorf("market %s is already closed - closesAt can no longer be changed", marketId)
+		}
+		newClosesAt = *closesAt
+		changes = append(changes, change{lib.MARKET_REVISION_CLOSES_AT, current.ClosesAt.UTC().Format(time.RFC3339), newClosesAt.UTC().Format(time.RFC3339)})
+	}
+
+	if categoryIds != nil {
+		currentCategoryIds, err := q.GetMarketCategoryIds(context.Background(), marketUUID)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, fmt.Errorf("GetMarketCategoryIds failed: %v", err)
+		}
+
+		sortedCategoryIds := append([]int32{}, categoryIds...)
+		slices.Sort(sortedCategoryIds)
+		if !slices.Equal(currentCategoryIds, sortedCategoryIds) {
+			err = q.DeleteMarketCategories(context.Background(), marketUUID)
+			if err != nil {
+				tx.Rollback()
+				return nil, nil, fmt.Errorf("DeleteMarketCategories failed: %v", err)
+			}
+			for _, categoryId := range sortedCategoryIds {
+				err = q.AddMarketCategory(context.Background(), sqlc.AddMarketCategoryParams{
+					MarketID:   marketUUID,
+					CategoryID: categoryId,
+				})
+				if err != nil {
+					tx.Rollback()
+					return nil, nil, fmt.Errorf("AddMarketCategory (categoryId=%d) failed: %v", categoryId, err)
+				}
+			}
+			changes = append(changes, change{lib.MARKET_REVISION_CATEGORY_IDS, joinCategoryIds(currentCategoryIds), joinCategoryIds(sortedCategoryIds)})
+		}
+	}
+
+	if len(changes) == 0 {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("nothing to update for market %s", marketId)
+	}
+
+	market, err := q.UpdateMarketDetails(context.Background(), sqlc.UpdateMarketDetailsParams{
+		MarketID:    marketUUID,
+		Description: newDescription,
+		ImageUrl:    newImageUrl,
+		ClosesAt:    newClosesAt,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("UpdateMarketDetails failed: %v", err)
+	}
+
+	var revisions []sqlc.MarketRevision
+	for _, c := range changes {
+		revision, err := q.CreateMarketRevision(context.Background(), sqlc.CreateMarketRevisionParams{
+			MarketID:  marketUUID,
+			Field:     c.field,
+			OldValue:  c.oldValue,
+			NewValue:  c.newValue,
+			AccountID: accountId,
+			Reason:    strings.TrimSpace(reason),
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, fmt.Errorf("CreateMarketRevision failed: %v", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Market %s updated by %s (%d fields changed)", marketId, accountId, len(revisions))
+	return &market, revisions, nil
+}
+
+func (marketsRepository *MarketsRepository) GetMarketRevisions(marketId string, limit int32, offset int32) ([]sqlc.MarketRevision, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	revisions, err := q.GetMarketRevisions(context.Background(), sqlc.GetMarketRevisionsParams{
+		MarketID: marketUUID,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketRevisions failed: %v", err)
+	}
+
+	return revisions, nil
+}
+
+// category ids as stored in market_revisions, e.g. "1,4,7"
+func joinCategoryIds(categoryIds []int32) string {
+	ids := make([]string, len(categoryIds))
+	for i, id := range categoryIds {
+		ids[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(ids, ",")
+}
+
+// stats for each of the given markets - unknown marketIds are left out of the result
+func (marketsRepository *MarketsRepository) GetMarketStats(marketIds []string) ([]sqlc.GetMarketStatsRow, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUIDs := make([]uuid.UUID, 0, len(marketIds))
+	for _, marketId := range marketIds {
+		marketUUID, err := uuid.Parse(marketId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+		}
+		marketUUIDs = append(marketUUIDs, marketUUID)
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	stats, err := q.GetMarketStats(context.Background(), marketUUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketStats failed: %v", err)
+	}
+
+	return stats, nil
+}
+
+/*
+*
+Move a market to toStatus (for changes that only move the status, e.g. closed -> resolving) and record it in market_status_history
+*/
+func (marketsRepository *MarketsRepository) SetMarketStatus(marketId uuid.UUID, toStatus string, changedBy string, reason string) (*sqlc.Market, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	// Start a transaction
+	tx, err := marketsRepository.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	fromStatus, err := lockMarketForStatusChange(q, marketId, toStatus)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	market, err := q.SetMarketStatus(context.Background(), sqlc.SetMarketStatusParams{
+		ToStatus:   toStatus,
+		MarketID:   marketId,
+		FromStatus: fromStatus,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("SetMarketStatus failed: %v", err)
+	}
+
+	err = recordMarketStatusChange(q, marketId, fromStatus, toStatus, changedBy, reason)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Market %s: %s -> %s by %s", marketId.String(), fromStatus, toStatus, changedBy)
+	return &market, nil
+}
+
+func (marketsRepository *MarketsRepository) GetMarketStatusHistory(marketId string) ([]sqlc.MarketStatusHistory, error) {
+	if marketsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	q := sqlc.New(marketsRepository.db)
+	history, err := q.GetMarketStatusHistory(context.Background(), marketUUID)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketStatusHistory failed: %v", err)
+	}
+
+	return history, nil
+}
+
+// lock the market for the rest of the transaction and check that moving it to toStatus is legal - returns the current status
+func lockMarketForStatusChange(q *sqlc.Queries, marketId uuid.UUID, toStatus string) (string, error) {
+	market, err := q.GetMarketForUpdate(context.Background(), marketId)
+	if err != nil {
+		return "", fmt.Errorf("GetMarketForUpdate failed: %v", err)
+	}
+
+	if !lib.IsLegalMarketStatusTransition(market.Status, toStatus) {
+		return "", fmt.Errorf("market %s can not move from %s to %s", marketId.String(), market.Status, toStatus)
+	}
+
+	return market.Status, nil
+}
+
+// fromStatus is empty when the market is created
+func recordMarketStatusChange(q *sqlc.Queries, marketId uuid.UUID, fromStatus string, toStatus string, changedBy string, reason string) error {
+	_, err := q.CreateMarketStatusHistory(context.Background(), sqlc.CreateMarketStatusHistoryParams{
+		MarketID:   marketId,
+		FromStatus: sql.NullString{String: fromStatus, Valid: fromStatus != ""},
+		ToStatus:   toStatus,
+		ChangedBy:  changedBy,
+		Reason:     strings.TrimSpace(reason),
+	})
+	if err != nil {
+		return fmt.Errorf("CreateMarketStatusHistory failed: %v", err)
+	}
+	return nil
+}
@@ -113,3 +113,24 @@ func (positionsRepository *PositionsRepository) GetAllPositions(ctx context.Cont
 
 	return result, nil
 }
+
+// the position held on a market by an evm address - returns nil (and no error) if it holds no YES or NO tokens there
+func (positionsRepository *PositionsRepository) GetHolderPosition(marketId uuid.UUID, evmAddress string) (*sqlc.Position, error) {
+	if positionsRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(positionsRepository.db)
+	position, err := q.GetHolderPosition(context.Background(), sqlc.GetHolderPositionParams{
+		MarketID:   marketId,
+		EvmAddress: evmAddress,
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetHolderPosition failed: %v", err)
+	}
+
+	return &position, nil
+}
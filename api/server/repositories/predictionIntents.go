@@ -46,28 +46,188 @@ func (pir *PredictionIntentsRepository) InitDb() error {
 }
 
 // SaveOrderRequest saves an order request to the database
-func (pir *PredictionIntentsRepository) CreateOrderIntentRequest(req *pb_api.PredictionIntentRequest) (*sqlc.PredictionIntent, error) {
+func (pir *PredictionIntentsRepository) CreateOrderIntentRequest(req *pb_api.PredictionIntentRequest, timeInForce string) (*sqlc.PredictionIntent, error) {
 	if pir.db == nil {
 		return nil, fmt.Errorf("could not connect to database")
 	}
 
-	txUUID, err := uuid.Parse(req.TxId)
+	params, err := createPredictionIntentParams(req, timeInForce)
+	if err != nil {
+		return nil, err
+	}
+
+	q := sqlc.New(pir.db)
+	newPredictionIntent, err := q.CreatePredictionIntent(context.Background(), params)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePredictionIntent failed: %v", err)
+	}
+
+	log.Printf("Saved prediction intent to database for account %s", req.AccountId)
+	return &newPredictionIntent, nil
+}
+
+/*
+*
+Saves the replacement of txId (linked both ways) in one transaction - before the CLOB swaps the orders, so match events
+of the replacement find it. txId stays open until CompletePredictionIntentReplacement (or RevertPredictionIntentReplacement).
+Fails if txId is no longer open or another replacement of it is already under way.
+*/
+func (pir *PredictionIntentsRepository) CreatePredictionIntentReplacement(txId string, replacement *pb_api.PredictionIntentRequest, timeInForce string) (*sqlc.PredictionIntent, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	txUUID, err := uuid.Parse(txId)
 	if err != nil {
 		return nil, fmt.Errorf("invalid txId uuid: %v", err)
 	}
 
+	params, err := createPredictionIntentParams(replacement, timeInForce)
+	if err != nil {
+		return nil, err
+	}
+	params.ReplacesTxID = uuid.NullUUID{UUID: txUUID, Valid: true}
+
+	// Start a transaction
+	tx, err := pir.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	nLinked, err := q.LinkPredictionIntentReplacement(context.Background(), sqlc.LinkPredictionIntentReplacementParams{
+		ReplacedByTxID: uuid.NullUUID{UUID: params.TxID, Valid: true},
+		TxID:           txUUID,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("LinkPredictionIntentReplacement failed: %v", err)
+	}
+	if nLinked == 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("prediction intent %s is no longer open (or is already being replaced)", txId)
+	}
+
+	newPredictionIntent, err := q.CreatePredictionIntent(context.Background(), params)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("CreatePredictionIntent failed: %v", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Saved replacement %s of prediction intent %s in database for account %s", replacement.TxId, txId, replacement.AccountId)
+	return &newPredictionIntent, nil
+}
+
+// the CLOB swapped the orders - cancel txId. Returns false if it had already left the book some other way (e.g. cancelled by the account meanwhile)
+func (pir *PredictionIntentsRepository) CompletePredictionIntentReplacement(txId string, replacementTxId string, replaceSig string) (bool, error) {
+	if pir.db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	txUUID, err := uuid.Parse(txId)
+	if err != nil {
+		return false, fmt.Errorf("invalid txId uuid: %v", err)
+	}
+	replacementUUID, err := uuid.Parse(replacementTxId)
+	if err != nil {
+		return false, fmt.Errorf("invalid replacement txId uuid: %v", err)
+	}
+
+	q := sqlc.New(pir.db)
+	nCancelled, err := q.CancelReplacedPredictionIntent(context.Background(), sqlc.CancelReplacedPredictionIntentParams{
+		CancelSig:      sql.NullString{String: replaceSig, Valid: true},
+		TxID:           txUUID,
+		ReplacedByTxID: uuid.NullUUID{UUID: replacementUUID, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("CancelReplacedPredictionIntent failed: %v", err)
+	}
+
+	log.Printf("Replaced prediction intent %s with %s in database", txId, replacementTxId)
+	return nCancelled > 0, nil
+}
+
+// the CLOB did not swap the orders - unlink txId and delete its replacement in one transaction, as if the replace never happened
+func (pir *PredictionIntentsRepository) RevertPredictionIntentReplacement(txId string, replacementTxId string) error {
+	if pir.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	txUUID, err := uuid.Parse(txId)
+	if err != nil {
+		return fmt.Errorf("invalid txId uuid: %v", err)
+	}
+	replacementUUID, err := uuid.Parse(replacementTxId)
+	if err != nil {
+		return fmt.Errorf("invalid replacement txId uuid: %v", err)
+	}
+
+	// Start a transaction
+	tx, err := pir.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	q := sqlc.New(tx)
+	err = q.UnlinkPredictionIntentReplacement(context.Background(), sqlc.UnlinkPredictionIntentReplacementParams{
+		TxID:           txUUID,
+		ReplacedByTxID: uuid.NullUUID{UUID: replacementUUID, Valid: true},
+	})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("UnlinkPredictionIntentReplacement failed: %v", err)
+	}
+
+	_, err = q.DeletePredictionIntentReplacement(context.Background(), sqlc.DeletePredictionIntentReplacementParams{
+		TxID:         replacementUUID,
+		ReplacesTxID: uuid.NullUUID{UUID: txUUID, Valid: true},
+	})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("DeletePredictionIntentReplacement failed: %v", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	log.Printf("Reverted replacement %s of prediction intent %s in database", replacementTxId, txId)
+	return nil
+}
+
+func createPredictionIntentParams(req *pb_api.PredictionIntentRequest, timeInForce string) (sqlc.CreatePredictionIntentParams, error) {
+	txUUID, err := uuid.Parse(req.TxId)
+	if err != nil {
+		return sqlc.CreatePredictionIntentParams{}, fmt.Errorf("invalid txId uuid: %v", err)
+	}
+
 	marketUUID, err := uuid.Parse(req.MarketId)
 	if err != nil {
-		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+		return sqlc.CreatePredictionIntentParams{}, fmt.Errorf("invalid marketId uuid: %v", err)
 	}
 
 	generatedAt, err := time.Parse(time.RFC3339, req.GeneratedAt) // Zulu time (RFC3339)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GeneratedAt timestamp: %v", err)
+		return sqlc.CreatePredictionIntentParams{}, fmt.Errorf("invalid GeneratedAt timestamp: %v", err)
 	}
 	generatedAt = generatedAt.UTC()
 
-	params := sqlc.CreatePredictionIntentParams{
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		parsed, err := time.Parse(time.RFC3339, req.GetExpiresAt())
+		if err != nil {
+			return sqlc.CreatePredictionIntentParams{}, fmt.Errorf("invalid ExpiresAt timestamp: %v", err)
+		}
+		expiresAt = sql.NullTime{Time: parsed.UTC(), Valid: true}
+	}
+
+	return sqlc.CreatePredictionIntentParams{
 		TxID:         txUUID,
 		Net:          req.Net,
 		MarketID:     marketUUID,
@@ -80,36 +240,82 @@ func (pir *PredictionIntentsRepository) CreateOrderIntentRequest(req *pb_api.Pre
 		PublicKeyHex: req.PublicKey,
 		Evmaddress:   req.EvmAddress,
 		Keytype:      int32(req.KeyType),
+		ExpiresAt:    expiresAt,
+		ExpirySig:    sql.NullString{String: req.GetExpirySig(), Valid: req.ExpirySig != nil},
+		TimeInForce:  timeInForce,
+	}, nil
+}
+
+// cancelSig is empty when the system cancels - returns false if the intent was no longer open
+func (pir *PredictionIntentsRepository) CancelPredictionIntent(txId string, cancelSig string) (bool, error) {
+	if pir.db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	txUUID, err := uuid.Parse(txId)
+	if err != nil {
+		return false, fmt.Errorf("invalid txId uuid: %v", err)
 	}
 
 	q := sqlc.New(pir.db)
-	newPredictionIntent, err := q.CreatePredictionIntent(context.Background(), params)
+	nCancelled, err := q.CancelPredictionIntent(context.Background(), sqlc.CancelPredictionIntentParams{
+		TxID:      txUUID,
+		CancelSig: sql.NullString{String: cancelSig, Valid: cancelSig != ""},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("CreatePredictionIntent failed: %v", err)
+		return false, fmt.Errorf("CancelPredictionIntent failed: %v", err)
 	}
 
-	log.Printf("Saved prediction intent to database for account %s", req.AccountId)
-	return &newPredictionIntent, nil
+	log.Printf("Cancelled prediction intent in database for txId: %s", txId)
+	return nCancelled > 0, nil
 }
 
-func (pir *PredictionIntentsRepository) CancelPredictionIntent(txId string) error {
+// marketId and side are optional ("" => all markets / both sides) - returns the intents that were cancelled
+func (pir *PredictionIntentsRepository) GetCancellablePredictionIntentsByAccountId(net string, accountId string, marketId string, side string, generatedBefore time.Time) ([]sqlc.GetCancellablePredictionIntentsByAccountIdRow, error) {
 	if pir.db == nil {
-		return fmt.Errorf("database not initialized")
+		return nil, fmt.Errorf("database not initialized")
 	}
 
-	txUUID, err := uuid.Parse(txId)
+	var marketUUID uuid.NullUUID
+	if marketId != "" {
+		parsed, err := uuid.Parse(marketId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+		}
+		marketUUID = uuid.NullUUID{UUID: parsed, Valid: true}
+	}
+
+	q := sqlc.New(pir.db)
+	open, err := q.GetCancellablePredictionIntentsByAccountId(context.Background(), sqlc.GetCancellablePredictionIntentsByAccountIdParams{
+		Net:             net,
+		AccountID:       accountId,
+		MarketID:        marketUUID,
+		Side:            sql.NullString{String: side, Valid: side != ""},
+		GeneratedBefore: generatedBefore.UTC(),
+	})
 	if err != nil {
-		return fmt.Errorf("invalid txId uuid: %v", err)
+		return nil, fmt.Errorf("GetCancellablePredictionIntentsByAccountId failed: %v", err)
+	}
+
+	return open, nil
+}
+
+func (pir *PredictionIntentsRepository) CancelPredictionIntents(txIds []uuid.UUID, cancelSig string) ([]uuid.UUID, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
 	}
 
 	q := sqlc.New(pir.db)
-	err = q.CancelPredictionIntent(context.Background(), txUUID)
+	cancelledTxIds, err := q.CancelPredictionIntents(context.Background(), sqlc.CancelPredictionIntentsParams{
+		CancelSig: sql.NullString{String: cancelSig, Valid: true},
+		TxIds:     txIds,
+	})
 	if err != nil {
-		return fmt.Errorf("CancelPredictionIntent failed: %v", err)
+		return nil, fmt.Errorf("CancelPredictionIntents failed: %v", err)
 	}
 
-	log.Printf("Cancelled prediction intent in database for txId: %s", txId)
-	return nil
+	log.Printf("Cancelled %d prediction intents in database", len(cancelledTxIds))
+	return cancelledTxIds, nil
 }
 
 func (pir *PredictionIntentsRepository) GetAllOpenPredictionIntentsByMarketId(marketId string) (*[]sqlc.PredictionIntent, error) {
@@ -217,6 +423,35 @@ func (pir *PredictionIntentsRepository) MarkPredictionIntentAsEvicted(txId uuid.
 	return nil
 }
 
+func (pir *PredictionIntentsRepository) GetAllAccountIdsWithOpenPredictionIntents() ([]string, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(pir.db)
+	accountIds, err := q.GetAllAccountIdsWithOpenPredictionIntents(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetAllAccountIdsWithOpenPredictionIntents failed: %v", err)
+	}
+
+	return accountIds, nil
+}
+
+// oldest first, across all markets and networks
+func (pir *PredictionIntentsRepository) GetAllOpenPredictionIntentsByAccountId(accountId string) ([]sqlc.GetAllOpenPredictionIntentsByAccountIdRow, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(pir.db)
+	predictionIntents, err := q.GetAllOpenPredictionIntentsByAccountId(context.Background(), accountId)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllOpenPredictionIntentsByAccountId failed: %v", err)
+	}
+
+	return predictionIntents, nil
+}
+
 func (pir *PredictionIntentsRepository) GetAllOpenPredictionIntentsByEvmAddress(evmAddress string) ([]sqlc.PredictionIntent, error) {
 	if pir.db == nil {
 		return nil, fmt.Errorf("database not initialized")
@@ -246,4 +481,70 @@ func (pir *PredictionIntentsRepository) GetAllPredictionIntents(limit int, offse
 	}
 
 	return predictionIntents, nil
-}
\ No newline at end of file
+}
+
+// returns nil (and no error) if there is no prediction intent with this txId
+func (pir *PredictionIntentsRepository) GetPredictionIntent(txId string) (*sqlc.PredictionIntent, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	txUUID, err := uuid.Parse(txId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid txId uuid: %v", err)
+	}
+
+	q := sqlc.New(pir.db)
+	predictionIntent, err := q.GetPredictionIntent(context.Background(), txUUID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetPredictionIntent failed: %v", err)
+	}
+
+	return &predictionIntent, nil
+}
+
+func (pir *PredictionIntentsRepository) GetPredictionIntentFills(txId uuid.UUID) ([]sqlc.GetPredictionIntentFillsRow, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(pir.db)
+	fills, err := q.GetPredictionIntentFills(context.Background(), txId)
+	if err != nil {
+		return nil, fmt.Errorf("GetPredictionIntentFills failed: %v", err)
+	}
+
+	return fills, nil
+}
+
+func (pir *PredictionIntentsRepository) GetDuePredictionIntents() ([]sqlc.GetDuePredictionIntentsRow, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(pir.db)
+	dueIntents, err := q.GetDuePredictionIntents(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetDuePredictionIntents failed: %v", err)
+	}
+
+	return dueIntents, nil
+}
+
+// returns the txIds that were still open (and are now expired)
+func (pir *PredictionIntentsRepository) MarkPredictionIntentsAsExpired(txIds []uuid.UUID) ([]uuid.UUID, error) {
+	if pir.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(pir.db)
+	expiredTxIds, err := q.MarkPredictionIntentsAsExpired(context.Background(), txIds)
+	if err != nil {
+		return nil, fmt.Errorf("MarkPredictionIntentsAsExpired failed: %v", err)
+	}
+
+	return expiredTxIds, nil
+}
@@ -134,3 +134,18 @@ func (priceRepository *PriceRepository) GetLatestPriceByMarket(marketId string)
 
 	return priceRow.Price, nil
 }
+
+// batch version of GetLatestPriceByMarket - markets with no price history are left out of the result
+func (priceRepository *PriceRepository) GetLatestPricesForMarkets(marketIds []uuid.UUID) ([]sqlc.GetLatestPricesForMarketsRow, error) {
+	if priceRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(priceRepository.db)
+	rows, err := q.GetLatestPricesForMarkets(context.Background(), marketIds)
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestPricesForMarkets failed: %v", err)
+	}
+
+	return rows, nil
+}
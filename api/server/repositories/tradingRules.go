@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	sqlc "api/gen/sqlc"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+type TradingRulesRepository struct {
+	db *sql.DB
+}
+
+func (tradingRulesRepository *TradingRulesRepository) CloseDb() error {
+	var err = tradingRulesRepository.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close database: %v", err)
+	}
+	return nil
+}
+
+func (tradingRulesRepository *TradingRulesRepository) InitDb() error {
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_UNAME"), os.Getenv("DB_PWORD"), os.Getenv("DB_NAME"))
+
+	var db, err = sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	tradingRulesRepository.db = db
+
+	// Verify connection
+	if err = db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	log.Println("DB: TradingRulesRepository connected successfully")
+	return nil
+}
+
+// marketId "" => the rules of the whole network. maxNotionalUsd nil => no maximum
+func (tradingRulesRepository *TradingRulesRepository) UpsertTradingRules(net string, marketId string, minNotionalUsd float64, maxNotionalUsd *float64, priceTick float64, qtyStep float64, updatedBy string) (*sqlc.TradingRule, error) {
+	if tradingRulesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	maxNotional := sql.NullFloat64{}
+	if maxNotionalUsd != nil {
+		maxNotional = sql.NullFloat64{Float64: *maxNotionalUsd, Valid: true}
+	}
+
+	q := sqlc.New(tradingRulesRepository.db)
+	if marketId == "" {
+		tradingRules, err := q.UpsertNetworkTradingRules(context.Background(), sqlc.UpsertNetworkTradingRulesParams{
+			Net:            net,
+			MinNotionalUsd: minNotionalUsd,
+			MaxNotionalUsd: maxNotional,
+			PriceTick:      priceTick,
+			QtyStep:        qtyStep,
+			UpdatedBy:      updatedBy,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("UpsertNetworkTradingRules failed: %v", err)
+		}
+		return &tradingRules, nil
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+	tradingRules, err := q.UpsertMarketTradingRules(context.Background(), sqlc.UpsertMarketTradingRulesParams{
+		Net:            net,
+		MarketID:       uuid.NullUUID{UUID: marketUUID, Valid: true},
+		MinNotionalUsd: minNotionalUsd,
+		MaxNotionalUsd: maxNotional,
+		PriceTick:      priceTick,
+		QtyStep:        qtyStep,
+		UpdatedBy:      updatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("UpsertMarketTradingRules failed: %v", err)
+	}
+	return &tradingRules, nil
+}
+
+// the market's own rules, else its network's - returns nil (and no error) if there are neither
+func (tradingRulesRepository *TradingRulesRepository) GetTradingRules(net string, marketId string) (*sqlc.TradingRule, error) {
+	if tradingRulesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	marketUUID, err := uuid.Parse(marketId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marketId uuid: %v", err)
+	}
+
+	q := sqlc.New(tradingRulesRepository.db)
+	tradingRules, err := q.GetTradingRules(context.Background(), sqlc.GetTradingRulesParams{
+		Net:      net,
+		MarketID: uuid.NullUUID{UUID: marketUUID, Valid: true},
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetTradingRules failed: %v", err)
+	}
+	return &tradingRules, nil
+}
+
+func (tradingRulesRepository *TradingRulesRepository) GetAllNetworkTradingRules() ([]sqlc.TradingRule, error) {
+	if tradingRulesRepository.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	q := sqlc.New(tradingRulesRepository.db)
+	tradingRules, err := q.GetAllNetworkTradingRules(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("GetAllNetworkTradingRules failed: %v", err)
+	}
+	return tradingRules, nil
+}
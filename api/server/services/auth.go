@@ -158,23 +158,57 @@ func (as *AuthService) HasRole(ctx context.Context, role lib.RolesType) bool {
 
 			for _, r := range rolesClaim {
 				if roleStr, ok := r.(string); ok && roleStr == string(role) {
+					// 5. Let's also check the database for the user's role (e.g. revoked tokens won't work)
+					// TODO - may not be needed - sig check sufficient
+					// as.userRoleRepository.Get(claims["sub"].(string), claims["network"].(string))
+
+					as.log.Log(INFO, "User logged in OK %s", claims["accountId"])
 					return true
 				}
 			}
 			as.log.Log(ERROR, "required role %s not found in user's roles", role)
-
-			// 5. Let's also check the database for the user's role (e.g. revoked tokens won't work)
-			// TODO - may not be needed - sig check sufficient
-			// as.userRoleRepository.Get(claims["sub"].(string), claims["network"].(string))
-
-			as.log.Log(INFO, "User logged in OK %s", claims["accountId"].(string))
-			return true
+			return false
 		}
 	}
 
 	return false
 }
 
+/*
+*
+Returns the accountId claim of the (verified) JWT passed in the authorization header
+*/
+func (as *AuthService) GetAccountId(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", as.log.Log(ERROR, "no metadata in request context")
+	}
+	authHeaders := md.Get("authorization")
+	if len(authHeaders) == 0 {
+		return "", as.log.Log(ERROR, "no authorization header")
+	}
+
+	parts := strings.Split(authHeaders[0], " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", as.log.Log(ERROR, "invalid authorization header format")
+	}
+
+	claims := jwt.MapClaims{}
+	tok, err := jwt.ParseWithClaims(parts[1], claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil || !tok.Valid {
+		return "", as.log.Log(ERROR, "invalid JWT token: %v", err)
+	}
+
+	accountId, ok := claims["accountId"].(string)
+	if !ok || accountId == "" {
+		return "", as.log.Log(ERROR, "invalid accountId claim in JWT token")
+	}
+
+	return accountId, nil
+}
+
 func (as *AuthService) GetRoles(ctx context.Context, accountId string, network string) ([]string, error) {
 	return as.userRoleRepository.GetRolesByUserAndNetwork(accountId, network)
 }
@@ -0,0 +1,81 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	repositories "api/server/repositories"
+)
+
+type CategoriesService struct {
+	log                  *LogService
+	categoriesRepository *repositories.CategoriesRepository
+}
+
+func (cs *CategoriesService) Init(log *LogService, categoriesRepository *repositories.CategoriesRepository) error {
+	cs.log = log
+	cs.categoriesRepository = categoriesRepository
+
+	cs.log.Log(INFO, "Service: Categories service initialized successfully")
+	return nil
+}
+
+func (cs *CategoriesService) GetCategories(activeOnly bool) (*pb_api.CategoriesResponse, error) {
+	categories, err := cs.categoriesRepository.GetCategories(activeOnly)
+	if err != nil {
+		return nil, cs.log.Log(ERROR, "failed to get categories: %v", err)
+	}
+
+	var categoryResponses []*pb_api.Category
+	for _, category := range categories {
+		categoryResponses = append(categoryResponses, cs.mapCategoryToCategoryResponse(&category))
+	}
+
+	return &pb_api.CategoriesResponse{
+		Categories: categoryResponses,
+	}, nil
+}
+
+func (cs *CategoriesService) CreateCategory(req *pb_api.CreateCategoryRequest) (*pb_api.Category, error) {
+	category, err := cs.categoriesRepository.CreateCategory(req.Name, req.Description, req.IsActive, req.SortOrder)
+	if err != nil {
+		return nil, cs.log.Log(ERROR, "failed to create category: %v", err)
+	}
+
+	cs.log.Log(INFO, "Category %d (%s) created", category.ID, category.Name)
+	return cs.mapCategoryToCategoryResponse(category), nil
+}
+
+func (cs *CategoriesService) UpdateCategory(req *pb_api.UpdateCategoryRequest) (*pb_api.Category, error) {
+	category, err := cs.categoriesRepository.UpdateCategory(req.Id, req.Name, req.Description, req.IsActive, req.SortOrder)
+	if err != nil {
+		return nil, cs.log.Log(ERROR, "failed to update category %d: %v", req.Id, err)
+	}
+
+	cs.log.Log(INFO, "Category %d (%s) updated", category.ID, category.Name)
+	return cs.mapCategoryToCategoryResponse(category), nil
+}
+
+func (cs *CategoriesService) DeleteCategory(id int32) (bool, error) {
+	isDeleted, err := cs.categoriesRepository.DeleteCategory(id)
+	if err != nil {
+		return false, cs.log.Log(ERROR, "failed to delete category %d: %v", id, err)
+	}
+	if !isDeleted {
+		return false, cs.log.Log(ERROR, "category %d not found", id)
+	}
+
+	cs.log.Log(INFO, "Category %d deleted", id)
+	return true, nil
+}
+
+func (cs *CategoriesService) mapCategoryToCategoryResponse(category *sqlc.Category) *pb_api.Category {
+	return &pb_api.Category{
+		Id:          category.ID,
+		Name:        category.Name,
+		Description: category.Description.String,
+		IsActive:    category.IsActive.Bool,
+		SortOrder:   category.SortOrder,
+		CreatedAt:   category.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   category.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -1,14 +1,18 @@
 package services
 
 import (
+	pb_clob "api/gen/clob"
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
 	repositories "api/server/repositories"
+	"context"
+	"encoding/json"
 	"fmt"
-	"math"
-	"os"
-	"strconv"
-	"strings"
+	"time"
 
-	hiero "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CronService struct {
@@ -17,16 +21,26 @@ type CronService struct {
 	marketsRepository           *repositories.MarketsRepository
 	predictionIntentsRepository *repositories.PredictionIntentsRepository
 	hederaService               *HederaService
+	riskService                 *RiskService
 	predictionIntentsService    *PredictionIntentsService
+	natsService                 *NatsService
+	resolutionsService          *ResolutionsService
+	marketImagesService         *MarketImagesService
+	marketSeriesService         *MarketSeriesService
 }
 
-func (cs *CronService) Init(log *LogService, mr *repositories.MarketsRepository, pir *repositories.PredictionIntentsRepository, hs *HederaService, pis *PredictionIntentsService) error {
+func (cs *CronService) Init(log *LogService, mr *repositories.MarketsRepository, pir *repositories.PredictionIntentsRepository, hs *HederaService, rks *RiskService, pis *PredictionIntentsService, ns *NatsService, rs *ResolutionsService, mis *MarketImagesService, mss *MarketSeriesService) error {
 	// inject deps
 	cs.log = log
 	cs.marketsRepository = mr
 	cs.predictionIntentsRepository = pir
 	cs.hederaService = hs
+	cs.riskService = rks
 	cs.predictionIntentsService = pis
+	cs.natsService = ns
+	cs.resolutionsService = rs
+	cs.marketImagesService = mis
+	cs.marketSeriesService = mss
 
 	cs.log.Log(INFO, "Service: Cron service initialized successfully")
 	return nil
@@ -35,7 +49,14 @@ func (cs *CronService) Init(log *LogService, mr *repositories.MarketsRepository,
 func (cs *CronService) CronJob() {
 	cs.log.Log(INFO, "CronService: Running CronJob...")
 
+	cs.CloseDueMarkets() // close first - no point checking funds for intents on markets that just closed
+	cs.RetryClobRemovals()
+	cs.ResolveDueMarkets()
+	cs.resolutionsService.FinalizeDueResolutions() // undisputed outcomes whose dispute window has passed
+	cs.marketSeriesService.CreateDueMarkets()      // upcoming markets of recurring series
+	cs.ExpireDuePredictionIntents()                // before the funds check - expired intents no longer need funding
 	cs.KickOutOrderIntentsNotBackedByFunds()
+	cs.marketImagesService.DeleteExpiredUploads()
 
 	cs.log.Log(INFO, "CronService: CronJob completed.")
 }
@@ -47,117 +68,235 @@ func (cs *CronService) UpdatePositionsWithRealPositions() error {
 	return nil
 }
 
-func (cs *CronService) KickOutOrderIntentsNotBackedByFunds() {
-	cs.log.Log(INFO, "KickOutOrderIntentsNotBackedByFunds: Starting process to kick out order intents not backed by funds...")
+/*
+*
+Close every market whose closes_at has passed:
+the market and its remaining open prediction intents are marked closed_at in the db,
+the book is removed from the CLOB and a market-closed event is published on NATS.
+*/
+func (cs *CronService) CloseDueMarkets() {
+	markets, err := cs.marketsRepository.GetMarketsDueForClose()
+	if err != nil {
+		cs.log.Log(ERROR, "Failed to fetch markets due for close: %v", err)
+		return
+	}
+
+	for _, dueMarket := range markets {
+		marketId := dueMarket.MarketID.String()
+
+		// Step 1:
+		// close the market and its open prediction intents in the **database**
+		market, closedTxIds, err := cs.marketsRepository.CloseMarket(dueMarket.MarketID, lib.MARKET_STATUS_CHANGED_BY_SYSTEM, "closes_at passed")
+		if err != nil {
+			cs.log.Log(ERROR, "Failed to close market %s: %v", marketId, err)
+			continue
+		}
+		cs.log.Log(INFO, "Closed market %s (closesAt=%s), closed %d open prediction intents", marketId, market.ClosesAt.Format(time.RFC3339), len(closedTxIds))
+
+		// Step 2:
+		// remove the market from the **CLOB** - new orders are already rejected at intake once closes_at has passed
+		err = removeMarketFromClob(cs.marketsRepository, market)
+		if err != nil {
+			cs.log.Log(WARN, "market %s closed but failed to remove it from the CLOB (retried by the cron): %v", marketId, err)
+		}
+
+		// Step 3:
+		// publish the market-closed event on **NATS**
+		err = publishMarketClosedEvent(cs.natsService, market, closedTxIds)
+		if err != nil {
+			cs.log.Log(ERROR, "failed to publish market closed event for market %s: %v", marketId, err)
+			continue
+		}
+		cs.log.Log(INFO, "Published market closed event to NATS subject '%s' for market %s", lib.NATS_MARKETS_CLOSED, marketId)
+	}
+}
+
+/*
+*
+Expire every good-till-time prediction intent whose expires_at has passed:
+the orders are removed from the CLOB first, then marked expired_at in the db.
+The CLOB already stops matching an order once its expires_at_ms passes, so a due order can not fill while it waits for this run.
+An intent that matched in between is no longer open and is left alone.
+*/
+func (cs *CronService) ExpireDuePredictionIntents() {
+	dueIntents, err := cs.predictionIntentsRepository.GetDuePredictionIntents()
+	if err != nil {
+		cs.log.Log(ERROR, "Failed to fetch prediction intents due to expire: %v", err)
+		return
+	}
+	if len(dueIntents) == 0 {
+		return
+	}
 
-	markets, err := cs.marketsRepository.GetAllUnresolvedMarkets()
+	// Step 1:
+	// remove the orders from the **CLOB** in one call
+	clobOrders := make([]*pb_clob.CancelOrderRequest, 0, len(dueIntents))
+	txIds := make([]uuid.UUID, 0, len(dueIntents))
+	for _, dueIntent := range dueIntents {
+		clobOrders = append(clobOrders, &pb_clob.CancelOrderRequest{
+			MarketId: dueIntent.MarketID.String(),
+			TxId:     dueIntent.TxID.String(),
+		})
+		txIds = append(txIds, dueIntent.TxID)
+	}
+	removedTxIds, err := lib.CancelOrdersOnClob(clobOrders)
+	if err != nil {
+		cs.log.Log(ERROR, "Failed to remove %d expired prediction intents from the CLOB: %v", len(clobOrders), err)
+		return // retried on the next run
+	}
+
+	// Step 2:
+	// mark them expired in the **database**
+	expiredTxIds, err := cs.predictionIntentsRepository.MarkPredictionIntentsAsExpired(txIds)
 	if err != nil {
-		cs.log.Log(ERROR, "Failed to fetch unresolved markets: %v", err)
+		cs.log.Log(ERROR, "Failed to mark %d prediction intents as expired: %v", len(txIds), err)
+		return
+	}
+
+	cs.log.Log(INFO, "Expired %d prediction intents (%d were on the CLOB)", len(expiredTxIds), len(removedTxIds))
+}
+
+/*
+*
+Remove the books of closed, resolved or voided markets that could not be removed from the CLOB at the time.
+A book that is no longer on the CLOB (e.g. the CLOB was restarted since) counts as removed.
+*/
+func (cs *CronService) RetryClobRemovals() {
+	markets, err := cs.marketsRepository.GetMarketsPendingClobRemoval()
+	if err != nil {
+		cs.log.Log(ERROR, "Failed to fetch markets pending removal from the CLOB: %v", err)
 		return
 	}
 
 	for _, market := range markets {
-		cs.log.Log(INFO, "verifying all orderIntents for market ID %s", market.MarketID)
+		marketId := market.MarketID.String()
+
+		err = lib.DeleteMarketOnClob(marketId)
+		if err != nil && status.Code(err) != codes.NotFound {
+			cs.log.Log(WARN, "market %s: failed again to remove it from the CLOB: %v", marketId, err)
+			continue
+		}
 
-		// retrieve all unique accountIds with live positions...
-		accountIds, err := cs.predictionIntentsRepository.GetAllAccountIdsForMarketId(market.MarketID)
+		err = cs.marketsRepository.ClearMarketClobRemovalFailed(market.MarketID)
 		if err != nil {
-			cs.log.Log(ERROR, "Failed to fetch account IDs for market ID %s: %v", market.MarketID, err)
+			cs.log.Log(ERROR, "market %s removed from the CLOB but failed to clear clob_removal_failed_at: %v", marketId, err)
 			continue
 		}
+		cs.log.Log(INFO, "Removed market %s from the CLOB (first attempt failed at %s)", marketId, market.ClobRemovalFailedAt.Time.Format(time.RFC3339))
+	}
+}
 
-		for _, accountIdStr := range accountIds {
-			cs.log.Log(INFO, "verifying orderIntents for account ID %s in market ID %s", accountIdStr, market.MarketID)
+// also used when a market is resolved or voided, or an outcome is proposed while it is still trading -
+// a failure is recorded on the market and retried by the cron (RetryClobRemovals)
+func removeMarketFromClob(marketsRepository *repositories.MarketsRepository, market *sqlc.Market) error {
+	err := lib.DeleteMarketOnClob(market.MarketID.String())
+	if err == nil {
+		return nil
+	}
+	if dbErr := marketsRepository.SetMarketClobRemovalFailed(market.MarketID); dbErr != nil {
+		return fmt.Errorf("%v (and failed to record it for a retry: %v)", err, dbErr)
+	}
+	return err
+}
 
-			// get the allowance for each accountId
-			net, err := hiero.LedgerIDFromString(strings.ToLower(market.Net))
-			if err != nil {
-				cs.log.Log(ERROR, "Failed to parse net %s for account ID %s: %v", market.Net, accountIdStr, err)
-				continue
-			}
+// also used when an outcome is proposed for a market that is still trading
+func publishMarketClosedEvent(natsService *NatsService, market *sqlc.Market, closedTxIds []uuid.UUID) error {
+	event := lib.MarketClosedEvent{
+		MarketId:    market.MarketID.String(),
+		Net:         market.Net,
+		ClosesAt:    market.ClosesAt.Format(time.RFC3339),
+		ClosedAt:    market.ClosedAt.Time.Format(time.RFC3339),
+		ClosedTxIds: []string{},
+	}
+	for _, txId := range closedTxIds {
+		event.ClosedTxIds = append(event.ClosedTxIds, txId.String())
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal market closed event: %v", err)
+	}
+	return natsService.Publish(lib.NATS_MARKETS_CLOSED, eventJSON)
+}
 
-			accountId, err := hiero.AccountIDFromString(accountIdStr)
-			if err != nil {
-				cs.log.Log(ERROR, "Failed to parse account ID %s: %v", accountIdStr, err)
-				continue
-			}
+/*
+*
+Ask the resolution source of every closed, unresolved market with an automatic source for an outcome
+and propose it - it is resolved on-chain once the dispute window has passed.
+Sources that can not decide yet are asked again on the next run.
+*/
+func (cs *CronService) ResolveDueMarkets() {
+	markets, err := cs.marketsRepository.GetMarketsDueForResolution()
+	if err != nil {
+		cs.log.Log(ERROR, "Failed to fetch markets due for resolution: %v", err)
+		return
+	}
 
-			smartContractId, err := hiero.ContractIDFromString(market.SmartContractID)
-			if err != nil {
-				cs.log.Log(ERROR, "Failed to parse smart contract ID %s for market ID %s: %v", market.SmartContractID, market.MarketID, err)
-				continue
-			}
+	for _, market := range markets {
+		marketId := market.MarketID.String()
 
-			usdcAddressStr := os.Getenv(fmt.Sprintf("%s_USDC_ADDRESS", strings.ToUpper(market.Net)))
-			usdcDecimalsStr := os.Getenv("USDC_DECIMALS")
+		source, err := NewResolutionSource(market.ResolutionSource, market.ResolutionConfig)
+		if err != nil {
+			cs.log.Log(ERROR, "market %s: invalid resolution source: %v", marketId, err)
+			continue
+		}
 
-			if usdcAddressStr == "" || usdcDecimalsStr == "" {
-				cs.log.Log(ERROR, "USDC_ADDRESS or USDC_DECIMALS environment variable is not set")
-				continue
-			}
-			usdcDecimals, err := strconv.ParseUint(usdcDecimalsStr, 10, 64)
-			if err != nil {
-				cs.log.Log(ERROR, "invalid USDC_DECIMALS: %v", err)
-				continue
-			}
-			usdcAddress, err := hiero.ContractIDFromString(usdcAddressStr)
-			if err != nil {
-				cs.log.Log(ERROR, "invalid USDC address: %v", err)
-				continue
-			}
+		outcome, err := source.ProposeOutcome(context.Background(), &market)
+		if err != nil {
+			cs.log.Log(WARN, "market %s: %s resolution source failed: %v", marketId, market.ResolutionSource, err)
+			continue
+		}
+		if outcome == nil {
+			continue // undecided - ask again next run
+		}
+		cs.log.Log(INFO, "market %s: %s resolution source proposed outcome=%t", marketId, market.ResolutionSource, *outcome)
 
-			allowance, err := cs.hederaService.GetSpenderAllowanceUsd(*net, accountId, smartContractId, usdcAddress, usdcDecimals)
-			if err != nil {
-				cs.log.Log(ERROR, "Failed to fetch allowance for account ID %s: %v", accountIdStr, err)
-				continue
-			}
-			cs.log.Log(INFO, "-> Account ID %s has allowance %f", accountIdStr, allowance)
+		_, err = cs.resolutionsService.ProposeResolution(marketId, *outcome, "source:"+market.ResolutionSource)
+		if err != nil {
+			cs.log.Log(ERROR, "market %s: failed to propose the outcome: %v", marketId, err)
+			continue
+		}
+	}
+}
+
+/*
+*
+Evict the open prediction intents an account can no longer fund - checked account-wide (all markets and networks)
+by the RiskService, the same check intents pass at intake. The newest intents are evicted first.
+*/
+func (cs *CronService) KickOutOrderIntentsNotBackedByFunds() {
+	cs.log.Log(INFO, "KickOutOrderIntentsNotBackedByFunds: Starting process to kick out order intents not backed by funds...")
 
-			usdcBalance, err := cs.hederaService.GetUsdcBalanceUsd(*net, accountId)
+	accountIds, err := cs.predictionIntentsRepository.GetAllAccountIdsWithOpenPredictionIntents()
+	if err != nil {
+		cs.log.Log(ERROR, "Failed to fetch account IDs with open prediction intents: %v", err)
+		return
+	}
+
+	for _, accountId := range accountIds {
+		cs.log.Log(INFO, "verifying orderIntents for account ID %s", accountId)
+
+		overCommitted, err := cs.riskService.GetOverCommittedPredictionIntents(accountId)
+		if err != nil {
+			cs.log.Log(ERROR, "Failed to check the open prediction intents of account ID %s: %v", accountId, err)
+			continue
+		}
+
+		for _, pi := range overCommitted {
+			// cancel this prediction intent
+			_, err := cs.predictionIntentsService.CancelPredictionIntentBySystem(pi.MarketID.String(), pi.TxID.String())
 			if err != nil {
-				cs.log.Log(ERROR, "Failed to fetch USDC balance for account ID %s: %v", accountIdStr, err)
+				cs.log.Log(ERROR, "Failed to cancel prediction intent txId %s for market ID %s and account ID %s: %v", pi.TxID.String(), pi.MarketID, accountId, err)
 				continue
 			}
-			cs.log.Log(INFO, "-> Account ID %s has USDC balance %f", accountIdStr, usdcBalance)
+			cs.log.Log(WARN, "-> Cancelled prediction intent txId %s for market ID %s and account ID %s due to insufficient funds", pi.TxID.String(), pi.MarketID, accountId)
 
-			// retrieve all live orderIntents for this market, for this specific accountId
-			sumTotalOfAllPredictionIntents := 0.0
-			usersOpenPredictionIntents, err := cs.predictionIntentsRepository.GetAllOpenPredictionIntentsByMarketIdAndAccountId(market.MarketID, accountIdStr)
+			// and mark predictionIntent as evicted:
+			err = cs.predictionIntentsRepository.MarkPredictionIntentAsEvicted(pi.TxID)
 			if err != nil {
-				cs.log.Log(ERROR, " Failed to fetch live prediction intents for market ID %s and account ID %s: %v", market.MarketID, accountIdStr, err)
+				cs.log.Log(ERROR, "Failed to mark as evicted prediction intent txId %s for market ID %s and account ID %s: %v", pi.TxID.String(), pi.MarketID, accountId, err)
 				continue
 			}
-
-			var txIds []string
-			for _, pi := range usersOpenPredictionIntents {
-				txIds = append(txIds, pi.TxID.String())
-			}
-			cs.log.Log(INFO, "usersOpenPredictionIntents (%d): %v", len(usersOpenPredictionIntents), txIds)
-
-			for _, pi := range usersOpenPredictionIntents {
-				cs.log.Log(INFO, "processing txId=%s", pi.TxID.String())
-				sumTotalOfAllPredictionIntents += (math.Abs(pi.PriceUsd) * pi.Qty)
-				cs.log.Log(INFO, "sumTotalOfAllPredictionIntent: %f", sumTotalOfAllPredictionIntents)
-
-				if sumTotalOfAllPredictionIntents > usdcBalance {
-					// cancel this prediction intent
-					_, err := cs.predictionIntentsService.CancelPredictionIntent(market.MarketID.String(), pi.TxID.String())
-					// err = cs.predictionIntentsRepository.CancelPredictionIntent(pi.TxID.String())
-					if err != nil {
-						cs.log.Log(ERROR, "Failed to cancel prediction intent txId %s for market ID %s and account ID %s: %v", pi.TxID.String(), market.MarketID, accountIdStr, err)
-						continue
-					}
-					cs.log.Log(WARN, "-> Cancelled prediction intent txId %s for market ID %s and account ID %s due to insufficient funds (total required: %f, allowance: %f, balance: %f)", pi.TxID.String(), market.MarketID, accountIdStr, sumTotalOfAllPredictionIntents, allowance, usdcBalance)
-
-					// and mark predictionIntent as evicted:
-					err = cs.predictionIntentsRepository.MarkPredictionIntentAsEvicted(pi.TxID)
-					if err != nil {
-						cs.log.Log(ERROR, "Failed to mark as evicted prediction intent txId %s for market ID %s and account ID %s: %v", pi.TxID.String(), market.MarketID, accountIdStr, err)
-						continue
-					}
-					cs.log.Log(WARN, "-> Marked as evicted prediction intent txId %s for market ID %s and account ID %s", pi.TxID.String(), market.MarketID, accountIdStr)
-				}
-			}
+			cs.log.Log(WARN, "-> Marked as evicted prediction intent txId %s for market ID %s and account ID %s", pi.TxID.String(), pi.MarketID, accountId)
 		}
 	}
 }
@@ -153,6 +153,33 @@ func (hs *HederaService) GetPublicKey(accountId hiero.AccountID, net string) (*h
 	return publicKey, keyType, nil
 }
 
+// the account's EVM address as reported by the mirror node - lowercase, without the 0x prefix
+func (hs *HederaService) GetEvmAddress(accountId hiero.AccountID, net string) (string, error) {
+	mirrorNodeURL := fmt.Sprintf("https://%s.mirrornode.hedera.com/api/v1/accounts/%s", net, accountId)
+	resp, err := lib.Fetch(lib.GET, mirrorNodeURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to query mirror node: %v", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("mirror node returned status code %d", resp.StatusCode)
+	}
+
+	defer resp.Body.Close()
+
+	var jsonParseResult struct {
+		EvmAddress string `json:"evm_address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jsonParseResult); err != nil {
+		return "", fmt.Errorf("failed to parse mirror node response: %v", err)
+	}
+	if jsonParseResult.EvmAddress == "" {
+		return "", fmt.Errorf("mirror node returned no evm_address for account %s", accountId)
+	}
+
+	return strings.ToLower(strings.TrimPrefix(jsonParseResult.EvmAddress, "0x")), nil
+}
+
 func (hs *HederaService) GetSpenderAllowanceUsd(networkSelected hiero.LedgerID, accountId hiero.AccountID, smartContractId hiero.ContractID, usdcAddress hiero.ContractID, usdcDecimals uint64) (float64, error) {
 	mirrorNodeURL := fmt.Sprintf("https://%s.mirrornode.hedera.com/api/v1/accounts/%s/allowances/tokens?spender.id=eq:%s&token.id=eq:%s", networkSelected.String(), accountId.String(), smartContractId.String(), usdcAddress.String())
 	// log.Printf("https://%s.mirrornode.hedera.com/api/v1/accounts/%s/allowances/tokens?spender.id=eq:%s&token.id=eq:%s", networkSelected.String(), accountId.String(), smartContractId.String(), usdcAddress.String())
@@ -597,3 +624,235 @@ func (hs *HederaService) CreateNewMarket(marketId string, statement string, net
 
 	return remainingAllowance.Uint64(), nil
 }
+
+/*
+*
+The operator's remaining USDC allowance to the smart contract, in collateral token units - what createNewMarket returns.
+Read from the mirror node when that return value is lost (e.g. a market creation resumed after it succeeded on-chain).
+*/
+func (hs *HederaService) GetMarketCreationAllowance(net string, smartContractId string) (uint64, error) {
+	netUpper := strings.ToUpper(net)
+	operatorId, err := hiero.AccountIDFromString(os.Getenv(fmt.Sprintf("%s_HEDERA_OPERATOR_ID", netUpper)))
+	if err != nil {
+		return 0, hs.log.Log(ERROR, "invalid %s_HEDERA_OPERATOR_ID: %v", netUpper, err)
+	}
+	contractId, err := hiero.ContractIDFromString(smartContractId)
+	if err != nil {
+		return 0, hs.log.Log(ERROR, "invalid smart contract ID: %v", err)
+	}
+	usdcAddress, err := hiero.ContractIDFromString(os.Getenv(fmt.Sprintf("%s_USDC_ADDRESS", netUpper)))
+	if err != nil {
+		return 0, hs.log.Log(ERROR, "failed to validate %s_USDC_ADDRESS: %v", netUpper, err)
+	}
+
+	mirrorNodeURL := fmt.Sprintf("https://%s.mirrornode.hedera.com/api/v1/accounts/%s/allowances/tokens?spender.id=eq:%s&token.id=eq:%s", strings.ToLower(net), operatorId.String(), contractId.String(), usdcAddress.String())
+	resp, err := lib.Fetch(lib.GET, mirrorNodeURL, nil)
+	if err != nil {
+		return 0, hs.log.Log(ERROR, "error fetching allowance: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return 0, hs.log.Log(ERROR, "network response was not ok: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Allowances []struct {
+			Amount int64 `json:"amount"`
+		} `json:"allowances"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, hs.log.Log(ERROR, "failed to parse response: %v", err)
+	}
+
+	if len(result.Allowances) == 0 || result.Allowances[0].Amount < 0 {
+		return 0, nil
+	}
+	return uint64(result.Allowances[0].Amount), nil
+}
+
+/*
+*
+Check whether a market exists on the Prism smart contract - statements(uint128 marketId) is non-empty once createNewMarket succeeded
+
+* @param marketId - market ID (UUIDv7 string)
+* @param net - the network the market was created on
+* @param smartContractId - the smart contract ID the market was created on
+
+* @return bool - true if the market exists on the smart contract
+*/
+func (hs *HederaService) IsMarketOnChain(marketId string, net string, smartContractId string) (bool, error) {
+	marketIdBig, err := lib.Uuid7_to_bigint(marketId)
+	if err != nil {
+		return false, hs.log.Log(ERROR, "failed to convert marketId to bigint: %v", err)
+	}
+	params := hiero.NewContractFunctionParameters()
+	params.AddUint128BigInt(marketIdBig) // marketId
+
+	contractID, err := hiero.ContractIDFromString(smartContractId)
+	if err != nil {
+		return false, hs.log.Log(ERROR, "invalid smart contract ID: %v", err)
+	}
+
+	result, err := hiero.NewContractCallQuery().
+		SetContractID(contractID).
+		SetGas(100_000).
+		SetFunction("statements", params).
+		Execute(hs.hedera_clients[net])
+	if err != nil {
+		return false, hs.log.Log(ERROR, "failed to query statements(marketId=%s) on the smart contract: %v", marketId, err)
+	}
+
+	return result.GetString(0) != "", nil
+}
+
+/*
+*
+Read a market's resolution from the Prism smart contract - resolutionTimes(uint128 marketId), voided(...) and outcomes(...).
+resolveMarket and voidMarket both revert ("Already resolved") once resolutionTimes is set, so check this before sending either again
+
+* @param marketId - market ID (UUIDv7 string)
+* @param net - the network the market was created on
+* @param smartContractId - the smart contract ID stored against the market (NOT the current X_SMART_CONTRACT_ID)
+
+* @return uint64 - block timestamp of the resolution (or void), 0 if the market is not resolved on-chain yet
+* @return bool - true if the market was voided
+* @return bool - the outcome (true => YES) of a resolved market
+*/
+func (hs *HederaService) GetMarketResolutionOnChain(marketId string, net string, smartContractId string) (uint64, bool, bool, error) {
+	marketIdBig, err := lib.Uuid7_to_bigint(marketId)
+	if err != nil {
+		return 0, false, false, hs.log.Log(ERROR, "failed to convert marketId to bigint: %v", err)
+	}
+
+	contractID, err := hiero.ContractIDFromString(smartContractId)
+	if err != nil {
+		return 0, false, false, hs.log.Log(ERROR, "invalid smart contract ID: %v", err)
+	}
+
+	query := func(function string) (*hiero.ContractFunctionResult, error) {
+		params := hiero.NewContractFunctionParameters()
+		params.AddUint128BigInt(marketIdBig) // marketId
+
+		result, err := hiero.NewContractCallQuery().
+			SetContractID(contractID).
+			SetGas(100_000).
+			SetFunction(function, params).
+			Execute(hs.hedera_clients[net])
+		if err != nil {
+			return nil, hs.log.Log(ERROR, "failed to query %s(marketId=%s) on the smart contract: %v", function, marketId, err)
+		}
+		return &result, nil
+	}
+
+	resolutionTime, err := query("resolutionTimes")
+	if err != nil {
+		return 0, false, false, err
+	}
+	resolvedAt := resolutionTime.GetUint64(0)
+	if resolvedAt == 0 {
+		return 0, false, false, nil
+	}
+
+	voided, err := query("voided")
+	if err != nil {
+		return 0, false, false, err
+	}
+	outcome, err := query("outcomes")
+	if err != nil {
+		return 0, false, false, err
+	}
+
+	return resolvedAt, voided.GetBool(0), outcome.GetBool(0), nil
+}
+
+/*
+*
+Resolve a market on the Prism smart contract - resolveMarket(uint128 marketId, bool noYes)
+
+* @param marketId - market ID (UUIDv7 string)
+* @param net - the network the market was created on
+* @param smartContractId - the smart contract ID stored against the market (NOT the current X_SMART_CONTRACT_ID)
+* @param outcome - true => YES, false => NO
+
+* @return string - the Hedera transaction ID of the resolution
+*/
+func (hs *HederaService) ResolveMarket(marketId string, net string, smartContractId string, outcome bool) (string, error) {
+	marketIdBig, err := lib.Uuid7_to_bigint(marketId)
+	if err != nil {
+		return "", hs.log.Log(ERROR, "failed to convert marketId to bigint: %v", err)
+	}
+	params := hiero.NewContractFunctionParameters()
+	params.AddUint128BigInt(marketIdBig) // marketId
+	params.AddBool(outcome)              // noYes
+
+	// NO - do not use the current X_SMART_CONTRACT_ID - use the one that is stored in the markets table
+	contractID, err := hiero.ContractIDFromString(smartContractId)
+	if err != nil {
+		return "", hs.log.Log(ERROR, "invalid smart contract ID: %v", err)
+	}
+
+	hs.log.Log(INFO, "Resolving market %s on Prism smart contract (%s) with outcome=%t", marketId, contractID, outcome)
+	result, err := hiero.NewContractExecuteTransaction().
+		SetContractID(contractID).
+		SetGas(2_000_000). // TODO - can this be lowered?
+		SetFunction("resolveMarket", params).
+		Execute(hs.hedera_clients[net])
+	if err != nil {
+		return "", hs.log.Log(ERROR, "failed to execute contract: %v", err)
+	}
+
+	receipt, err := result.GetReceipt(hs.hedera_clients[net])
+	if err != nil {
+		return "", hs.log.Log(ERROR, "ResolveMarket - tx failed (could not get transaction receipt). Hedera txId = %s. %v", result.TransactionID.String(), err)
+	}
+
+	hs.log.Log(INFO, "resolveMarket(marketId=%s, ...) status: %s. Hedera txId = %s", marketId, receipt.Status.String(), result.TransactionID.String())
+
+	return result.TransactionID.String(), nil
+}
+
+/*
+*
+Void a market on the Prism smart contract - voidMarket(uint128 marketId)
+Holders then redeem their YES and NO tokens 50/50 via redeem()
+
+* @param marketId - market ID (UUIDv7 string)
+* @param net - the network the market was created on
+* @param smartContractId - the smart contract ID stored against the market (NOT the current X_SMART_CONTRACT_ID)
+
+* @return string - the Hedera transaction ID of the void
+*/
+func (hs *HederaService) VoidMarket(marketId string, net string, smartContractId string) (string, error) {
+	marketIdBig, err := lib.Uuid7_to_bigint(marketId)
+	if err != nil {
+		return "", hs.log.Log(ERROR, "failed to convert marketId to bigint: %v", err)
+	}
+	params := hiero.NewContractFunctionParameters()
+	params.AddUint128BigInt(marketIdBig) // marketId
+
+	// NO - do not use the current X_SMART_CONTRACT_ID - use the one that is stored in the markets table
+	contractID, err := hiero.ContractIDFromString(smartContractId)
+	if err != nil {
+		return "", hs.log.Log(ERROR, "invalid smart contract ID: %v", err)
+	}
+
+	hs.log.Log(INFO, "Voiding market %s on Prism smart contract (%s)", marketId, contractID)
+	result, err := hiero.NewContractExecuteTransaction().
+		SetContractID(contractID).
+		SetGas(2_000_000). // TODO - can this be lowered?
+		SetFunction("voidMarket", params).
+		Execute(hs.hedera_clients[net])
+	if err != nil {
+		return "", hs.log.Log(ERROR, "failed to execute contract: %v", err)
+	}
+
+	receipt, err := result.GetReceipt(hs.hedera_clients[net])
+	if err != nil {
+		return "", hs.log.Log(ERROR, "VoidMarket - tx failed (could not get transaction receipt). Hedera txId = %s. %v", result.TransactionID.String(), err)
+	}
+
+	hs.log.Log(INFO, "voidMarket(marketId=%s) status: %s. Hedera txId = %s", marketId, receipt.Status.String(), result.TransactionID.String())
+
+	return result.TransactionID.String(), nil
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	repositories "api/server/repositories"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MarketImagesService struct {
+	log                          *LogService
+	marketImageUploadsRepository *repositories.MarketImageUploadsRepository
+	blobStore                    lib.BlobStore
+}
+
+func (mis *MarketImagesService) Init(log *LogService, marketImageUploadsRepository *repositories.MarketImageUploadsRepository, blobStore lib.BlobStore) error {
+	mis.log = log
+	mis.marketImageUploadsRepository = marketImageUploadsRepository
+	mis.blobStore = blobStore
+
+	mis.log.Log(INFO, "Service: MarketImages service initialized successfully")
+	return nil
+}
+
+// process and store an image sent in one piece (CreateMarketv2's img_chunk) - returns its URL
+func (mis *MarketImagesService) SaveMarketImage(data []byte) (string, error) {
+	imageUrl, err := lib.SaveMarketImage(context.Background(), mis.blobStore, data)
+	if err != nil {
+		return "", mis.log.Log(ERROR, "failed to save market image: %v", err)
+	}
+	return imageUrl, nil
+}
+
+/*
+*
+Receive the chunks of one upload from a client stream. Each chunk is stored as soon as it arrives,
+so a stream that breaks off can be resumed with a new stream from the upload's received_bytes (see GetMarketImageUpload).
+Once the last byte is in, the whole image is checked against the client's sha256, processed and stored.
+An upload belongs to the account that started it - only that account can send it more chunks.
+*/
+func (mis *MarketImagesService) UploadMarketImage(stream pb_api.ApiServicePublic_UploadMarketImageServer, accountId string) (*pb_api.MarketImageUpload, error) {
+	var upload *sqlc.MarketImageUpload
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// N.B. the chunks received so far are kept - the client resumes from received_bytes
+			return nil, mis.log.Log(ERROR, "failed to receive market image chunk: %v", err)
+		}
+
+		if err := chunk.ValidateAll(); err != nil { // PGV validation
+			return nil, err
+		}
+		if upload != nil && !strings.EqualFold(chunk.UploadId, upload.UploadID.String()) {
+			return nil, mis.log.Log(ERROR, "one upload per stream: got uploadId=%s after uploadId=%s", chunk.UploadId, upload.UploadID.String())
+		}
+
+		upload, err = mis.receiveChunk(chunk, accountId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// guards
+	if upload == nil {
+		return nil, mis.log.Log(ERROR, "no market image chunks received")
+	}
+
+	/////
+	// OK
+	/////
+	return mapMarketImageUpload(upload), nil
+}
+
+// one chunk per call, for clients that can not stream (gRPC-web in the browser)
+func (mis *MarketImagesService) UploadMarketImageChunk(chunk *pb_api.MarketImageChunk, accountId string) (*pb_api.MarketImageUpload, error) {
+	upload, err := mis.receiveChunk(chunk, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapMarketImageUpload(upload), nil
+}
+
+func (mis *MarketImagesService) GetMarketImageUpload(uploadId string, accountId string) (*pb_api.MarketImageUpload, error) {
+	upload, err := mis.marketImageUploadsRepository.GetMarketImageUpload(uploadId)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "failed to get market image upload (uploadId=%s): %v", uploadId, err)
+	}
+	if upload == nil || upload.AccountID != accountId {
+		return nil, mis.log.Log(ERROR, "market image upload not found (uploadId=%s)", uploadId)
+	}
+
+	return mapMarketImageUpload(upload), nil
+}
+
+// the URL of a complete, unexpired upload - what CreateMarketv2 stores against the market
+func (mis *MarketImagesService) GetUploadedImageUrl(uploadId string) (string, error) {
+	upload, err := mis.marketImageUploadsRepository.GetMarketImageUpload(uploadId)
+	if err != nil {
+		return "", mis.log.Log(ERROR, "failed to get market image upload (uploadId=%s): %v", uploadId, err)
+	}
+	if upload == nil {
+		return "", mis.log.Log(ERROR, "market image upload not found (uploadId=%s)", uploadId)
+	}
+	if upload.Status != lib.MARKET_IMAGE_UPLOAD_COMPLETE || !upload.ImageUrl.Valid {
+		return "", mis.log.Log(ERROR, "market image upload is %s, not %s (uploadId=%s)", upload.Status, lib.MARKET_IMAGE_UPLOAD_COMPLETE, uploadId)
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return "", mis.log.Log(ERROR, "market image upload expired at %s (uploadId=%s)", upload.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"), uploadId)
+	}
+
+	return upload.ImageUrl.String, nil
+}
+
+// called by the cron - abandoned uploads (and their chunks) are dropped after MARKET_IMAGE_UPLOAD_EXPIRY_HOURS
+func (mis *MarketImagesService) DeleteExpiredUploads() {
+	nDeleted, err := mis.marketImageUploadsRepository.DeleteExpiredMarketImageUploads()
+	if err != nil {
+		mis.log.Log(ERROR, "Failed to delete expired market image uploads: %v", err)
+		return
+	}
+	if nDeleted > 0 {
+		mis.log.Log(INFO, "Deleted %d expired market image uploads", nDeleted)
+	}
+}
+
+func (mis *MarketImagesService) receiveChunk(chunk *pb_api.MarketImageChunk, accountId string) (*sqlc.MarketImageUpload, error) {
+	uploadUUID, err := uuid.Parse(chunk.UploadId)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "invalid uploadId %s: %v", chunk.UploadId, err)
+	}
+
+	// Step 1:
+	// find the upload - the first chunk of an unknown upload_id starts it
+	upload, err := mis.marketImageUploadsRepository.GetMarketImageUpload(chunk.UploadId)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "failed to get market image upload (uploadId=%s): %v", chunk.UploadId, err)
+	}
+	if upload == nil {
+		if chunk.Offset != 0 {
+			return nil, mis.log.Log(ERROR, "market image upload not found (uploadId=%s) - a new upload starts at offset 0", chunk.UploadId)
+		}
+		if chunk.TotalSize <= 0 {
+			return nil, mis.log.Log(ERROR, "totalSize is required on the first chunk (uploadId=%s)", chunk.UploadId)
+		}
+
+		if err := mis.checkUploadQuota(accountId, chunk.TotalSize); err != nil {
+			return nil, err
+		}
+
+		expiresAt := time.Now().Add(lib.MARKET_IMAGE_UPLOAD_EXPIRY_HOURS * time.Hour)
+		upload, err = mis.marketImageUploadsRepository.CreateMarketImageUpload(uploadUUID, chunk.TotalSize, expiresAt, accountId)
+		if err != nil {
+			return nil, mis.log.Log(ERROR, "failed to create market image upload (uploadId=%s): %v", chunk.UploadId, err)
+		}
+		if upload == nil {
+			return nil, mis.log.Log(ERROR, "market image upload was started concurrently (uploadId=%s) - resume it instead", chunk.UploadId)
+		}
+	}
+
+	// guards
+	if upload.AccountID != accountId {
+		return nil, mis.log.Log(ERROR, "market image upload belongs to another account (uploadId=%s)", chunk.UploadId)
+	}
+	if upload.Status != lib.MARKET_IMAGE_UPLOAD_UPLOADING {
+		return nil, mis.log.Log(ERROR, "market image upload is already %s (uploadId=%s)", upload.Status, chunk.UploadId)
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, mis.log.Log(ERROR, "market image upload expired at %s (uploadId=%s) - start a new upload", upload.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"), chunk.UploadId)
+	}
+	if chunk.TotalSize != 0 && chunk.TotalSize != upload.TotalSize {
+		return nil, mis.log.Log(ERROR, "totalSize=%d does not match the declared %d (uploadId=%s)", chunk.TotalSize, upload.TotalSize, chunk.UploadId)
+	}
+	if chunk.Offset != upload.ReceivedBytes {
+		return nil, mis.log.Log(ERROR, "unexpected offset %d - resume from offset %d (uploadId=%s)", chunk.Offset, upload.ReceivedBytes, chunk.UploadId)
+	}
+	nextOffset := chunk.Offset + int64(len(chunk.Data))
+	if nextOffset > upload.TotalSize {
+		return nil, mis.log.Log(ERROR, "chunk at offset %d (%d bytes) runs past totalSize=%d (uploadId=%s)", chunk.Offset, len(chunk.Data), upload.TotalSize, chunk.UploadId)
+	}
+	isFinal := nextOffset == upload.TotalSize
+	if isFinal && chunk.Sha256 == "" {
+		return nil, mis.log.Log(ERROR, "sha256 is required on the final chunk (uploadId=%s)", chunk.UploadId)
+	}
+
+	// Step 2:
+	// store the chunk
+	upload, err = mis.marketImageUploadsRepository.AppendMarketImageUploadChunk(uploadUUID, chunk.Offset, chunk.Data)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "failed to store market image chunk (uploadId=%s, offset=%d): %v", chunk.UploadId, chunk.Offset, err)
+	}
+	if upload == nil {
+		return nil, mis.log.Log(ERROR, "market image upload moved on concurrently (uploadId=%s) - check GetMarketImageUpload and resume", chunk.UploadId)
+	}
+	if !isFinal {
+		return upload, nil
+	}
+
+	// Step 3:
+	// last byte is in - verify the checksum, then process and store the image
+	data, err := mis.marketImageUploadsRepository.GetMarketImageUploadData(uploadUUID)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "failed to read market image upload (uploadId=%s): %v", chunk.UploadId, err)
+	}
+	checksum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(checksum[:]), chunk.Sha256) {
+		return mis.failUpload(uploadUUID, "sha256 mismatch: the received bytes do not match the checksum")
+	}
+
+	imageUrl, err := lib.SaveMarketImage(context.Background(), mis.blobStore, data)
+	if err != nil {
+		return mis.failUpload(uploadUUID, err.Error())
+	}
+
+	completed, err := mis.marketImageUploadsRepository.CompleteMarketImageUpload(uploadUUID, hex.EncodeToString(checksum[:]), imageUrl)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "failed to complete market image upload (uploadId=%s): %v", chunk.UploadId, err)
+	}
+	if completed == nil {
+		return nil, mis.log.Log(ERROR, "market image upload was completed concurrently (uploadId=%s)", chunk.UploadId)
+	}
+
+	return completed, nil
+}
+
+// uploads are stored in the database until they expire - cap how many (and how many bytes) one account can start per expiry window
+func (mis *MarketImagesService) checkUploadQuota(accountId string, totalSize int64) error {
+	since := time.Now().Add(-lib.MARKET_IMAGE_UPLOAD_EXPIRY_HOURS * time.Hour)
+	nUploads, totalBytes, err := mis.marketImageUploadsRepository.GetMarketImageUploadUsageByAccountId(accountId, since)
+	if err != nil {
+		return mis.log.Log(ERROR, "failed to get market image upload usage (accountId=%s): %v", accountId, err)
+	}
+	if nUploads >= lib.MARKET_IMAGE_UPLOADS_MAX_PER_ACCOUNT {
+		return mis.log.Log(ERROR, "account %s started %d market image uploads in the last %d hours - at most %d are allowed", accountId, nUploads, lib.MARKET_IMAGE_UPLOAD_EXPIRY_HOURS, lib.MARKET_IMAGE_UPLOADS_MAX_PER_ACCOUNT)
+	}
+	if totalBytes+totalSize > lib.MARKET_IMAGE_UPLOAD_MAX_BYTES_PER_ACCOUNT {
+		return mis.log.Log(ERROR, "account %s uploaded %d bytes of market images in the last %d hours - %d more would pass the limit of %d", accountId, totalBytes, lib.MARKET_IMAGE_UPLOAD_EXPIRY_HOURS, totalSize, lib.MARKET_IMAGE_UPLOAD_MAX_BYTES_PER_ACCOUNT)
+	}
+
+	return nil
+}
+
+// a failed upload can not be resumed - the client starts again with a new upload_id
+func (mis *MarketImagesService) failUpload(uploadId uuid.UUID, reason string) (*sqlc.MarketImageUpload, error) {
+	upload, err := mis.marketImageUploadsRepository.FailMarketImageUpload(uploadId, reason)
+	if err != nil {
+		return nil, mis.log.Log(ERROR, "failed to mark market image upload failed (uploadId=%s): %v", uploadId.String(), err)
+	}
+	if upload == nil {
+		return nil, mis.log.Log(ERROR, "market image upload is no longer uploading (uploadId=%s)", uploadId.String())
+	}
+
+	mis.log.Log(WARN, "Market image upload %s failed: %s", uploadId.String(), reason)
+	return upload, nil
+}
+
+func mapMarketImageUpload(upload *sqlc.MarketImageUpload) *pb_api.MarketImageUpload {
+	return &pb_api.MarketImageUpload{
+		UploadId:      upload.UploadID.String(),
+		TotalSize:     upload.TotalSize,
+		ReceivedBytes: upload.ReceivedBytes,
+		Status:        upload.Status,
+		ImageUrl:      upload.ImageUrl.String,
+		Error:         upload.LastError.String,
+		ExpiresAt:     upload.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	repositories "api/server/repositories"
+	"strings"
+	"time"
+
+	hiero "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+)
+
+type MarketProposalsService struct {
+	log                       *LogService
+	marketProposalsRepository *repositories.MarketProposalsRepository
+	marketsService            *MarketsService
+	hederaService             *HederaService
+}
+
+func (mps *MarketProposalsService) Init(log *LogService, marketProposalsRepository *repositories.MarketProposalsRepository, marketsService *MarketsService, hederaService *HederaService) error {
+	mps.log = log
+	mps.marketProposalsRepository = marketProposalsRepository
+	mps.marketsService = marketsService
+	mps.hederaService = hederaService
+
+	mps.log.Log(INFO, "Service: Market proposals service initialized successfully")
+	return nil
+}
+
+/*
+*
+Store a market draft signed by the proposer's Hedera key. Nothing goes on-chain until an ADMIN or MODERATOR approves it.
+*/
+func (mps *MarketProposalsService) ProposeMarket(req *pb_api.ProposeMarketRequest) (*pb_api.MarketProposal, error) {
+	// guards
+	// protobuf validation does a great job sofar ;)
+	net := strings.ToLower(req.Net)
+	if !lib.IsValidNetwork(net) {
+		return nil, mps.log.Log(ERROR, "invalid network: %s", req.Net)
+	}
+
+	var closesAt *time.Time
+	if req.GetClosesAt() != "" {
+		t, err := time.Parse(time.RFC3339, req.GetClosesAt())
+		if err != nil {
+			return nil, mps.log.Log(ERROR, "invalid closesAt time format (must be RFC3339): %v", err)
+		}
+		if !t.After(time.Now()) {
+			return nil, mps.log.Log(ERROR, "closesAt must be in the future")
+		}
+		closesAt = &t
+	}
+
+	existing, err := mps.marketProposalsRepository.GetMarketProposal(req.MarketId)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to get market proposal: %v", err)
+	}
+	if existing != nil {
+		return nil, mps.log.Log(ERROR, "marketId %s has already been proposed", req.MarketId)
+	}
+
+	// the public key sent must belong to the account on the selected network
+	accountId, err := hiero.AccountIDFromString(req.AccountId)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "invalid account ID: %s", req.AccountId)
+	}
+	publicKeyLookedUp, _, err := mps.hederaService.GetPublicKey(accountId, net)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to get public key: %v", err)
+	}
+	publicKey, err := hiero.PublicKeyFromString(req.PublicKey)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to parse public key from string: %v", err)
+	}
+	if publicKeyLookedUp.String() != publicKey.String() || publicKey.String() == "" {
+		return nil, mps.log.Log(ERROR, "public key mismatch: expected %s, got %s", publicKeyLookedUp.String(), publicKey.String())
+	}
+
+	// now verify the signature over the draft
+	isValidSig, err := lib.VerifySig(&publicKey, lib.Utf82hex(lib.AssembleMarketProposalPayload(req)), req.Sig)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to verify signature: %v", err)
+	}
+	if !isValidSig {
+		return nil, mps.log.Log(ERROR, "invalid signature for account %s", req.AccountId)
+	}
+
+	/////
+	// OK
+	/////
+	proposal, err := mps.marketProposalsRepository.CreateMarketProposal(req.MarketId, net, req.Statement, req.Description, req.ImageUrl, closesAt, req.CategoryIds, req.AccountId, req.PublicKey, req.KeyType, req.Sig)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to create market proposal: %v", err)
+	}
+
+	mps.log.Log(INFO, "Market proposal %s created by account %s", proposal.ProposalID.String(), proposal.AccountID)
+	return mps.mapMarketProposalToResponse(proposal), nil
+}
+
+/*
+*
+ADMIN/MODERATOR: list proposals by status (oldest first)
+*/
+func (mps *MarketProposalsService) GetMarketProposals(status string, limit int32, offset int32) (*pb_api.MarketProposalsResponse, error) {
+	proposals, err := mps.marketProposalsRepository.GetMarketProposalsByStatus(status, limit, offset)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to get market proposals: %v", err)
+	}
+
+	var proposalResponses []*pb_api.MarketProposal
+	for _, proposal := range proposals {
+		proposalResponses = append(proposalResponses, mps.mapMarketProposalToResponse(&proposal))
+	}
+
+	return &pb_api.MarketProposalsResponse{
+		MarketProposals: proposalResponses,
+	}, nil
+}
+
+/*
+*
+ADMIN/MODERATOR: approve a pending proposal and run the usual market creation path with the proposal id as the market id
+(so the market is linked to its proposal by id).
+If creation fails part way, the proposal stays approved without a market - approving it again retries the creation
+from its last completed step.
+*/
+func (mps *MarketProposalsService) ApproveMarketProposal(proposalId string, reviewedBy string, reason string) (*pb_api.CreateMarketResponse, error) {
+	// guards
+	proposal, err := mps.marketProposalsRepository.GetMarketProposal(proposalId)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to get market proposal: %v", err)
+	}
+	if proposal == nil {
+		return nil, mps.log.Log(ERROR, "market proposal %s not found", proposalId)
+	}
+	isRetry := false
+	if proposal.Status == lib.MARKET_PROPOSAL_APPROVED {
+		exists, err := mps.marketsService.marketsRepository.MarketExists(proposal.ProposalID)
+		if err != nil {
+			return nil, mps.log.Log(ERROR, "failed to check for the market of proposal %s: %v", proposalId, err)
+		}
+		if exists {
+			return nil, mps.log.Log(ERROR, "market proposal %s is already approved and its market created", proposalId)
+		}
+		isRetry = true
+	} else if proposal.Status != lib.MARKET_PROPOSAL_PENDING {
+		return nil, mps.log.Log(ERROR, "market proposal %s is already %s", proposalId, proposal.Status)
+	}
+	if proposal.ClosesAt.Valid && !proposal.ClosesAt.Time.After(time.Now()) {
+		return nil, mps.log.Log(ERROR, "market proposal %s closes in the past - reject it instead", proposalId)
+	}
+
+	/////
+	// OK
+	/////
+	// Step 1: claim the review so a second reviewer can not act on the same proposal (a retry was claimed by the first approval)
+	if !isRetry {
+		proposal, err = mps.marketProposalsRepository.ReviewMarketProposal(proposalId, lib.MARKET_PROPOSAL_APPROVED, reviewedBy, reason)
+		if err != nil {
+			return nil, mps.log.Log(ERROR, "failed to approve market proposal: %v", err)
+		}
+		if proposal == nil {
+			return nil, mps.log.Log(ERROR, "market proposal %s is no longer pending", proposalId)
+		}
+	}
+
+	// Step 2: create the market (contract -> CLOB -> db) - resumes a creation that failed part way
+	// (two reviewers retrying at once are serialised by the creation's claim)
+	var closesAt *string
+	if proposal.ClosesAt.Valid {
+		s := proposal.ClosesAt.Time.UTC().Format(time.RFC3339)
+		closesAt = &s
+	}
+	result, err := mps.marketsService.CreateMarket(&pb_api.CreateMarketRequest{
+		MarketId:    proposal.ProposalID.String(),
+		Net:         proposal.Net,
+		Statement:   proposal.Statement,
+		Description: proposal.Description,
+		ImageUrl:    proposal.ImageUrl,
+		ClosesAt:    closesAt,
+		CategoryIds: proposal.CategoryIds,
+	})
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "market proposal %s approved but market creation failed (approve it again to retry): %v", proposalId, err)
+	}
+
+	mps.log.Log(INFO, "Market proposal %s approved by %s", proposalId, reviewedBy)
+	return result, nil
+}
+
+/*
+*
+ADMIN/MODERATOR: reject a pending proposal - the reason is returned to the proposer
+*/
+func (mps *MarketProposalsService) RejectMarketProposal(proposalId string, reviewedBy string, reason string) (*pb_api.MarketProposal, error) {
+	// guards
+	if strings.TrimSpace(reason) == "" {
+		return nil, mps.log.Log(ERROR, "a reason is required to reject a market proposal")
+	}
+
+	// OK
+	proposal, err := mps.marketProposalsRepository.ReviewMarketProposal(proposalId, lib.MARKET_PROPOSAL_REJECTED, reviewedBy, reason)
+	if err != nil {
+		return nil, mps.log.Log(ERROR, "failed to reject market proposal: %v", err)
+	}
+	if proposal == nil {
+		return nil, mps.log.Log(ERROR, "market proposal %s not found or no longer pending", proposalId)
+	}
+
+	mps.log.Log(INFO, "Market proposal %s rejected by %s", proposalId, reviewedBy)
+	return mps.mapMarketProposalToResponse(proposal), nil
+}
+
+func (mps *MarketProposalsService) mapMarketProposalToResponse(proposal *sqlc.MarketProposal) *pb_api.MarketProposal {
+	response := &pb_api.MarketProposal{
+		ProposalId:   proposal.ProposalID.String(),
+		Net:          proposal.Net,
+		Statement:    proposal.Statement,
+		Description:  proposal.Description,
+		ImageUrl:     proposal.ImageUrl,
+		CategoryIds:  proposal.CategoryIds,
+		AccountId:    proposal.AccountID,
+		Status:       proposal.Status,
+		ReviewedBy:   proposal.ReviewedBy.String,
+		ReviewReason: proposal.ReviewReason.String,
+		CreatedAt:    proposal.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if proposal.ClosesAt.Valid {
+		response.ClosesAt = proposal.ClosesAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	if proposal.ReviewedAt.Valid {
+		response.ReviewedAt = proposal.ReviewedAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	return response
+}
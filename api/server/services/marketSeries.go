@@ -0,0 +1,453 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	repositories "api/server/repositories"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// same format as CRON_STR (cron.WithSeconds)
+var seriesCadenceParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type MarketSeriesService struct {
+	log                    *LogService
+	marketSeriesRepository *repositories.MarketSeriesRepository
+	marketsRepository      *repositories.MarketsRepository
+	marketsService         *MarketsService
+}
+
+func (mss *MarketSeriesService) Init(log *LogService, marketSeriesRepository *repositories.MarketSeriesRepository, marketsRepository *repositories.MarketsRepository, marketsService *MarketsService) error {
+	mss.log = log
+	mss.marketSeriesRepository = marketSeriesRepository
+	mss.marketsRepository = marketsRepository
+	mss.marketsService = marketsService
+
+	mss.log.Log(INFO, "Service: MarketSeries service initialized successfully")
+	return nil
+}
+
+/*
+*
+ADMIN: create a market template - the statement, description and resolution config are Go text/templates
+rendered with .Params (from the series' parameter generator) and .ClosesAt (a time.Time)
+*/
+func (mss *MarketSeriesService) CreateMarketTemplate(req *pb_api.CreateMarketTemplateRequest, accountId string) (*pb_api.MarketTemplate, error) {
+	// guards
+	for _, tmpl := range []string{req.StatementTemplate, req.DescriptionTemplate, req.ResolutionConfigTemplate} {
+		if _, err := parseSeriesTemplate(tmpl); err != nil {
+			return nil, mss.log.Log(ERROR, "%v", err)
+		}
+	}
+
+	// OK
+	resolutionConfigTemplate := req.ResolutionConfigTemplate
+	if strings.TrimSpace(resolutionConfigTemplate) == "" {
+		resolutionConfigTemplate = "{}"
+	}
+	template, err := mss.marketSeriesRepository.CreateMarketTemplate(req.Name, req.StatementTemplate, req.DescriptionTemplate, req.ImageUrl, req.CategoryIds, req.ResolutionSource, resolutionConfigTemplate, accountId)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "failed to create market template: %v", err)
+	}
+
+	return mapMarketTemplate(template), nil
+}
+
+/*
+*
+ADMIN: create a series of recurring markets from a template.
+The next market is rendered as a dry run first, so a bad cadence, template or generator config fails here rather than in the cron.
+*/
+func (mss *MarketSeriesService) CreateMarketSeries(req *pb_api.CreateMarketSeriesRequest, accountId string) (*pb_api.MarketSeries, error) {
+	// guards
+	template, err := mss.marketSeriesRepository.GetMarketTemplate(req.TemplateId)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "failed to get market template %d: %v", req.TemplateId, err)
+	}
+	if template == nil {
+		return nil, mss.log.Log(ERROR, "market template %d not found", req.TemplateId)
+	}
+
+	schedule, err := seriesCadenceParser.Parse(req.Cadence)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "invalid cadence %q: %v", req.Cadence, err)
+	}
+
+	parameterConfig := []byte(req.ParameterConfig)
+	if strings.TrimSpace(req.ParameterConfig) == "" {
+		parameterConfig = []byte("{}")
+	}
+	if !json.Valid(parameterConfig) {
+		return nil, mss.log.Log(ERROR, "parameter config is not valid JSON")
+	}
+	generator, err := NewParameterGenerator(req.ParameterGenerator, parameterConfig)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "%v", err)
+	}
+
+	if _, _, _, _, err := mss.renderOccurrence(template, generator, schedule.Next(time.Now())); err != nil {
+		return nil, mss.log.Log(ERROR, "dry run of the next market failed: %v", err)
+	}
+
+	// OK
+	series, err := mss.marketSeriesRepository.CreateMarketSeries(template.ID, req.Name, req.Net, req.Cadence, req.LeadTimeHours, req.ParameterGenerator, parameterConfig, accountId)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "failed to create market series: %v", err)
+	}
+
+	return mss.mapMarketSeries(series, nil)
+}
+
+/*
+*
+ADMIN: stop (or restart) generating markets - markets already generated are not affected
+*/
+func (mss *MarketSeriesService) SetMarketSeriesActive(seriesId int32, isActive bool) (*pb_api.MarketSeries, error) {
+	series, err := mss.marketSeriesRepository.SetMarketSeriesActive(seriesId, isActive)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "failed to set market series %d active=%t: %v", seriesId, isActive, err)
+	}
+	if series == nil {
+		return nil, mss.log.Log(ERROR, "market series %d not found", seriesId)
+	}
+
+	return mss.mapMarketSeries(series, nil)
+}
+
+// a series and the markets it generated (newest first) - the series history shown in the UI
+func (mss *MarketSeriesService) GetMarketSeries(seriesId int32, limit int32, offset int32) (*pb_api.MarketSeries, error) {
+	series, err := mss.marketSeriesRepository.GetMarketSeries(seriesId)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "failed to get market series %d: %v", seriesId, err)
+	}
+	if series == nil {
+		return nil, mss.log.Log(ERROR, "market series %d not found", seriesId)
+	}
+
+	occurrences, err := mss.marketSeriesRepository.GetMarketSeriesOccurrences(seriesId, limit, offset)
+	if err != nil {
+		return nil, mss.log.Log(ERROR, "failed to get occurrences of market series %d: %v", seriesId, err)
+	}
+
+	return mss.mapMarketSeries(series, occurrences)
+}
+
+/*
+*
+Called by the cron: create the markets of every active series that close within the series' lead time.
+Occurrences whose market creation failed on an earlier run are retried first.
+*/
+func (mss *MarketSeriesService) CreateDueMarkets() {
+	// Step 1:
+	// retry occurrences that were claimed but whose market was not (fully) created
+	failedSeriesIds := make(map[int32]bool) // keep their last_error
+	pending, err := mss.marketSeriesRepository.GetPendingMarketSeriesOccurrences(lib.MARKET_SERIES_PENDING_RETRY_LIMIT)
+	if err != nil {
+		mss.log.Log(ERROR, "Failed to fetch pending market series occurrences: %v", err)
+	} else {
+		for _, occurrence := range pending {
+			series, template, err := mss.getSeriesAndTemplate(occurrence.SeriesID)
+			if err != nil {
+				mss.log.Log(ERROR, "Failed to retry market %s of series %d: %v", occurrence.MarketID.String(), occurrence.SeriesID, err)
+				continue
+			}
+			if err := mss.createOccurrenceMarket(&occurrence, series, template); err != nil {
+				mss.recordSeriesError(series, err)
+				failedSeriesIds[series.ID] = true
+			}
+		}
+	}
+
+	// Step 2:
+	// claim and create the occurrences that are now within the lead time
+	seriesList, err := mss.marketSeriesRepository.GetActiveMarketSeries()
+	if err != nil {
+		mss.log.Log(ERROR, "Failed to fetch active market series: %v", err)
+		return
+	}
+
+	for _, series := range seriesList {
+		template, err := mss.marketSeriesRepository.GetMarketTemplate(series.TemplateID)
+		if err != nil {
+			mss.recordSeriesError(&series, err)
+			continue
+		}
+		if template == nil {
+			mss.recordSeriesError(&series, fmt.Errorf("market template %d not found", series.TemplateID))
+			continue
+		}
+
+		if err := mss.createSeriesMarkets(&series, template); err != nil {
+			mss.recordSeriesError(&series, err)
+			continue
+		}
+		if series.LastError.Valid && !failedSeriesIds[series.ID] {
+			if err := mss.marketSeriesRepository.SetMarketSeriesLastError(series.ID, ""); err != nil {
+				mss.log.Log(ERROR, "Failed to clear last error of market series %d: %v", series.ID, err)
+			}
+		}
+	}
+}
+
+func (mss *MarketSeriesService) createSeriesMarkets(series *sqlc.MarketSeries, template *sqlc.MarketTemplate) error {
+	schedule, err := seriesCadenceParser.Parse(series.Cadence)
+	if err != nil {
+		return fmt.Errorf("invalid cadence %q: %v", series.Cadence, err)
+	}
+	generator, err := NewParameterGenerator(series.ParameterGenerator, series.ParameterConfig)
+	if err != nil {
+		return err
+	}
+
+	// continue from the latest occurrence - but never generate markets that would already be closed
+	now := time.Now()
+	from := now
+	latest, err := mss.marketSeriesRepository.GetLatestMarketSeriesOccurrence(series.ID)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.ClosesAt.After(from) {
+		from = latest.ClosesAt
+	}
+	horizon := now.Add(time.Duration(series.LeadTimeHours) * time.Hour)
+
+	for i := 0; i < lib.MARKET_SERIES_MAX_MARKETS_PER_RUN; i++ {
+		closesAt := schedule.Next(from)
+		if closesAt.IsZero() || closesAt.After(horizon) {
+			return nil
+		}
+
+		params, statement, description, resolutionConfig, err := mss.renderOccurrence(template, generator, closesAt)
+		if err != nil {
+			return fmt.Errorf("failed to render the market closing %s: %v", closesAt.UTC().Format(time.RFC3339), err)
+		}
+
+		marketId, err := uuid.NewV7() // market ids are UUIDv7
+		if err != nil {
+			return fmt.Errorf("failed to generate marketId: %v", err)
+		}
+
+		occurrence, err := mss.marketSeriesRepository.CreateMarketSeriesOccurrence(series.ID, closesAt, marketId, params, statement, description, resolutionConfig)
+		if err != nil {
+			return err
+		}
+		if occurrence != nil { // nil => claimed by a concurrent run
+			if err := mss.createOccurrenceMarket(occurrence, series, template); err != nil {
+				return err
+			}
+		}
+
+		from = closesAt
+	}
+	return nil
+}
+
+/*
+*
+Create the market of a claimed occurrence through the normal creation path (idempotent per marketId),
+then set its resolution source and link it back to the series.
+*/
+func (mss *MarketSeriesService) createOccurrenceMarket(occurrence *sqlc.MarketSeriesOccurrence, series *sqlc.MarketSeries, template *sqlc.MarketTemplate) error {
+	marketId := occurrence.MarketID.String()
+
+	// Step 1: contract -> CLOB -> db
+	closesAt := occurrence.ClosesAt.UTC().Format(time.RFC3339)
+	_, err := mss.marketsService.CreateMarket(&pb_api.CreateMarketRequest{
+		MarketId:    marketId,
+		Net:         series.Net,
+		Statement:   occurrence.Statement,
+		Description: occurrence.Description,
+		ImageUrl:    template.ImageUrl,
+		ClosesAt:    &closesAt,
+		CategoryIds: template.CategoryIds,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create market %s (retried on the next run): %v", marketId, err)
+	}
+
+	// Step 2: resolution source
+	if template.ResolutionSource != lib.RESOLUTION_SOURCE_MANUAL {
+		_, err = mss.marketsService.SetMarketResolutionSource(marketId, template.ResolutionSource, string(occurrence.ResolutionConfig))
+		if err != nil {
+			return fmt.Errorf("failed to set the resolution source of market %s: %v", marketId, err)
+		}
+	}
+
+	// Step 3: link back to the series
+	if _, err = mss.marketsRepository.SetMarketSeries(occurrence.MarketID, series.ID); err != nil {
+		return fmt.Errorf("failed to link market %s to series %d: %v", marketId, series.ID, err)
+	}
+	if err = mss.marketSeriesRepository.CompleteMarketSeriesOccurrence(occurrence.SeriesID, occurrence.ClosesAt); err != nil {
+		return err
+	}
+
+	mss.log.Log(INFO, "Created market %s of series %d (%s) closing %s", marketId, series.ID, series.Name, closesAt)
+	return nil
+}
+
+// generate the parameters and render the statement, description and resolution config of the market closing at closesAt
+func (mss *MarketSeriesService) renderOccurrence(template *sqlc.MarketTemplate, generator ParameterGenerator, closesAt time.Time) (map[string]interface{}, string, string, []byte, error) {
+	params, err := generator.GenerateParams(context.Background(), closesAt)
+	if err != nil {
+		return nil, "", "", nil, fmt.Errorf("failed to generate params: %v", err)
+	}
+
+	data := seriesTemplateData{Params: params, ClosesAt: closesAt.UTC()}
+	statement, err := renderSeriesTemplate(template.StatementTemplate, data)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	if n := utf8.RuneCountInString(statement); n < 5 || n > 500 { // same limits as CreateMarketRequest
+		return nil, "", "", nil, fmt.Errorf("rendered statement must be 5-500 characters, got %d", n)
+	}
+	description, err := renderSeriesTemplate(template.DescriptionTemplate, data)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	resolutionConfig, err := renderSeriesTemplate(template.ResolutionConfigTemplate, data)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	if !json.Valid([]byte(resolutionConfig)) {
+		return nil, "", "", nil, fmt.Errorf("rendered resolution config is not valid JSON: %s", resolutionConfig)
+	}
+	if _, err := NewResolutionSource(template.ResolutionSource, []byte(resolutionConfig)); err != nil {
+		return nil, "", "", nil, err
+	}
+
+	return params, statement, description, []byte(resolutionConfig), nil
+}
+
+func (mss *MarketSeriesService) getSeriesAndTemplate(seriesId int32) (*sqlc.MarketSeries, *sqlc.MarketTemplate, error) {
+	series, err := mss.marketSeriesRepository.GetMarketSeries(seriesId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if series == nil {
+		return nil, nil, fmt.Errorf("market series %d not found", seriesId)
+	}
+	template, err := mss.marketSeriesRepository.GetMarketTemplate(series.TemplateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if template == nil {
+		return nil, nil, fmt.Errorf("market template %d not found", series.TemplateID)
+	}
+	return series, template, nil
+}
+
+// logged, and kept against the series so an ADMIN can see why it stopped generating markets
+func (mss *MarketSeriesService) recordSeriesError(series *sqlc.MarketSeries, err error) {
+	mss.log.Log(ERROR, "Market series %d (%s): %v", series.ID, series.Name, err)
+	if errSet := mss.marketSeriesRepository.SetMarketSeriesLastError(series.ID, err.Error()); errSet != nil {
+		mss.log.Log(ERROR, "Failed to record last error of market series %d: %v", series.ID, errSet)
+	}
+}
+
+/////
+// templates
+/////
+
+// what a template can reference: {{.Params.threshold}}, {{.ClosesAt.Format "Mon Jan 2"}}
+type seriesTemplateData struct {
+	Params   map[string]interface{}
+	ClosesAt time.Time
+}
+
+func parseSeriesTemplate(text string) (*texttemplate.Template, error) {
+	tmpl, err := texttemplate.New("series").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %q: %v", text, err)
+	}
+	return tmpl, nil
+}
+
+func renderSeriesTemplate(text string, data seriesTemplateData) (string, error) {
+	tmpl, err := parseSeriesTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %v", text, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+/////
+// mappers
+/////
+
+func mapMarketTemplate(template *sqlc.MarketTemplate) *pb_api.MarketTemplate {
+	return &pb_api.MarketTemplate{
+		Id:                       template.ID,
+		Name:                     template.Name,
+		StatementTemplate:        template.StatementTemplate,
+		DescriptionTemplate:      template.DescriptionTemplate,
+		ImageUrl:                 template.ImageUrl,
+		CategoryIds:              template.CategoryIds,
+		ResolutionSource:         template.ResolutionSource,
+		ResolutionConfigTemplate: template.ResolutionConfigTemplate,
+		CreatedBy:                template.CreatedBy,
+		CreatedAt:                template.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func (mss *MarketSeriesService) mapMarketSeries(series *sqlc.MarketSeries, occurrences []sqlc.GetMarketSeriesOccurrencesRow) (*pb_api.MarketSeries, error) {
+	var nextClosesAt string
+	if series.IsActive {
+		schedule, err := seriesCadenceParser.Parse(series.Cadence)
+		if err != nil {
+			return nil, mss.log.Log(ERROR, "invalid cadence %q of market series %d: %v", series.Cadence, series.ID, err)
+		}
+		from := time.Now()
+		latest, err := mss.marketSeriesRepository.GetLatestMarketSeriesOccurrence(series.ID)
+		if err != nil {
+			return nil, mss.log.Log(ERROR, "failed to get latest occurrence of market series %d: %v", series.ID, err)
+		}
+		if latest != nil && latest.ClosesAt.After(from) {
+			from = latest.ClosesAt
+		}
+		nextClosesAt = schedule.Next(from).UTC().Format("2006-01-02T15:04:05Z")
+	}
+
+	result := &pb_api.MarketSeries{
+		Id:                 series.ID,
+		TemplateId:         series.TemplateID,
+		Name:               series.Name,
+		Net:                series.Net,
+		Cadence:            series.Cadence,
+		LeadTimeHours:      series.LeadTimeHours,
+		ParameterGenerator: series.ParameterGenerator,
+		IsActive:           series.IsActive,
+		LastError:          series.LastError.String,
+		NextClosesAt:       nextClosesAt,
+		CreatedAt:          series.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for _, occurrence := range occurrences {
+		o := &pb_api.MarketSeriesOccurrence{
+			MarketId:     occurrence.MarketID.String(),
+			Statement:    occurrence.Statement,
+			ClosesAt:     occurrence.ClosesAt.UTC().Format("2006-01-02T15:04:05Z"),
+			MarketStatus: occurrence.MarketStatus,
+		}
+		if occurrence.Outcome.Valid {
+			outcome := occurrence.Outcome.Bool
+			o.Outcome = &outcome
+		}
+		result.Occurrences = append(result.Occurrences, o)
+	}
+	return result, nil
+}
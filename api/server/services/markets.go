@@ -5,29 +5,39 @@ import (
 	sqlc "api/gen/sqlc"
 	"api/server/lib"
 	repositories "api/server/repositories"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	hiero "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type MarketsService struct {
-	log               *LogService
-	marketsRepository *repositories.MarketsRepository
-	hederaService     *HederaService
-	priceService      *PriceService
-	priceRepository   *repositories.PriceRepository
+	log                       *LogService
+	marketsRepository         *repositories.MarketsRepository
+	marketCreationsRepository *repositories.MarketCreationsRepository
+	hederaService             *HederaService
+	priceService              *PriceService
+	priceRepository           *repositories.PriceRepository
+	marketImagesService       *MarketImagesService
+	tradingRulesService       *TradingRulesService
 }
 
-func (ms *MarketsService) Init(log *LogService, marketsRepository *repositories.MarketsRepository, hederaService *HederaService, priceService *PriceService) error {
+func (ms *MarketsService) Init(log *LogService, marketsRepository *repositories.MarketsRepository, marketCreationsRepository *repositories.MarketCreationsRepository, hederaService *HederaService, priceService *PriceService, marketImagesService *MarketImagesService, tradingRulesService *TradingRulesService) error {
 	ms.log = log
 	ms.marketsRepository = marketsRepository
+	ms.marketCreationsRepository = marketCreationsRepository
 	ms.hederaService = hederaService
 	ms.priceService = priceService
 	ms.priceRepository = priceService.priceRepository
+	ms.marketImagesService = marketImagesService
+	ms.tradingRulesService = tradingRulesService
 
 	ms.log.Log(INFO, "Service: Market service initialized successfully")
 	return nil
@@ -43,10 +53,16 @@ func (ms *MarketsService) GetMarketById(marketId string) (*pb_api.MarketResponse
 	if err != nil {
 		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
 	}
+
+	// so clients can round prices and quantities before signing
+	response.TradingRules, err = ms.tradingRulesService.GetTradingRules(market.Net, market.MarketID.String())
+	if err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
-func (ms *MarketsService) GetMarkets(limit int32, offset int32) (*pb_api.MarketsResponse, error) {
+func (ms *MarketsService) GetMarkets(limit int32, offset int32, categoryId *int32) (*pb_api.MarketsResponse, error) {
 	result := os.Getenv("DB_MAX_ROWS")
 	DB_MAX_ROWS, err := strconv.Atoi(result)
 	if err != nil {
@@ -57,18 +73,19 @@ func (ms *MarketsService) GetMarkets(limit int32, offset int32) (*pb_api.Markets
 		limit = int32(DB_MAX_ROWS)
 	}
 
-	markets, err := ms.marketsRepository.GetMarkets(limit, offset)
+	var markets []sqlc.Market
+	if categoryId != nil { // optional category filter
+		markets, err = ms.marketsRepository.GetMarketsByCategoryId(*categoryId, limit, offset)
+	} else {
+		markets, err = ms.marketsRepository.GetMarkets(limit, offset)
+	}
 	if err != nil {
 		return nil, ms.log.Log(ERROR, "failed to get markets: %v", err)
 	}
 
-	var marketResponses []*pb_api.MarketResponse
-	for _, market := range markets {
-		marketResponse, err := ms.mapMarketToMarketResponse(&market)
-		if err != nil {
-			return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
-		}
-		marketResponses = append(marketResponses, marketResponse)
+	marketResponses, err := ms.mapMarketsToMarketResponses(markets)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to map markets to market responses: %v", err)
 	}
 
 	response := &pb_api.MarketsResponse{
@@ -77,90 +94,512 @@ func (ms *MarketsService) GetMarkets(limit int32, offset int32) (*pb_api.Markets
 	return response, nil
 }
 
-// TODO - idempotent?
+/*
+*
+Full-text search over markets with optional filters. Pagination is keyset based: the returned nextCursor
+is an opaque token encoding the sort mode plus the sort key and marketId of the last row of the page.
+*/
+func (ms *MarketsService) SearchMarkets(req *pb_api.SearchMarketsRequest) (*pb_api.SearchMarketsResponse, error) {
+	// guards
+	result := os.Getenv("DB_MAX_ROWS")
+	DB_MAX_ROWS, err := strconv.Atoi(result)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "invalid DB_MAX_ROWS environment variable: %v", err)
+	}
+	limit := req.Limit
+	if limit > int32(DB_MAX_ROWS) {
+		ms.log.Log(WARN, "Warning: limit %d exceeds DB_MAX_ROWS %d, setting limit to DB_MAX_ROWS", limit, DB_MAX_ROWS)
+		limit = int32(DB_MAX_ROWS)
+	}
+
+	sortBy := "newest" // optional, default is newest first
+	if req.SortBy != nil {
+		sortBy = *req.SortBy
+	}
+
+	var query *string
+	if req.Query != nil && strings.TrimSpace(*req.Query) != "" {
+		trimmed := strings.TrimSpace(*req.Query)
+		query = &trimmed
+	}
+
+	var closesAfter, closesBefore *time.Time
+	if req.ClosesAfter != nil {
+		t, err := time.Parse(time.RFC3339, *req.ClosesAfter)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "invalid RFC3339 'closesAfter' timestamp: %v", err)
+		}
+		closesAfter = &t
+	}
+	if req.ClosesBefore != nil {
+		t, err := time.Parse(time.RFC3339, *req.ClosesBefore)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "invalid RFC3339 'closesBefore' timestamp: %v", err)
+		}
+		closesBefore = &t
+	}
+	if closesAfter != nil && closesBefore != nil && !closesAfter.Before(*closesBefore) {
+		return nil, ms.log.Log(ERROR, "'closesAfter' must be before 'closesBefore'")
+	}
+
+	var cursorSortKey *float64
+	var cursorMarketId string
+	if req.Cursor != nil && *req.Cursor != "" {
+		cursor, err := decodeMarketsCursor(*req.Cursor)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "invalid cursor: %v", err)
+		}
+		if cursor.SortBy != sortBy {
+			return nil, ms.log.Log(ERROR, "invalid cursor: cursor was issued for sortBy=%s, not %s", cursor.SortBy, sortBy)
+		}
+		cursorSortKey = &cursor.SortKey
+		cursorMarketId = cursor.MarketId
+	}
+
+	// OK
+	// fetch one extra row to find out whether there is a next page
+	rows, err := ms.marketsRepository.SearchMarkets(query, req.Net, req.Status, closesAfter, closesBefore, sortBy, cursorSortKey, cursorMarketId, limit+1)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to search markets: %v", err)
+	}
+
+	var nextCursor string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor, err = encodeMarketsCursor(marketsCursor{SortBy: sortBy, SortKey: last.SortKey, MarketId: last.Market.MarketID.String()})
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "failed to encode cursor: %v", err)
+		}
+	}
+
+	markets := make([]sqlc.Market, 0, len(rows))
+	for _, row := range rows {
+		markets = append(markets, row.Market)
+	}
+	marketResponses, err := ms.mapMarketsToMarketResponses(markets)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to map markets to market responses: %v", err)
+	}
+
+	return &pb_api.SearchMarketsResponse{
+		Markets:    marketResponses,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+/*
+*
+Create a new market. Creation is a persisted saga (market_creations), so retrying with the same marketId is idempotent
+and resumes from the last completed step.
+*/
 func (ms *MarketsService) CreateMarket(req *pb_api.CreateMarketRequest) (*pb_api.CreateMarketResponse, error) {
 	// guards
 	// protobuf validation does a great job sofar ;)
+	closesAt, err := parseClosesAt(req.ClosesAt)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "%v", err)
+	}
 
-	/////
-	// OK - 3 steps to create a new market
-	/////
+	// OK
+	creation, err := ms.marketCreationsRepository.StartMarketCreation(req.MarketId, req.Net, req.Statement, req.Description, req.ImageUrl, closesAt, req.CategoryIds)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to start market creation (marketId=%s): %v", req.MarketId, err)
+	}
+	if err := ms.assertSameMarketCreation(creation, req.Net, req.Statement); err != nil {
+		return nil, err
+	}
 
-	// Step 1:
-	// create a market on the **smart contract** - return with error if it fails
-	remainingAllowance, err := ms.hederaService.CreateNewMarket(req.MarketId, req.Statement, req.Net)
+	return ms.runMarketCreation(creation)
+}
+
+func (ms *MarketsService) CreateMarketv2(req *pb_api.CreateMarketv2Request) (*pb_api.CreateMarketResponse, error) {
+	// guards
+	// protobuf validation does a great job sofar ;)
+	closesAt, err := parseClosesAt(req.ClosesAt)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to create new market (marketId=%s) on Hedera: %v", req.MarketId, err)
+		return nil, ms.log.Log(ERROR, "%v", err)
+	}
+	if (len(req.ImgChunk) > 0) == (req.ImgUploadId != "") {
+		return nil, ms.log.Log(ERROR, "exactly one of imgChunk or imgUploadId is required (marketId=%s)", req.MarketId)
 	}
 
-	// Step 2:
-	// create market on the **CLOB**
-	err = lib.CreateMarketOnClob(req.MarketId)
+	// OK
+	// only process and store the image the first time round - a retry reuses the url stored against the creation
+	existing, err := ms.marketCreationsRepository.GetMarketCreation(req.MarketId)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to create new market (marketId=%s) on CLOB: %v", req.MarketId, err)
+		return nil, ms.log.Log(ERROR, "failed to get market creation (marketId=%s): %v", req.MarketId, err)
+	}
+	var imgUrl string
+	if existing != nil {
+		imgUrl = existing.ImageUrl
+	} else if req.ImgUploadId != "" {
+		// already processed and stored by UploadMarketImage
+		imgUrl, err = ms.marketImagesService.GetUploadedImageUrl(req.ImgUploadId)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// N.B. ImgFileName/ImgMimeType are informational only - the format is sniffed from the bytes
+		imgUrl, err = ms.marketImagesService.SaveMarketImage(req.ImgChunk)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Step 3:
-	// now record the tx on the **db**
-	contractID, err := hiero.ContractIDFromString(
-		// YES, use the current X_SMART_CONTRACT_ID loaded from env vars - we're creating a new market
-		os.Getenv(fmt.Sprintf("%s_SMART_CONTRACT_ID", strings.ToUpper(req.Net))),
-	)
-	market, err := ms.marketsRepository.CreateMarket(req.MarketId, req.Net, req.ImageUrl, req.Statement, *req.ClosesAt, req.Description, contractID.String())
+	creation, err := ms.marketCreationsRepository.StartMarketCreation(req.MarketId, req.Net, req.Statement, req.Description, imgUrl, closesAt, req.CategoryIds)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to create a new market row (marketId=%s) on the db: %v", req.MarketId, err)
+		return nil, ms.log.Log(ERROR, "failed to start market creation (marketId=%s): %v", req.MarketId, err)
+	}
+	if err := ms.assertSameMarketCreation(creation, req.Net, req.Statement); err != nil {
+		return nil, err
+	}
+
+	return ms.runMarketCreation(creation)
+}
+
+/*
+*
+ADMIN: list creations that are not completed and not currently being driven by anyone (at most lib.MARKET_CREATIONS_MAX_LIMIT per page)
+*/
+func (ms *MarketsService) GetStuckMarketCreations(limit int32, offset int32) (*pb_api.MarketCreationsResponse, error) {
+	if limit > lib.MARKET_CREATIONS_MAX_LIMIT {
+		limit = lib.MARKET_CREATIONS_MAX_LIMIT
+	}
+
+	creations, err := ms.marketCreationsRepository.GetStuckMarketCreations(limit, offset)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get stuck market creations: %v", err)
+	}
+
+	var marketCreations []*pb_api.MarketCreation
+	for _, creation := range creations {
+		marketCreations = append(marketCreations, &pb_api.MarketCreation{
+			MarketId:  creation.MarketID.String(),
+			Net:       creation.Net,
+			Statement: creation.Statement,
+			Step:      creation.Step,
+			Attempts:  creation.Attempts,
+			LastError: creation.LastError.String,
+			CreatedAt: creation.CreatedAt.UTC().Format(time.RFC3339),
+			UpdatedAt: creation.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return &pb_api.MarketCreationsResponse{
+		MarketCreations: marketCreations,
+	}, nil
+}
+
+/*
+*
+ADMIN: resume a stuck creation from its last completed step
+*/
+func (ms *MarketsService) ResumeMarketCreation(marketId string) (*pb_api.CreateMarketResponse, error) {
+	creation, err := ms.marketCreationsRepository.GetMarketCreation(marketId)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market creation (marketId=%s): %v", marketId, err)
+	}
+	if creation == nil {
+		return nil, ms.log.Log(ERROR, "no market creation found for marketId=%s", marketId)
+	}
+
+	return ms.runMarketCreation(creation)
+}
+
+func (ms *MarketsService) assertSameMarketCreation(creation *sqlc.MarketCreation, net string, statement string) error {
+	if creation.Net != strings.ToLower(net) || creation.Statement != strings.TrimSpace(statement) {
+		return ms.log.Log(ERROR, "marketId %s is already used by a different market", creation.MarketID.String())
+	}
+	return nil
+}
+
+/*
+*
+Drive the market creation saga to completion, skipping the steps that were already completed:
+pending -> contract_created -> clob_created -> completed
+*/
+func (ms *MarketsService) runMarketCreation(creation *sqlc.MarketCreation) (*pb_api.CreateMarketResponse, error) {
+	marketId := creation.MarketID.String()
+
+	if creation.Step != lib.MARKET_CREATION_COMPLETED {
+		// only one caller drives a creation at a time
+		claimed, err := ms.marketCreationsRepository.ClaimMarketCreation(creation.MarketID)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "failed to claim market creation (marketId=%s): %v", marketId, err)
+		}
+		if claimed == nil {
+			return nil, ms.log.Log(ERROR, "market creation (marketId=%s) is already in progress", marketId)
+		}
+		creation = claimed
+
+		/////
+		// OK - 3 steps to create a new market
+		/////
+
+		// Step 1:
+		// create a market on the **smart contract**
+		if creation.Step == lib.MARKET_CREATION_PENDING {
+			// YES, use the current X_SMART_CONTRACT_ID loaded from env vars - we're creating a new market
+			smartContractId := os.Getenv(fmt.Sprintf("%s_SMART_CONTRACT_ID", strings.ToUpper(creation.Net)))
+
+			remainingAllowance, err := ms.hederaService.CreateNewMarket(marketId, creation.Statement, creation.Net)
+			if err != nil {
+				// a previous attempt may have created the market on-chain without recording it
+				isOnChain, errOnChain := ms.hederaService.IsMarketOnChain(marketId, creation.Net, smartContractId)
+				if errOnChain != nil || !isOnChain {
+					return nil, ms.failMarketCreation(creation, "failed to create new market on Hedera: %v", err)
+				}
+				ms.log.Log(WARN, "market (marketId=%s) already exists on the smart contract - continuing", marketId)
+
+				// the allowance createNewMarket returned was lost with the failed attempt - read it instead
+				remainingAllowance, err = ms.hederaService.GetMarketCreationAllowance(creation.Net, smartContractId)
+				if err != nil {
+					ms.log.Log(WARN, "market (marketId=%s) - failed to read the remaining allowance: %v", marketId, err)
+				}
+			}
+
+			updated, err := ms.marketCreationsRepository.SetMarketCreationContractCreated(creation.MarketID, smartContractId, remainingAllowance)
+			if err != nil {
+				return nil, ms.failMarketCreation(creation, "created on Hedera but failed to record the step: %v", err)
+			}
+			creation = updated
+		}
+
+		// Step 2:
+		// create market on the **CLOB**
+		if creation.Step == lib.MARKET_CREATION_CONTRACT_CREATED {
+			err = lib.CreateMarketOnClob(marketId)
+			if err != nil && status.Code(err) != codes.AlreadyExists {
+				return nil, ms.failMarketCreation(creation, "failed to create new market on CLOB: %v", err)
+			}
+
+			updated, err := ms.marketCreationsRepository.SetMarketCreationClobCreated(creation.MarketID)
+			if err != nil {
+				return nil, ms.failMarketCreation(creation, "created on the CLOB but failed to record the step: %v", err)
+			}
+			creation = updated
+		}
+
+		// Step 3:
+		// now record the market on the **db**
+		if creation.Step == lib.MARKET_CREATION_CLOB_CREATED {
+			exists, err := ms.marketsRepository.MarketExists(creation.MarketID)
+			if err != nil {
+				return nil, ms.failMarketCreation(creation, "failed to check for an existing market row: %v", err)
+			}
+			if !exists {
+				_, err = ms.marketsRepository.CreateMarket(marketId, creation.Net, creation.ImageUrl, creation.Statement, creation.ClosesAt.UTC().Format(time.RFC3339), creation.Description, creation.SmartContractID.String, creation.CategoryIds)
+				if err != nil {
+					return nil, ms.failMarketCreation(creation, "failed to create a new market row on the db: %v", err)
+				}
+			}
+
+			updated, err := ms.marketCreationsRepository.SetMarketCreationCompleted(creation.MarketID)
+			if err != nil {
+				return nil, ms.failMarketCreation(creation, "created on the db but failed to record the step: %v", err)
+			}
+			creation = updated
+		}
 	}
 
 	/////
 	// Output: map the result to MarketResponse
 	/////
+	market, err := ms.marketsRepository.GetMarketById(marketId)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market by id: %v", err)
+	}
 	marketResponse, err := ms.mapMarketToMarketResponse(market)
 	if err != nil {
 		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
 	}
 	return &pb_api.CreateMarketResponse{
 		MarketResponse:     marketResponse,
-		RemainingAllowance: remainingAllowance,
+		RemainingAllowance: uint64(creation.RemainingAllowance),
 	}, nil
 }
 
-func (ms *MarketsService) CreateMarketv2(req *pb_api.CreateMarketv2Request) (*pb_api.CreateMarketResponse, error) {
+// record the error against the creation (and release it) so it can be resumed later
+func (ms *MarketsService) failMarketCreation(creation *sqlc.MarketCreation, format string, args ...interface{}) error {
+	err := ms.log.Log(ERROR, "market creation (marketId=%s, step=%s) "+format, append([]interface{}{creation.MarketID.String(), creation.Step}, args...)...)
+	if errFail := ms.marketCreationsRepository.SetMarketCreationFailed(creation.MarketID, err.Error()); errFail != nil {
+		ms.log.Log(ERROR, "failed to record market creation error (marketId=%s): %v", creation.MarketID.String(), errFail)
+	}
+	return err
+}
+
+// closesAt is optional - default: 30 days from now
+func parseClosesAt(closesAt *string) (time.Time, error) {
+	if closesAt == nil || *closesAt == "" {
+		return time.Now().Add(30 * 24 * time.Hour), nil
+	}
+	t, err := time.Parse(time.RFC3339, *closesAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid closesAt time format (must be RFC3339): %v", err)
+	}
+	return t, nil
+}
+
+/*
+*
+Resolve a market whose outcome has been decided (see ResolutionsService) - changedBy is recorded in market_status_history.
+Safe to call again (e.g. by the cron) if the db step failed after the market was resolved on-chain.
+*/
+func (ms *MarketsService) ResolveMarket(marketId string, outcome bool, changedBy string) (*pb_api.MarketResponse, error) {
 	// guards
-	// protobuf validation does a great job sofar ;)
+	market, err := ms.marketsRepository.GetMarketById(marketId)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market by id: %v", err)
+	}
+	if err := ms.guardMarketStatusTransition(market, lib.MARKET_STATUS_RESOLVED); err != nil {
+		return nil, err
+	}
+
+	/////
+	// OK - 3 steps to resolve a market
+	/////
+
+	// Step 1:
+	// resolve the market on the **smart contract** - return with error if it fails
+	// N.B. use the smart contract ID stored against the market, not the current X_SMART_CONTRACT_ID
+	// an earlier attempt may have resolved it on-chain and then failed on the db - resolveMarket would revert, so only the db step is retried
+	resolvedAt, isVoided, outcomeOnChain, err := ms.hederaService.GetMarketResolutionOnChain(marketId, market.Net, market.SmartContractID)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to read the on-chain resolution of market (marketId=%s): %v", marketId, err)
+	}
+	txHash := ""
+	switch {
+	case resolvedAt > 0 && isVoided:
+		return nil, ms.log.Log(ERROR, "market (marketId=%s) is already voided on-chain - it can not be resolved", marketId)
+	case resolvedAt > 0 && outcomeOnChain != outcome:
+		return nil, ms.log.Log(ERROR, "market (marketId=%s) is already resolved on-chain with outcome=%t, not %t", marketId, outcomeOnChain, outcome)
+	case resolvedAt > 0:
+		ms.log.Log(WARN, "market (marketId=%s) is already resolved on-chain (outcome=%t) - only updating the db", marketId, outcome)
+	default:
+		txHash, err = ms.hederaService.ResolveMarket(marketId, market.Net, market.SmartContractID, outcome)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "failed to resolve market (marketId=%s) on Hedera: %v", marketId, err)
+		}
+	}
+
+	// Step 2:
+	// record the outcome on the **db** and cancel all open prediction intents for the market
+	market, cancelledTxIds, err := ms.marketsRepository.ResolveMarket(marketId, outcome, changedBy)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "market (marketId=%s) resolved on-chain (Hedera txId = %s) but failed to update the db: %v", marketId, txHash, err)
+	}
+	ms.log.Log(INFO, "Resolved market %s (outcome=%t), cancelled %d open prediction intents", marketId, outcome, len(cancelledTxIds))
+
+	// Step 3:
+	// remove the market from the **CLOB** (closed markets were already removed by the cron job)
+	if !market.ClosedAt.Valid {
+		err = removeMarketFromClob(ms.marketsRepository, market)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "market (marketId=%s) resolved but failed to remove it from the CLOB (retried by the cron): %v", marketId, err)
+		}
+	}
 
 	/////
-	// OK - 3 steps to create a new market
+	// Output: map the result to MarketResponse
 	/////
+	marketResponse, err := ms.mapMarketToMarketResponse(market)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
+	}
+	return marketResponse, nil
+}
 
-	// save the image to S3
-	imgUrl, err := lib.SaveImageToS3(req.ImgChunk, req.ImgFileName, req.ImgMimeType)
+/*
+*
+Void (cancel) a market, e.g. when its statement is ambiguous: the market can no longer be resolved,
+open prediction intents are cancelled and YES/NO holders are refunded 50/50 by the smart contract.
+Safe to call again if the db step failed after the market was voided on-chain.
+*/
+func (ms *MarketsService) VoidMarket(marketId string, accountId string, reason string) (*pb_api.MarketResponse, error) {
+	// guards
+	market, err := ms.marketsRepository.GetMarketById(marketId)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to save image to S3: %v", err)
+		return nil, ms.log.Log(ERROR, "failed to get market by id: %v", err)
+	}
+	if err := ms.guardMarketStatusTransition(market, lib.MARKET_STATUS_VOIDED); err != nil {
+		return nil, err
 	}
 
+	/////
+	// OK - 3 steps to void a market
+	/////
+
 	// Step 1:
-	// create a market on the **smart contract** - return with error if it fails
-	remainingAllowance, err := ms.hederaService.CreateNewMarket(req.MarketId, req.Statement, req.Net)
+	// void the market on the **smart contract** (refund path) - return with error if it fails
+	// N.B. use the smart contract ID stored against the market, not the current X_SMART_CONTRACT_ID
+	// an earlier attempt may have voided it on-chain and then failed on the db - voidMarket would revert, so only the db step is retried
+	resolvedAt, isVoided, _, err := ms.hederaService.GetMarketResolutionOnChain(marketId, market.Net, market.SmartContractID)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to create new market (marketId=%s) on Hedera: %v", req.MarketId, err)
+		return nil, ms.log.Log(ERROR, "failed to read the on-chain resolution of market (marketId=%s): %v", marketId, err)
+	}
+	txHash := ""
+	switch {
+	case resolvedAt > 0 && !isVoided:
+		return nil, ms.log.Log(ERROR, "market (marketId=%s) is already resolved on-chain - it can not be voided", marketId)
+	case resolvedAt > 0:
+		ms.log.Log(WARN, "market (marketId=%s) is already voided on-chain - only updating the db", marketId)
+	default:
+		txHash, err = ms.hederaService.VoidMarket(marketId, market.Net, market.SmartContractID)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "failed to void market (marketId=%s) on Hedera: %v", marketId, err)
+		}
 	}
 
 	// Step 2:
-	// create market on the **CLOB**
-	err = lib.CreateMarketOnClob(req.MarketId)
+	// mark the market as voided on the **db**, cancel all open prediction intents and record the refunds
+	market, cancelledTxIds, refunds, err := ms.marketsRepository.VoidMarket(marketId, accountId, reason)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to create new market (marketId=%s) on CLOB: %v", req.MarketId, err)
+		return nil, ms.log.Log(ERROR, "market (marketId=%s) voided on-chain (Hedera txId = %s) but failed to update the db: %v", marketId, txHash, err)
 	}
+	ms.log.Log(INFO, "Voided market %s (reason: %s), cancelled %d open prediction intents, recorded %d refunds", marketId, reason, len(cancelledTxIds), len(refunds))
 
 	// Step 3:
-	// now record the tx on the **db**
-	contractID, err := hiero.ContractIDFromString(
-		// YES, use the current X_SMART_CONTRACT_ID loaded from env vars - we're creating a new market
-		os.Getenv(fmt.Sprintf("%s_SMART_CONTRACT_ID", strings.ToUpper(req.Net))),
-	)
-	market, err := ms.marketsRepository.CreateMarket(req.MarketId, req.Net, imgUrl, req.Statement, *req.ClosesAt, req.Description, contractID.String())
+	// remove the market from the **CLOB** (closed markets were already removed by the cron job)
+	if !market.ClosedAt.Valid {
+		err = removeMarketFromClob(ms.marketsRepository, market)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "market (marketId=%s) voided but failed to remove it from the CLOB (retried by the cron): %v", marketId, err)
+		}
+	}
+
+	/////
+	// Output: map the result to MarketResponse
+	/////
+	marketResponse, err := ms.mapMarketToMarketResponse(market)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
+	}
+	return marketResponse, nil
+}
+
+/*
+*
+ADMIN: set the resolution source (manual, http_json or price_threshold) and its JSON config for an unresolved market.
+The config is validated by building the source before it is stored.
+*/
+func (ms *MarketsService) SetMarketResolutionSource(marketId string, resolutionSource string, resolutionConfig string) (*pb_api.MarketResponse, error) {
+	// guards
+	config := []byte(resolutionConfig)
+	if strings.TrimSpace(resolutionConfig) == "" {
+		config = []byte("{}")
+	}
+	if !json.Valid(config) {
+		return nil, ms.log.Log(ERROR, "resolution config is not valid JSON")
+	}
+	if _, err := NewResolutionSource(resolutionSource, config); err != nil {
+		return nil, ms.log.Log(ERROR, "%v", err)
+	}
+
+	// OK
+	market, err := ms.marketsRepository.SetMarketResolutionSource(marketId, resolutionSource, config)
 	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to create a new market row (marketId=%s) on the db: %v", req.MarketId, err)
+		return nil, ms.log.Log(ERROR, "failed to set resolution source for market (marketId=%s): %v", marketId, err)
 	}
 
 	/////
@@ -170,23 +609,281 @@ func (ms *MarketsService) CreateMarketv2(req *pb_api.CreateMarketv2Request) (*pb
 	if err != nil {
 		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
 	}
-	return &pb_api.CreateMarketResponse{
-		MarketResponse:     marketResponse,
-		RemainingAllowance: remainingAllowance,
+	return marketResponse, nil
+}
+
+/*
+*
+Per-market statistics for the market cards: volume windows, open interest and unique traders from the db,
+the 24h price change from price_history and the best bid/ask from the CLOB. Suspended markets are left out.
+*/
+func (ms *MarketsService) GetMarketStats(_marketIds []string) (*pb_api.MarketStatsResponse, error) {
+	marketIds := make([]string, len(_marketIds))
+	for i, marketId := range _marketIds {
+		marketIds[i] = strings.ToLower(marketId) // keys of the CLOB prices map
+	}
+
+	rows, err := ms.marketsRepository.GetMarketStats(marketIds)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market stats: %v", err)
+	}
+
+	// the CLOB is best effort - the db stats are still returned if it is unavailable
+	prices, err := lib.GetPricesFromClob(marketIds)
+	if err != nil {
+		ms.log.Log(WARN, "failed to get best bid/ask from the CLOB: %v", err)
+	}
+
+	var marketStats []*pb_api.MarketStats
+	for _, row := range rows {
+		stats := &pb_api.MarketStats{
+			MarketId:           row.MarketID.String(),
+			Volume_1HUsd:       row.Volume1hUsd,
+			Volume_24HUsd:      row.Volume24hUsd,
+			Volume_7DUsd:       row.Volume7dUsd,
+			VolumeAllTimeUsd:   row.VolumeAllTimeUsd,
+			OpenInterest:       uint64(row.OpenInterest),
+			NTraders:           uint64(row.NTraders),
+			PriceUsd:           row.LatestPriceUsd,
+			PriceChange_24HUsd: row.LatestPriceUsd - row.Price24hAgoUsd,
+		}
+		// an empty side of the book has no quote (the CLOB reports 0.5 for it)
+		if price, ok := prices[stats.MarketId]; ok {
+			if price.HasBid {
+				stats.BestBidUsd = &price.PriceBidUsd
+			}
+			if price.HasAsk {
+				stats.BestAskUsd = &price.PriceAskUsd
+			}
+		}
+		marketStats = append(marketStats, stats)
+	}
+
+	return &pb_api.MarketStatsResponse{
+		MarketStats: marketStats,
+	}, nil
+}
+
+/*
+*
+ADMIN: edit the off-chain fields of a market (description, image, closesAt, categories).
+The statement is on-chain and can not be changed. Every change is recorded in market_revisions.
+*/
+func (ms *MarketsService) UpdateMarket(req *pb_api.UpdateMarketRequest, accountId string) (*pb_api.MarketResponse, error) {
+	// guards
+	if accountId == "" {
+		return nil, ms.log.Log(ERROR, "accountId is required to update a market")
+	}
+
+	var closesAt *time.Time
+	if req.ClosesAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.ClosesAt)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "invalid closesAt time format (must be RFC3339): %v", err)
+		}
+		if !t.After(time.Now()) {
+			return nil, ms.log.Log(ERROR, "closesAt must be in the future")
+		}
+		closesAt = &t
+	}
+
+	var categoryIds []int32 // nil => unchanged
+	if req.UpdateCategoryIds {
+		categoryIds = append([]int32{}, req.CategoryIds...)
+	}
+
+	// OK
+	market, revisions, err := ms.marketsRepository.UpdateMarket(req.MarketId, req.Description, req.ImageUrl, closesAt, categoryIds, accountId, req.Reason)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to update market (marketId=%s): %v", req.MarketId, err)
+	}
+	for _, revision := range revisions {
+		ms.log.Log(INFO, "Market %s: %s changed from %q to %q by %s (reason: %s)", req.MarketId, revision.Field, revision.OldValue, revision.NewValue, accountId, revision.Reason)
+	}
+
+	/////
+	// Output: map the result to MarketResponse
+	/////
+	marketResponse, err := ms.mapMarketToMarketResponse(market)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
+	}
+	return marketResponse, nil
+}
+
+func (ms *MarketsService) GetMarketRevisions(marketId string, limit int32, offset int32) (*pb_api.MarketRevisionsResponse, error) {
+	result := os.Getenv("DB_MAX_ROWS")
+	DB_MAX_ROWS, err := strconv.Atoi(result)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "invalid DB_MAX_ROWS environment variable: %v", err)
+	}
+	if limit > int32(DB_MAX_ROWS) {
+		limit = int32(DB_MAX_ROWS)
+	}
+
+	revisions, err := ms.marketsRepository.GetMarketRevisions(marketId, limit, offset)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market revisions (marketId=%s): %v", marketId, err)
+	}
+
+	var revisionResponses []*pb_api.MarketRevision
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, &pb_api.MarketRevision{
+			Id:        revision.ID,
+			MarketId:  revision.MarketID.String(),
+			Field:     revision.Field,
+			OldValue:  revision.OldValue,
+			NewValue:  revision.NewValue,
+			Reason:    revision.Reason,
+			CreatedAt: revision.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return &pb_api.MarketRevisionsResponse{
+		MarketRevisions: revisionResponses,
 	}, nil
 }
 
+/*
+*
+Pause, unpause, suspend or unsuspend a market
+- records who did it (and why) on the db
+- stops (paused or suspended) or restores (neither paused nor suspended) matching on the CLOB, while the market still trades (open or paused)
+*/
+func (ms *MarketsService) ModerateMarket(marketId string, action lib.MarketModerationActionType, accountId string, reason string) (*pb_api.MarketResponse, error) {
+	// guards
+	if accountId == "" {
+		return nil, ms.log.Log(ERROR, "accountId is required to %s a market", action)
+	}
+
+	// OK
+
+	// Step 1:
+	// update the market on the **db** (and the moderation log) - pause/unpause are status changes, checked there with the row locked
+	// (suspended markets can still be paused, so they are not looked up here)
+	market, err := ms.marketsRepository.ModerateMarket(marketId, action, accountId, reason)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to %s market (marketId=%s): %v", action, marketId, err)
+	}
+	ms.log.Log(INFO, "Market %s: %s by %s (reason: %s)", marketId, action, accountId, reason)
+
+	// Step 2:
+	// stop or restore matching on the **CLOB** - closed, resolving, resolved and voided markets no longer have a book there
+	if market.Status == lib.MARKET_STATUS_OPEN || market.Status == lib.MARKET_STATUS_PAUSED {
+		isHalted := market.Status == lib.MARKET_STATUS_PAUSED || market.IsSuspended
+		err = lib.PauseMarketOnClob(marketId, isHalted)
+		if err != nil {
+			return nil, ms.log.Log(ERROR, "market (marketId=%s) updated on the db but failed to set isPaused=%t on the CLOB: %v", marketId, isHalted, err)
+		}
+	}
+
+	/////
+	// Output: map the result to MarketResponse
+	/////
+	marketResponse, err := ms.mapMarketToMarketResponse(market)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to map market to market response: %v", err)
+	}
+	return marketResponse, nil
+}
+
+/*
+*
+Move a market to a new status, e.g. closed -> resolving once an outcome is proposed.
+Only legal transitions are allowed (see lib.IsLegalMarketStatusTransition) and every change is recorded in market_status_history.
+*/
+func (ms *MarketsService) SetMarketStatus(marketId string, toStatus string, changedBy string, reason string) (*sqlc.Market, error) {
+	// guards
+	market, err := ms.marketsRepository.GetMarketById(marketId)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market by id: %v", err)
+	}
+	if err := ms.guardMarketStatusTransition(market, toStatus); err != nil {
+		return nil, err
+	}
+
+	// OK
+	market, err = ms.marketsRepository.SetMarketStatus(market.MarketID, toStatus, changedBy, reason)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to set market status: %v", err)
+	}
+
+	ms.log.Log(INFO, "Market %s is now %s (by %s)", marketId, market.Status, changedBy)
+	return market, nil
+}
+
+func (ms *MarketsService) GetMarketStatusHistory(marketId string) (*pb_api.MarketStatusHistoryResponse, error) {
+	history, err := ms.marketsRepository.GetMarketStatusHistory(marketId)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get market status history: %v", err)
+	}
+
+	var changes []*pb_api.MarketStatusChange
+	for _, change := range history {
+		changes = append(changes, &pb_api.MarketStatusChange{
+			MarketId:   change.MarketID.String(),
+			FromStatus: change.FromStatus.String,
+			ToStatus:   change.ToStatus,
+			ChangedBy:  change.ChangedBy,
+			Reason:     change.Reason,
+			CreatedAt:  change.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return &pb_api.MarketStatusHistoryResponse{
+		MarketStatusChanges: changes,
+	}, nil
+}
+
+// checked again by the repository with the market row locked
+func (ms *MarketsService) guardMarketStatusTransition(market *sqlc.Market, toStatus string) error {
+	if !lib.IsLegalMarketStatusTransition(market.Status, toStatus) {
+		return ms.log.Log(ERROR, "market %s is %s and can not move to %s", market.MarketID.String(), market.Status, toStatus)
+	}
+	return nil
+}
+
 func (ms *MarketsService) mapMarketToMarketResponse(market *sqlc.Market) (*pb_api.MarketResponse, error) {
+	marketResponses, err := ms.mapMarketsToMarketResponses([]sqlc.Market{*market})
+	if err != nil {
+		return nil, err
+	}
+	return marketResponses[0], nil
+}
+
+// maps a page of markets with a single price query for the whole page
+func (ms *MarketsService) mapMarketsToMarketResponses(markets []sqlc.Market) ([]*pb_api.MarketResponse, error) {
+	marketIds := make([]uuid.UUID, len(markets))
+	for i, market := range markets {
+		marketIds[i] = market.MarketID
+	}
+
+	prices, err := ms.priceService.GetLatestPricesForMarkets(marketIds)
+	if err != nil {
+		return nil, ms.log.Log(ERROR, "failed to get latest prices for markets: %v", err)
+	}
+
+	var marketResponses []*pb_api.MarketResponse
+	for _, market := range markets {
+		marketResponse, err := ms.buildMarketResponse(&market, prices[market.MarketID])
+		if err != nil {
+			return nil, err
+		}
+		marketResponses = append(marketResponses, marketResponse)
+	}
+	return marketResponses, nil
+}
+
+func (ms *MarketsService) buildMarketResponse(market *sqlc.Market, priceUsd float32) (*pb_api.MarketResponse, error) {
 	var createdAt string
-	var resolvedAt string
+	var resolvedAt string // market may not yet be resolved
 	if !market.CreatedAt.Valid {
 		return nil, ms.log.Log(ERROR, "invalid market: createdAt is null")
 	}
-	if !market.ResolvedAt.Valid {
-		resolvedAt = "" // market may not yet be resolved
-	}
 	createdAt = market.CreatedAt.Time.Format("2006-01-02T15:04:05Z")
-	resolvedAt = market.ResolvedAt.Time.Format("2006-01-02T15:04:05Z")
+	if market.ResolvedAt.Valid {
+		resolvedAt = market.ResolvedAt.Time.Format("2006-01-02T15:04:05Z")
+	}
 
 	var imageUrl string
 	if market.ImageUrl.Valid {
@@ -195,22 +892,37 @@ func (ms *MarketsService) mapMarketToMarketResponse(market *sqlc.Market) (*pb_ap
 		imageUrl = ""
 	}
 
-	priceUsd, err := ms.priceService.GetLatestPriceByMarket(market.MarketID.String())
-	if err != nil {
-		return nil, ms.log.Log(ERROR, "failed to get latest price for market %s: %v", market.MarketID.String(), err)
+	var closedAt string
+	if market.ClosedAt.Valid {
+		closedAt = market.ClosedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	var voidedAt string
+	if market.VoidedAt.Valid {
+		voidedAt = market.VoidedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
 	}
 
 	marketResponse := &pb_api.MarketResponse{
-		MarketId:    market.MarketID.String(),
-		Net:         market.Net,
-		Statement:   market.Statement,
-		IsPaused:    market.IsPaused,
-		IsSuspended: market.IsSuspended,
-		CreatedAt:   createdAt,
-		ResolvedAt:  resolvedAt,
-		ImageUrl:    imageUrl,
-		PriceUsd:    priceUsd,
-		Description: market.Description,
+		MarketId:         market.MarketID.String(),
+		Net:              market.Net,
+		Statement:        market.Statement,
+		IsPaused:         market.Status == lib.MARKET_STATUS_PAUSED,
+		IsSuspended:      market.IsSuspended,
+		CreatedAt:        createdAt,
+		ResolvedAt:       resolvedAt,
+		ImageUrl:         imageUrl,
+		PriceUsd:         priceUsd,
+		Description:      market.Description,
+		ClosesAt:         market.ClosesAt.UTC().Format("2006-01-02T15:04:05Z"),
+		ClosedAt:         closedAt,
+		VoidedAt:         voidedAt,
+		ResolutionSource: market.ResolutionSource,
+		Status:           market.Status,
+	}
+	if market.Outcome.Valid {
+		marketResponse.Outcome = &market.Outcome.Bool
+	}
+	if market.SeriesID.Valid {
+		marketResponse.SeriesId = &market.SeriesID.Int32
 	}
 	return marketResponse, nil
 }
@@ -311,3 +1023,33 @@ func (ms *MarketsService) GetNumMarkets() uint32 {
 	}
 	return uint32(nMarkets)
 }
+
+// opaque SearchMarkets pagination cursor (base64url encoded JSON)
+type marketsCursor struct {
+	SortBy   string  `json:"s"`
+	SortKey  float64 `json:"k"`
+	MarketId string  `json:"m"`
+}
+
+func encodeMarketsCursor(cursor marketsCursor) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeMarketsCursor(token string) (*marketsCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cursor: %v", err)
+	}
+	var cursor marketsCursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, fmt.Errorf("failed to parse cursor: %v", err)
+	}
+	if cursor.MarketId == "" {
+		return nil, fmt.Errorf("cursor is missing marketId")
+	}
+	return &cursor, nil
+}
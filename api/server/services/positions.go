@@ -3,9 +3,12 @@ package services
 import (
 	pb_api "api/gen"
 	sqlc "api/gen/sqlc"
+	"api/server/lib"
 	repositories "api/server/repositories"
 	"context"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type PositionsService struct {
@@ -49,17 +52,56 @@ func (ps *PositionsService) GetUserPortfolio(req *pb_api.UserPortfolioRequest) (
 		OpenPredictionIntents: make(map[string]*pb_api.PredictionIntents),
 	}
 
+	// batch-load the markets, prices and refunds for all positions (one query each)
+	marketIds := make([]uuid.UUID, 0, len(userPositions))
 	for _, userPosition := range userPositions {
-		priceUsd, err := ps.priceService.GetLatestPriceByMarket(userPosition.MarketID.String())
-		if err != nil {
-			ps.log.Log(WARN, "skipping market %s: failed to get latest price: %v", userPosition.MarketID.String(), err)
+		marketIds = append(marketIds, userPosition.MarketID)
+	}
+	markets, err := ps.marketsRepository.GetMarketsByIds(marketIds)
+	if err != nil {
+		return nil, ps.log.Log(ERROR, "failed to get markets for user portfolio: %v", err)
+	}
+	marketsById := make(map[uuid.UUID]*sqlc.Market, len(markets))
+	for i := range markets {
+		marketsById[markets[i].MarketID] = &markets[i]
+	}
+	prices, err := ps.priceService.GetLatestPricesForMarkets(marketIds)
+	if err != nil {
+		return nil, ps.log.Log(ERROR, "failed to get latest prices for user portfolio: %v", err)
+	}
+	refunds, err := ps.marketsRepository.GetMarketRefundsByEvmAddress(req.EvmAddress)
+	if err != nil {
+		return nil, ps.log.Log(ERROR, "failed to get refunds for user portfolio: %v", err)
+	}
+	refundsByMarketId := make(map[uuid.UUID]uint64, len(refunds))
+	for _, refund := range refunds {
+		refundsByMarketId[refund.MarketID] = uint64(refund.RefundAmount)
+	}
+
+	for _, userPosition := range userPositions {
+		market, ok := marketsById[userPosition.MarketID]
+		if !ok {
+			ps.log.Log(WARN, "skipping market %s: market not found", userPosition.MarketID.String())
 			continue // skip to next userPosition
 		}
 
-		market, err := ps.marketsRepository.GetMarketById(userPosition.MarketID.String())
-		if err != nil {
-			ps.log.Log(WARN, "skipping market %s: failed to get market: %v", userPosition.MarketID.String(), err)
-			continue // skip to next userPosition
+		var resolvedAt string
+		if market.ResolvedAt.Valid {
+			resolvedAt = market.ResolvedAt.Time.UTC().Format(time.RFC3339)
+		}
+
+		// voided markets have no meaningful last price - report the refund instead
+		var priceUsd float32
+		var voidedAt string
+		var refundAmount uint64
+		if market.VoidedAt.Valid {
+			voidedAt = market.VoidedAt.Time.UTC().Format(time.RFC3339)
+			refundAmount, ok = refundsByMarketId[userPosition.MarketID]
+			if !ok {
+				ps.log.Log(WARN, "market %s is voided but no refund was recorded for %s", userPosition.MarketID.String(), userPosition.EvmAddress)
+			}
+		} else {
+			priceUsd = prices[userPosition.MarketID]
 		}
 
 		position := &pb_api.Position{
@@ -72,10 +114,13 @@ func (ps *PositionsService) GetUserPortfolio(req *pb_api.UserPortfolioRequest) (
 		}
 
 		elem := &pb_api.PositionInfo{
-			Position:   position,
-			PriceUsd:   priceUsd,
-			IsPaused:   market.IsPaused,
-			ResolvedAt: market.ResolvedAt.Time.String(),
+			Position:     position,
+			PriceUsd:     priceUsd,
+			IsPaused:     market.Status == lib.MARKET_STATUS_PAUSED,
+			ResolvedAt:   resolvedAt,
+			VoidedAt:     voidedAt,
+			RefundAmount: refundAmount,
+			Status:       market.Status,
 		}
 
 		response.Positions[userPosition.MarketID.String()] = elem
@@ -98,6 +143,8 @@ func (ps *PositionsService) GetUserPortfolio(req *pb_api.UserPortfolioRequest) (
 			MarketLimit: pi.MarketLimit,
 			PriceUsd:    pi.PriceUsd,
 			Qty:         pi.Qty,
+			ExpiresAt:   formatExpiresAt(pi.ExpiresAt),
+			TimeInForce: pi.TimeInForce,
 		}
 		if _, ok := response.OpenPredictionIntents[pi.MarketID.String()]; !ok {
 			response.OpenPredictionIntents[pi.MarketID.String()] = &pb_api.PredictionIntents{}
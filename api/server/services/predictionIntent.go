@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -28,17 +29,21 @@ type PredictionIntentsService struct {
 	marketsRepository           *repositories.MarketsRepository
 	predictionIntentsRepository *repositories.PredictionIntentsRepository
 
-	natsService   *NatsService
-	hederaService *HederaService
+	natsService         *NatsService
+	hederaService       *HederaService
+	riskService         *RiskService
+	tradingRulesService *TradingRulesService
 }
 
-func (pis *PredictionIntentsService) Init(logService *LogService, dbRepository *repositories.DbRepository, marketsRepository *repositories.MarketsRepository, natsService *NatsService, hederaService *HederaService, predictionIntentRepository *repositories.PredictionIntentsRepository) error {
+func (pis *PredictionIntentsService) Init(logService *LogService, dbRepository *repositories.DbRepository, marketsRepository *repositories.MarketsRepository, natsService *NatsService, hederaService *HederaService, riskService *RiskService, tradingRulesService *TradingRulesService, predictionIntentRepository *repositories.PredictionIntentsRepository) error {
 	pis.dbRepository = dbRepository
 	pis.marketsRepository = marketsRepository
 	pis.predictionIntentsRepository = predictionIntentRepository
 
 	pis.natsService = natsService
 	pis.hederaService = hederaService
+	pis.riskService = riskService
+	pis.tradingRulesService = tradingRulesService
 	pis.log = logService
 
 	pis.log.Log(INFO, "Service: PredictionIntents service initialized successfully, %p", pis)
@@ -46,40 +51,71 @@ func (pis *PredictionIntentsService) Init(logService *LogService, dbRepository *
 	return nil
 }
 
-func (pis *PredictionIntentsService) CreatePredictionIntent(req *pb_api.PredictionIntentRequest) (string, error) {
-	/////
-	// validations
-	/////
-	// Validate account ID format and minimum account number
-	accountId, err := hiero.AccountIDFromString(req.AccountId)
+func (pis *PredictionIntentsService) CreatePredictionIntent(req *pb_api.PredictionIntentRequest) (*pb_api.PredictionIntentResponse, error) {
+	timeInForce, err := pis.validatePredictionIntent(req, "")
 	if err != nil {
-		return "Invalid accountId format", err
+		return nil, err
 	}
 
-	// Validate timestamp is within the last TIMESTAMP_ALLOWED_PAST_SECONDS seconds
-	timestamp, err := time.Parse(time.RFC3339, req.GeneratedAt)
+	/// OK - All validations passed
+	/// Now you can (attempt to) put the order on the CLOB (subject to on-chain sig verification)
+
+	clobRequestObj := newClobOrderRequest(req, timeInForce)
+
+	if timeInForce != lib.TIME_IN_FORCE_GTC {
+		return pis.placeImmediateOrder(req, clobRequestObj)
+	}
+
+	/////
+	// notify the CLOB via NATS:
+	/////
+
+	// Marshal the CLOB req: *pb_api.PredictionIntentRequest to JSON
+	clobRequestJSON, err := json.Marshal(clobRequestObj)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "invalid timestamp format: %v", err)
+		return nil, pis.log.Log(ERROR, "failed to marshal CLOB request: %v", err)
 	}
 
-	now := time.Now().UTC()
-	allowedPastSeconds, err := strconv.Atoi(os.Getenv("TIMESTAMP_ALLOWED_PAST_SECONDS"))
+	// Publish the message to NATS:
+	err = pis.natsService.Publish(lib.SUBJECT_CLOB_ORDERS, clobRequestJSON)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "invalid TIMESTAMP_ALLOWED_PAST_SECONDS environment variable: %v", err)
+		return nil, pis.log.Log(ERROR, "failed to publish to NATS: %v", err)
 	}
-	allowedFutureSeconds, err := strconv.Atoi(os.Getenv("TIMESTAMP_ALLOWED_FUTURE_SECONDS"))
+
+	pis.log.Log(INFO, "Published order to NATS subject '%s': %s", lib.SUBJECT_CLOB_ORDERS, string(clobRequestJSON))
+
+	// now store the OrderRequest in the database - the txid must be unique or this fails
+	_, err = pis.predictionIntentsRepository.CreateOrderIntentRequest(req, timeInForce)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "invalid TIMESTAMP_ALLOWED_FUTURE_SECONDS environment variable: %v", err)
+		return nil, pis.log.Log(ERROR, "database error: failed to save order request: %v", err)
 	}
-	pastDelta := now.Add(-1 * time.Duration(allowedPastSeconds) * time.Second)
-	futureDelta := now.Add(time.Duration(allowedFutureSeconds) * time.Second)
 
-	if timestamp.Before(pastDelta) {
-		return "", pis.log.Log(ERROR, "timestamp is too old: %s", req.GeneratedAt)
+	return &pb_api.PredictionIntentResponse{
+		Message:      fmt.Sprintf("Processed input for user %s", req.AccountId),
+		Status:       lib.PREDICTION_INTENT_OPEN, // matched asynchronously - see GetPredictionIntent
+		RemainingQty: req.Qty,
+		TimeInForce:  timeInForce,
+	}, nil
+}
+
+/*
+*
+Validate a new intent (or the replacement of replacesTxId) - its signature(s), the account's key, the market,
+its trading rules (see TradingRulesService) and the account's funds (see RiskService). Returns the effective time in force.
+*/
+func (pis *PredictionIntentsService) validatePredictionIntent(req *pb_api.PredictionIntentRequest, replacesTxId string) (string, error) {
+	/////
+	// validations
+	/////
+	// Validate account ID format and minimum account number
+	accountId, err := hiero.AccountIDFromString(req.AccountId)
+	if err != nil {
+		return "", pis.log.Log(ERROR, "invalid accountId format: %v", err)
 	}
 
-	if timestamp.After(futureDelta) {
-		return "", pis.log.Log(ERROR, "timestamp is too far in the future: %s. Now: %s", req.GeneratedAt, now)
+	// Validate timestamp is within the last TIMESTAMP_ALLOWED_PAST_SECONDS seconds
+	if _, err := pis.parseGeneratedAt(req.GeneratedAt); err != nil {
+		return "", err
 	}
 
 	// check we haven't received this txid previously
@@ -102,6 +138,18 @@ func (pis *PredictionIntentsService) CreatePredictionIntent(req *pb_api.Predicti
 		return "", pis.log.Log(ERROR, "invalid network: %s", req.Net)
 	}
 
+	// GTC by default - but a market order must never rest on the book, so it is IOC unless it asks for FOK
+	timeInForce := lib.TIME_IN_FORCE_GTC
+	if req.MarketLimit == "market" {
+		timeInForce = lib.TIME_IN_FORCE_IOC
+	}
+	if req.TimeInForce != nil {
+		timeInForce = req.GetTimeInForce()
+	}
+	if req.MarketLimit == "market" && timeInForce == lib.TIME_IN_FORCE_GTC {
+		return "", pis.log.Log(ERROR, "a market order can not be %s - use %s or %s", lib.TIME_IN_FORCE_GTC, lib.TIME_IN_FORCE_IOC, lib.TIME_IN_FORCE_FOK)
+	}
+
 	// First look up the Hedera accountId against the mirror node
 	publicKeyLookedUp, keyTypeLookedUp, err := pis.hederaService.GetPublicKey(accountId, netSelectedByUser)
 	if err != nil {
@@ -147,10 +195,25 @@ func (pis *PredictionIntentsService) CreatePredictionIntent(req *pb_api.Predicti
 	// if we get here, the sig is valid
 	pis.log.Log(INFO, "**Signature is valid for account %s**", req.AccountId)
 
-	// Ensure user has provided enough of an allowance
-	_networkSelected, err := hiero.LedgerIDFromString(netSelectedByUser)
-	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to get network selected: %v", err)
+	// good-till-time: expires_at carries its own signature (the sig above is also verified on-chain, so its payload is fixed)
+	if (req.ExpiresAt == nil) != (req.ExpirySig == nil) {
+		return "", pis.log.Log(ERROR, "expiresAt and expirySig must be sent together")
+	}
+	if req.ExpiresAt != nil {
+		expiresAt, err := time.Parse(time.RFC3339, req.GetExpiresAt())
+		if err != nil {
+			return "", pis.log.Log(ERROR, "invalid expiresAt format: %v", err)
+		}
+		if !expiresAt.After(time.Now()) {
+			return "", pis.log.Log(ERROR, "expiresAt %s has already passed", req.GetExpiresAt())
+		}
+		isValidExpirySig, err := lib.VerifySig(&publicKey, lib.Utf82hex(lib.AssemblePredictionIntentExpiryPayload(req)), req.GetExpirySig())
+		if err != nil {
+			return "", pis.log.Log(ERROR, "failed to verify expiry signature: %v", err)
+		}
+		if !isValidExpirySig {
+			return "", pis.log.Log(ERROR, "invalid expiry signature for account %s", req.AccountId)
+		}
 	}
 
 	// NO, don't use the current X_SMART_CONTRACT_ID loaded from env vars
@@ -163,54 +226,32 @@ func (pis *PredictionIntentsService) CreatePredictionIntent(req *pb_api.Predicti
 	if err != nil {
 		return "", pis.log.Log(ERROR, "failed to get market by id %s: %v", req.MarketId, err)
 	}
-	_smartContractId, err := hiero.ContractIDFromString(market.SmartContractID)
-	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to validate smart contract ID from market %s: %v", req.MarketId, err)
+	// reject orders unless the market is open (and not suspended)
+	if market.Status != lib.MARKET_STATUS_OPEN || market.IsSuspended {
+		return "", pis.log.Log(ERROR, "market %s is not accepting orders (status=%s, isSuspended=%t)", req.MarketId, market.Status, market.IsSuspended)
 	}
-
-	// read USDC address from env var
-	usdcAddress, err := hiero.ContractIDFromString(os.Getenv(fmt.Sprintf("%s_USDC_ADDRESS", strings.ToUpper(netSelectedByUser))))
-	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to validate %s_USDC_ADDRESS: %v", strings.ToUpper(netSelectedByUser), err)
+	// reject orders once the market has closed (the cron job may not have closed it yet)
+	if market.ClosedAt.Valid || !time.Now().Before(market.ClosesAt) {
+		return "", pis.log.Log(ERROR, "market %s closed at %s and is no longer accepting orders", req.MarketId, market.ClosesAt.Format(time.RFC3339))
 	}
 
-	// ensure user has provided enough of an allowance to the smart contract:
-	spenderAllowanceUsd, err := pis.hederaService.GetSpenderAllowanceUsd(*_networkSelected, accountId, _smartContractId, usdcAddress, usdcDecimals)
+	// min/max notional, price tick and qty step - of the market, else of its network
+	err = pis.tradingRulesService.CheckPredictionIntent(req)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to get spender allowance: %v", err)
+		return "", err
 	}
-	pis.log.Log(INFO, "Spender allowance for account %s on contract %s: $%.2f", accountId.String(), _smartContractId.String(), spenderAllowanceUsd)
 
-	if spenderAllowanceUsd < math.Abs(req.GetPriceUsd()*req.GetQty()) {
-		return "", pis.log.Log(ERROR, "Spender allowance ($USD%.2f USD token = %s) too low for this predictionIntent ($USD%.2f)", spenderAllowanceUsd, usdcAddress.String(), req.GetPriceUsd()*req.GetQty())
-	}
-
-	// ensure the spenderAllowanceUsd is <= usdc balance currently in the user's wallet
-	currentUserBalanceUsdc, err := pis.hederaService.GetUsdcBalanceUsd(*_networkSelected, accountId)
+	// the account's open intents on every market and network must stay funded too - not just this one
+	err = pis.riskService.CheckPredictionIntent(req, market.SmartContractID, replacesTxId)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to get user's USDC balance: %v", err)
-	}
-	pis.log.Log(INFO, "Current USDC balance for account %s: $%.2f", accountId.String(), currentUserBalanceUsdc)
-	pis.log.Log(INFO, "Spender allowance for account %s: $%.2f", accountId.String(), spenderAllowanceUsd)
-	if spenderAllowanceUsd <= currentUserBalanceUsdc {
-		// OK
-	} else {
-		if math.Abs(req.PriceUsd)*req.Qty <= currentUserBalanceUsdc {
-			// this is also OK - let's not warn the user that their allowance is higher than their balance
-		} else {
-			return "", pis.log.Log(ERROR, "Spender allowance ($USD%.2f) is greater than than the user's balance ($USD%.2f)", spenderAllowanceUsd, currentUserBalanceUsdc)
-		}
+		return "", err
 	}
 
-	/// OK - All validations passed
-	/// Now you can (attempt to) put the order on the CLOB (subject to on-chain sig verification)
-
-	/////
-	// notify the CLOB via NATS:
-	/////
+	return timeInForce, nil
+}
 
-	// Marshal the CLOB req: *pb_api.PredictionIntentRequest to JSON
-	clobRequestObj := &pb_clob.CreateOrderRequestClob{
+func newClobOrderRequest(req *pb_api.PredictionIntentRequest, timeInForce string) *pb_clob.CreateOrderRequestClob {
+	return &pb_clob.CreateOrderRequestClob{
 		TxId:        req.TxId,
 		Net:         req.Net,
 		MarketId:    req.MarketId,
@@ -223,43 +264,306 @@ func (pis *PredictionIntentsService) CreatePredictionIntent(req *pb_api.Predicti
 		PublicKey:   req.PublicKey, // passing extra key info - i) avoid lookups ii) handle situation where user has changed their key
 		EvmAddress:  req.EvmAddress,
 		KeyType:     int32(req.KeyType),
+		TimeInForce: timeInForce,
+		ExpiresAtMs: clobExpiresAtMs(req.GetExpiresAt()),
 	}
-	clobRequestJSON, err := json.Marshal(clobRequestObj)
+}
+
+// good-till-time: the CLOB stops matching the order once this passes (the cron then marks it expired) - 0 for good-till-cancelled
+func clobExpiresAtMs(expiresAt string) int64 {
+	if expiresAt == "" {
+		return 0
+	}
+	parsed, err := time.Parse(time.RFC3339, expiresAt) // already validated by checkPredictionIntent
+	if err != nil {
+		return 0
+	}
+	return parsed.UnixMilli()
+}
+
+/*
+*
+Place an IOC or FOK order: matched synchronously on the CLOB (gRPC rather than NATS) so the outcome can go back to the caller.
+Stored first, so the match events published by the CLOB find the intent. Nothing of it ever rests on the book -
+whatever did not fill (an IOC remainder, or a FOK that could not fill in full) is cancelled straight away.
+*/
+func (pis *PredictionIntentsService) placeImmediateOrder(req *pb_api.PredictionIntentRequest, clobRequestObj *pb_clob.CreateOrderRequestClob) (*pb_api.PredictionIntentResponse, error) {
+	// Step 1: store the OrderRequest in the database - the txid must be unique or this fails
+	_, err := pis.predictionIntentsRepository.CreateOrderIntentRequest(req, clobRequestObj.TimeInForce)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to marshal CLOB request: %v", err)
+		return nil, pis.log.Log(ERROR, "database error: failed to save order request: %v", err)
 	}
 
-	// Publish the message to NATS:
-	err = pis.natsService.Publish(lib.SUBJECT_CLOB_ORDERS, clobRequestJSON)
+	// Step 2: match on the CLOB
+	outcome, err := lib.CreateOrderOnClob(clobRequestObj)
 	if err != nil {
-		return "", pis.log.Log(ERROR, "failed to publish to NATS: %v", err)
+		if _, cancelErr := pis.predictionIntentsRepository.CancelPredictionIntent(req.TxId, ""); cancelErr != nil {
+			pis.log.Log(ERROR, "failed to cancel %s order %s after the CLOB rejected it: %v", clobRequestObj.TimeInForce, req.TxId, cancelErr)
+		}
+		return nil, pis.log.Log(ERROR, "failed to place %s order (txId=%s): %v", clobRequestObj.TimeInForce, req.TxId, err)
 	}
 
-	pis.log.Log(INFO, "Published order to NATS subject '%s': %s", lib.SUBJECT_CLOB_ORDERS, string(clobRequestJSON))
+	// Step 3: cancel whatever did not fill
+	return pis.settleImmediateOrder(req, clobRequestObj.TimeInForce, outcome)
+}
 
-	// now store the OrderRequest in the database - the txid must be unique or this fails
-	_, err = pis.predictionIntentsRepository.CreateOrderIntentRequest(req)
+// the CLOB does not rest what an IOC/FOK order could not fill - cancel it in the database too
+func (pis *PredictionIntentsService) settleImmediateOrder(req *pb_api.PredictionIntentRequest, timeInForce string, outcome *pb_clob.CreateOrderResponse) (*pb_api.PredictionIntentResponse, error) {
+	response := &pb_api.PredictionIntentResponse{
+		Message:      fmt.Sprintf("Processed input for user %s", req.AccountId),
+		Status:       lib.PREDICTION_INTENT_FILLED, // fully_matched_at is set when the match event comes in
+		FilledQty:    outcome.FilledQty,
+		RemainingQty: outcome.RemainingQty,
+		TimeInForce:  timeInForce,
+	}
+	if outcome.RemainingQty <= 0 {
+		return response, nil
+	}
+
+	_, err := pis.predictionIntentsRepository.CancelPredictionIntent(req.TxId, "")
 	if err != nil {
-		return "", pis.log.Log(ERROR, "database error: failed to save order request: %v", err)
+		return nil, pis.log.Log(ERROR, "failed to cancel the unfilled %f of %s order %s: %v", outcome.RemainingQty, timeInForce, req.TxId, err)
+	}
+
+	// an IOC that filled in part is told apart from one that filled nothing
+	if outcome.FilledQty > 0 {
+		response.Status = lib.PREDICTION_INTENT_PARTIALLY_FILLED
+		response.Message = fmt.Sprintf("%s order filled %g of %g - the remaining %g was cancelled", timeInForce, outcome.FilledQty, req.Qty, outcome.RemainingQty)
+		return response, nil
 	}
 
-	return fmt.Sprintf("Processed input for user %s", req.AccountId), nil
+	response.Status = lib.PREDICTION_INTENT_CANCELLED
+	response.Message = fmt.Sprintf("%s order filled nothing of %g - it was cancelled", timeInForce, req.Qty)
+	return response, nil
 }
 
-func (pis *PredictionIntentsService) CancelPredictionIntent(marketId string, txId string) (*pb_api.StdResponse, error) {
+/*
+*
+Cancel every open prediction intent of an account in one call - optionally only one market and/or one side.
+Signed (see lib.AssembleCancelAllPredictionIntentsPayload) by the account's current key, and only intents generated
+up to the cancel's generatedAt are cancelled, so a replayed request can not pull orders placed after it.
+The orders are removed from the CLOB with a single CancelOrders call before they are cancelled in the database, so a failed call can simply be retried.
+*/
+func (pis *PredictionIntentsService) CancelAllPredictionIntents(req *pb_api.CancelAllPredictionIntentsRequest) (*pb_api.CancelAllPredictionIntentsResponse, error) {
 	// guards
+	generatedAt, err := pis.parseGeneratedAt(req.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// the public key sent must be the account's current key on this network
+	accountId, err := hiero.AccountIDFromString(req.AccountId)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "invalid account ID: %s", req.AccountId)
+	}
+	publicKeyLookedUp, _, err := pis.hederaService.GetPublicKey(accountId, req.Net)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to get public key: %v", err)
+	}
+	publicKey, err := hiero.PublicKeyFromString(req.PublicKey)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to parse public key from string: %v", err)
+	}
+	if publicKeyLookedUp.String() != publicKey.String() || publicKey.String() == "" {
+		return nil, pis.log.Log(ERROR, "public key mismatch: expected %s, got %s", publicKeyLookedUp.String(), publicKey.String())
+	}
 
+	isValidSig, err := lib.VerifySig(&publicKey, lib.Utf82hex(lib.AssembleCancelAllPredictionIntentsPayload(req)), req.Sig)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to verify signature: %v", err)
+	}
+	if !isValidSig {
+		return nil, pis.log.Log(ERROR, "invalid signature for account %s", req.AccountId)
+	}
+
+	/////
 	// OK
+	/////
+	// Step 1: find the open intents the cancel covers
+	open, err := pis.predictionIntentsRepository.GetCancellablePredictionIntentsByAccountId(req.Net, req.AccountId, req.GetMarketId(), req.GetSide(), generatedAt)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to get open prediction intents of account %s: %v", req.AccountId, err)
+	}
+	if len(open) == 0 {
+		return &pb_api.CancelAllPredictionIntentsResponse{CancelledTxIds: []string{}}, nil
+	}
 
+	// Step 2: remove them from the CLOB (one call) - first, so a failure leaves them open everywhere and the cancel can simply be retried
+	clobOrders := make([]*pb_clob.CancelOrderRequest, 0, len(open))
+	txIds := make([]uuid.UUID, 0, len(open))
+	for _, o := range open {
+		clobOrders = append(clobOrders, &pb_clob.CancelOrderRequest{
+			MarketId: o.MarketID.String(),
+			TxId:     o.TxID.String(),
+		})
+		txIds = append(txIds, o.TxID)
+	}
+	removedTxIds, err := lib.CancelOrdersOnClob(clobOrders)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to remove %d prediction intents of account %s from the CLOB: %v", len(clobOrders), req.AccountId, err)
+	}
+
+	// Step 3: cancel them in the database (one statement)
+	cancelled, err := pis.predictionIntentsRepository.CancelPredictionIntents(txIds, req.Sig)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "removed %d prediction intents of account %s from the CLOB but failed to cancel them in the database: %v", len(removedTxIds), req.AccountId, err)
+	}
+
+	cancelledTxIds := make([]string, 0, len(cancelled))
+	for _, txId := range cancelled {
+		cancelledTxIds = append(cancelledTxIds, txId.String())
+	}
+
+	pis.log.Log(INFO, "Cancelled %d prediction intents of account %s (%d were on the CLOB)", len(cancelledTxIds), req.AccountId, len(removedTxIds))
+	return &pb_api.CancelAllPredictionIntentsResponse{CancelledTxIds: cancelledTxIds}, nil
+}
+
+/*
+*
+Replace an open prediction intent with a new one (e.g. a market maker re-quoting). The request must be signed
+(see lib.AssembleReplacePredictionIntentPayload) by the key that signed the intent being replaced.
+The replacement is stored (linked to the old intent, which stays open) before the CLOB is touched, so its match events find it.
+The CLOB then cancels the old order and places the replacement under one lock of the book, so nothing can fill the old order in between -
+and if it has already filled (or left the book) the replace fails, the replacement is deleted again and nothing changes.
+*/
+func (pis *PredictionIntentsService) ReplacePredictionIntent(req *pb_api.ReplacePredictionIntentRequest) (*pb_api.PredictionIntentResponse, error) {
+	replacement := req.Replacement
+
+	// guards
+	predictionIntent, err := pis.predictionIntentsRepository.GetPredictionIntent(req.TxId)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to get prediction intent (txId=%s): %v", req.TxId, err)
+	}
+	if predictionIntent == nil || !strings.EqualFold(predictionIntent.MarketID.String(), replacement.MarketId) {
+		return nil, pis.log.Log(ERROR, "prediction intent not found (marketId=%s, txId=%s)", replacement.MarketId, req.TxId)
+	}
+	if predictionIntent.AccountID != replacement.AccountId || predictionIntent.Net != strings.ToLower(replacement.Net) {
+		return nil, pis.log.Log(ERROR, "the replacement must be for the same account and network as prediction intent %s", req.TxId)
+	}
+
+	// verify against the key stored with the intent being replaced
+	publicKey, err := hiero.PublicKeyFromString(predictionIntent.PublicKeyHex)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to parse stored public key of prediction intent (txId=%s): %v", req.TxId, err)
+	}
+	isValidSig, err := lib.VerifySig(&publicKey, lib.Utf82hex(lib.AssembleReplacePredictionIntentPayload(req)), req.Sig)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to verify signature: %v", err)
+	}
+	if !isValidSig {
+		return nil, pis.log.Log(ERROR, "invalid signature to replace prediction intent (txId=%s)", req.TxId)
+	}
+
+	if predictionIntent.CancelledAt.Valid || predictionIntent.FullyMatchedAt.Valid || predictionIntent.EvictedAt.Valid || predictionIntent.ClosedAt.Valid || predictionIntent.ExpiredAt.Valid {
+		return nil, pis.log.Log(ERROR, "prediction intent is no longer open (txId=%s)", req.TxId)
+	}
+
+	// the replacement is validated as any new intent - before the CLOB is touched
+	timeInForce, err := pis.validatePredictionIntent(replacement, req.TxId)
+	if err != nil {
+		return nil, err
+	}
+
+	/////
+	// OK
+	/////
+
+	// Step 1: store the replacement (linked both ways) - the old intent stays open until the CLOB has swapped the orders
+	_, err = pis.predictionIntentsRepository.CreatePredictionIntentReplacement(req.TxId, replacement, timeInForce)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "database error: failed to save replacement %s of prediction intent %s: %v", replacement.TxId, req.TxId, err)
+	}
+
+	// Step 2: swap the orders on the CLOB - fails if the old order is no longer on the book
+	outcome, err := lib.ReplaceOrderOnClob(req.TxId, newClobOrderRequest(replacement, timeInForce))
+	if err != nil {
+		if revertErr := pis.predictionIntentsRepository.RevertPredictionIntentReplacement(req.TxId, replacement.TxId); revertErr != nil {
+			pis.log.Log(ERROR, "failed to revert replacement %s of prediction intent %s after the CLOB rejected it: %v", replacement.TxId, req.TxId, revertErr)
+		}
+		return nil, pis.log.Log(ERROR, "failed to replace prediction intent %s with %s: %v", req.TxId, replacement.TxId, err)
+	}
+
+	// Step 3: cancel the old intent
+	isCancelled, err := pis.predictionIntentsRepository.CompletePredictionIntentReplacement(req.TxId, replacement.TxId, req.Sig)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "prediction intent %s was replaced by %s on the CLOB but could not be cancelled in the database: %v", req.TxId, replacement.TxId, err)
+	}
+	if !isCancelled {
+		pis.log.Log(WARN, "prediction intent %s left the book before its replacement %s was confirmed", req.TxId, replacement.TxId)
+	}
+	pis.log.Log(INFO, "Replaced prediction intent %s with %s", req.TxId, replacement.TxId)
+
+	// Step 4: an IOC/FOK replacement does not rest - cancel whatever did not fill
+	if timeInForce != lib.TIME_IN_FORCE_GTC {
+		return pis.settleImmediateOrder(replacement, timeInForce, outcome)
+	}
+
+	return &pb_api.PredictionIntentResponse{
+		Message:      fmt.Sprintf("Replaced %s with %s for user %s", req.TxId, replacement.TxId, replacement.AccountId),
+		Status:       lib.PREDICTION_INTENT_OPEN,
+		FilledQty:    outcome.FilledQty,
+		RemainingQty: outcome.RemainingQty,
+		TimeInForce:  timeInForce,
+	}, nil
+}
+
+/*
+*
+Cancel a prediction intent on behalf of its account. The request must be signed (see lib.AssembleCancelPredictionIntentPayload)
+by the key that signed the intent - txIds are public (e.g. the CLOB's GetBook), so knowing one is not enough.
+The signature is stored against the intent for audit.
+*/
+func (pis *PredictionIntentsService) CancelPredictionIntent(req *pb_api.CancelOrderRequest) (*pb_api.StdResponse, error) {
+	// guards
+	predictionIntent, err := pis.predictionIntentsRepository.GetPredictionIntent(req.TxId)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to get prediction intent (txId=%s): %v", req.TxId, err)
+	}
+	if predictionIntent == nil || !strings.EqualFold(predictionIntent.MarketID.String(), req.MarketId) {
+		return nil, pis.log.Log(ERROR, "prediction intent not found (marketId=%s, txId=%s)", req.MarketId, req.TxId)
+	}
+
+	// verify against the key stored with the intent, not one sent with the cancel
+	publicKey, err := hiero.PublicKeyFromString(predictionIntent.PublicKeyHex)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to parse stored public key of prediction intent (txId=%s): %v", req.TxId, err)
+	}
+	isValidSig, err := lib.VerifySig(&publicKey, lib.Utf82hex(lib.AssembleCancelPredictionIntentPayload(req)), req.Sig)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to verify signature: %v", err)
+	}
+	if !isValidSig {
+		return nil, pis.log.Log(ERROR, "invalid signature to cancel prediction intent (txId=%s)", req.TxId)
+	}
+
+	if predictionIntent.CancelledAt.Valid || predictionIntent.FullyMatchedAt.Valid || predictionIntent.EvictedAt.Valid || predictionIntent.ClosedAt.Valid || predictionIntent.ExpiredAt.Valid {
+		return nil, pis.log.Log(ERROR, "prediction intent is no longer open (txId=%s)", req.TxId)
+	}
+
+	/////
+	// OK
+	/////
+	return pis.cancelPredictionIntent(predictionIntent.MarketID.String(), predictionIntent.TxID.String(), req.Sig)
+}
+
+// cancel without a signature - for the system only (e.g. the cron evicting intents the account can no longer fund)
+func (pis *PredictionIntentsService) CancelPredictionIntentBySystem(marketId string, txId string) (*pb_api.StdResponse, error) {
+	return pis.cancelPredictionIntent(marketId, txId, "")
+}
+
+func (pis *PredictionIntentsService) cancelPredictionIntent(marketId string, txId string, cancelSig string) (*pb_api.StdResponse, error) {
 	// 1. Mark the position as cancelled in the database
-	// - prediction_intents: set the cancelled_at timestamp
+	// - prediction_intents: set the cancelled_at timestamp (and the cancel_sig, if the account cancelled)
 	// 2. Remove the order from the CLOB
 
 	// 1 - Mark the order as cancelled in the database
-	err := pis.predictionIntentsRepository.CancelPredictionIntent(txId)
+	isCancelled, err := pis.predictionIntentsRepository.CancelPredictionIntent(txId, cancelSig)
 	if err != nil {
 		return nil, pis.log.Log(ERROR, "failed to cancel prediction intent: %v", err)
 	}
+	if !isCancelled {
+		return nil, pis.log.Log(ERROR, "prediction intent is no longer open (txId=%s)", txId)
+	}
 
 	// TODO - in future, this will be done using NATS/Jetstream
 	// 2 - Notify the CLOB via NATS:
@@ -308,6 +612,137 @@ func (pis *PredictionIntentsService) CancelPredictionIntent(marketId string, txI
 	return response, nil
 }
 
+/*
+*
+The status of one prediction intent, derived from its *_at columns, with every fill recorded in matches.
+An evicted intent is also cancelled (see CronService), so eviction is checked first.
+*/
+func (pis *PredictionIntentsService) GetPredictionIntent(txId string) (*pb_api.PredictionIntentStatus, error) {
+	predictionIntent, err := pis.predictionIntentsRepository.GetPredictionIntent(txId)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to get prediction intent (txId=%s): %v", txId, err)
+	}
+	if predictionIntent == nil {
+		return nil, pis.log.Log(ERROR, "prediction intent not found (txId=%s)", txId)
+	}
+
+	fills, err := pis.predictionIntentsRepository.GetPredictionIntentFills(predictionIntent.TxID)
+	if err != nil {
+		return nil, pis.log.Log(ERROR, "failed to get fills of prediction intent (txId=%s): %v", txId, err)
+	}
+
+	filledQty := 0.0
+	pbFills := make([]*pb_api.PredictionIntentFill, 0, len(fills))
+	for _, fill := range fills {
+		filledQty += fill.Qty
+		pbFills = append(pbFills, &pb_api.PredictionIntentFill{
+			MatchId:          fill.ID,
+			CounterpartyTxId: fill.CounterpartyTxID.String(),
+			PriceUsd:         fill.PriceUsd,
+			Qty:              fill.Qty,
+			TxHash:           fill.TxHash,
+			MatchedAt:        fill.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	var status, reason string
+	var statusAt time.Time
+	switch {
+	case predictionIntent.EvictedAt.Valid:
+		status, reason, statusAt = lib.PREDICTION_INTENT_EVICTED, "insufficient USDC balance or allowance to cover the account's open prediction intents", predictionIntent.EvictedAt.Time
+	case predictionIntent.CancelledAt.Valid && predictionIntent.ReplacedByTxID.Valid:
+		status, reason, statusAt = lib.PREDICTION_INTENT_CANCELLED, "replaced by "+predictionIntent.ReplacedByTxID.UUID.String(), predictionIntent.CancelledAt.Time
+	case predictionIntent.CancelledAt.Valid && predictionIntent.CancelSig.Valid:
+		status, reason, statusAt = lib.PREDICTION_INTENT_CANCELLED, "cancelled by the account", predictionIntent.CancelledAt.Time
+	case predictionIntent.CancelledAt.Valid && predictionIntent.TimeInForce == lib.TIME_IN_FORCE_IOC && len(fills) > 0:
+		status, reason, statusAt = lib.PREDICTION_INTENT_PARTIALLY_FILLED, "IOC - partially filled at once, the remainder was cancelled", predictionIntent.CancelledAt.Time
+	case predictionIntent.CancelledAt.Valid && predictionIntent.TimeInForce == lib.TIME_IN_FORCE_IOC:
+		status, reason, statusAt = lib.PREDICTION_INTENT_CANCELLED, "IOC - nothing filled at once, so it was cancelled", predictionIntent.CancelledAt.Time
+	case predictionIntent.CancelledAt.Valid && predictionIntent.TimeInForce == lib.TIME_IN_FORCE_FOK:
+		status, reason, statusAt = lib.PREDICTION_INTENT_CANCELLED, "FOK - could not be filled in full at once", predictionIntent.CancelledAt.Time
+	case predictionIntent.CancelledAt.Valid:
+		// no sig - the system cancelled it: the market was resolved or voided, or an eviction did not get to mark it evicted
+		market, err := pis.marketsRepository.GetMarketById(predictionIntent.MarketID.String())
+		if err != nil {
+			return nil, pis.log.Log(ERROR, "failed to get market %s of prediction intent (txId=%s): %v", predictionIntent.MarketID.String(), txId, err)
+		}
+		reason = "cancelled by the system"
+		if market.ResolvedAt.Valid || market.VoidedAt.Valid {
+			reason = "cancelled when the market was resolved or voided"
+		}
+		status, statusAt = lib.PREDICTION_INTENT_CANCELLED, predictionIntent.CancelledAt.Time
+	case predictionIntent.ClosedAt.Valid:
+		status, reason, statusAt = lib.PREDICTION_INTENT_CLOSED, "the market closed before it was fully matched", predictionIntent.ClosedAt.Time
+	case predictionIntent.ExpiredAt.Valid:
+		status, reason, statusAt = lib.PREDICTION_INTENT_EXPIRED, "passed its expiresAt ("+formatExpiresAt(predictionIntent.ExpiresAt)+") before it was fully matched", predictionIntent.ExpiredAt.Time
+	case predictionIntent.FullyMatchedAt.Valid:
+		status, reason, statusAt = lib.PREDICTION_INTENT_FILLED, "fully matched", predictionIntent.FullyMatchedAt.Time
+	case len(fills) > 0:
+		status, statusAt = lib.PREDICTION_INTENT_PARTIALLY_FILLED, fills[len(fills)-1].CreatedAt
+	default:
+		status, statusAt = lib.PREDICTION_INTENT_OPEN, predictionIntent.CreatedAt
+	}
+
+	remainingQty := math.Max(0, predictionIntent.Qty-filledQty)
+	if status == lib.PREDICTION_INTENT_FILLED {
+		remainingQty = 0 // fills are floats - don't report dust once the CLOB says it is fully matched
+	}
+
+	return &pb_api.PredictionIntentStatus{
+		PredictionIntent: &pb_api.PredictionIntent{
+			TxId:           predictionIntent.TxID.String(),
+			Net:            predictionIntent.Net,
+			MarketId:       predictionIntent.MarketID.String(),
+			GeneratedAt:    predictionIntent.GeneratedAt.Format(time.RFC3339),
+			AccountId:      predictionIntent.AccountID,
+			MarketLimit:    predictionIntent.MarketLimit,
+			PriceUsd:       predictionIntent.PriceUsd,
+			Qty:            predictionIntent.Qty,
+			ExpiresAt:      formatExpiresAt(predictionIntent.ExpiresAt),
+			TimeInForce:    predictionIntent.TimeInForce,
+			ReplacesTxId:   formatTxId(predictionIntent.ReplacesTxID),
+			ReplacedByTxId: formatTxId(predictionIntent.ReplacedByTxID),
+		},
+		Status:       status,
+		Reason:       reason,
+		StatusAt:     statusAt.UTC().Format("2006-01-02T15:04:05Z"),
+		FilledQty:    filledQty,
+		RemainingQty: remainingQty,
+		CreatedAt:    predictionIntent.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		Fills:        pbFills,
+	}, nil
+}
+
+// generatedAt must be within TIMESTAMP_ALLOWED_PAST_SECONDS / TIMESTAMP_ALLOWED_FUTURE_SECONDS of now
+func (pis *PredictionIntentsService) parseGeneratedAt(generatedAt string) (time.Time, error) {
+	timestamp, err := time.Parse(time.RFC3339, generatedAt)
+	if err != nil {
+		return time.Time{}, pis.log.Log(ERROR, "invalid timestamp format: %v", err)
+	}
+
+	now := time.Now().UTC()
+	allowedPastSeconds, err := strconv.Atoi(os.Getenv("TIMESTAMP_ALLOWED_PAST_SECONDS"))
+	if err != nil {
+		return time.Time{}, pis.log.Log(ERROR, "invalid TIMESTAMP_ALLOWED_PAST_SECONDS environment variable: %v", err)
+	}
+	allowedFutureSeconds, err := strconv.Atoi(os.Getenv("TIMESTAMP_ALLOWED_FUTURE_SECONDS"))
+	if err != nil {
+		return time.Time{}, pis.log.Log(ERROR, "invalid TIMESTAMP_ALLOWED_FUTURE_SECONDS environment variable: %v", err)
+	}
+	pastDelta := now.Add(-1 * time.Duration(allowedPastSeconds) * time.Second)
+	futureDelta := now.Add(time.Duration(allowedFutureSeconds) * time.Second)
+
+	if timestamp.Before(pastDelta) {
+		return time.Time{}, pis.log.Log(ERROR, "timestamp is too old: %s", generatedAt)
+	}
+
+	if timestamp.After(futureDelta) {
+		return time.Time{}, pis.log.Log(ERROR, "timestamp is too far in the future: %s. Now: %s", generatedAt, now)
+	}
+
+	return timestamp, nil
+}
+
 func (pis *PredictionIntentsService) GetAllOpenPredictionIntentsByMarketId(marketId string) (*[]sqlc.PredictionIntent, error) {
 	predictionIntent, err := pis.predictionIntentsRepository.GetAllOpenPredictionIntentsByMarketId(marketId)
 	if err != nil {
@@ -333,7 +768,7 @@ func (pis *PredictionIntentsService) GetAllPredictionIntents(limit int32, offset
 	// Map []sqlc.PredictionIntent to []*pb_api.PredictionIntentRequest
 	var pbPredictionIntents []*pb_api.PredictionIntentRequest
 	for _, pi := range predictionIntents {
-		pbPredictionIntents = append(pbPredictionIntents, &pb_api.PredictionIntentRequest{
+		pbPredictionIntent := &pb_api.PredictionIntentRequest{
 			TxId:        pi.TxID.String(),
 			Net:         pi.Net,
 			MarketId:    pi.MarketID.String(),
@@ -346,8 +781,31 @@ func (pis *PredictionIntentsService) GetAllPredictionIntents(limit int32, offset
 			EvmAddress:  pi.Evmaddress,
 			KeyType:     uint32(pi.Keytype),
 			GeneratedAt: pi.GeneratedAt.Format(time.RFC3339),
-		})
+			TimeInForce: &pi.TimeInForce,
+		}
+		if pi.ExpiresAt.Valid { // good-till-time
+			expiresAt := formatExpiresAt(pi.ExpiresAt)
+			pbPredictionIntent.ExpiresAt = &expiresAt
+			pbPredictionIntent.ExpirySig = &pi.ExpirySig.String
+		}
+		pbPredictionIntents = append(pbPredictionIntents, pbPredictionIntent)
 	}
 
 	return pbPredictionIntents, nil
 }
+
+// empty for a good-till-cancelled intent. Always with milliseconds, as the client sent and signed it - so expirySig can be verified again from the stored value
+func formatExpiresAt(expiresAt sql.NullTime) string {
+	if !expiresAt.Valid {
+		return ""
+	}
+	return expiresAt.Time.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// empty unless the intent is linked to another (see ReplacePredictionIntent)
+func formatTxId(txId uuid.NullUUID) string {
+	if !txId.Valid {
+		return ""
+	}
+	return txId.UUID.String()
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// expiresAt goes back out exactly as the client signed it - millisecond precision, Zulu time
+func TestFormatExpiresAtRoundTrip(t *testing.T) {
+	for _, signed := range []string{"2026-10-17T12:34:56.789Z", "2026-10-17T12:34:56.000Z", "2026-12-31T23:59:59.001Z"} {
+		parsed, err := time.Parse(time.RFC3339, signed)
+		if err != nil {
+			t.Fatalf("%s: %v", signed, err)
+		}
+		got := formatExpiresAt(sql.NullTime{Time: parsed.In(time.FixedZone("CEST", 2*60*60)), Valid: true})
+		if got != signed {
+			t.Errorf("formatExpiresAt(%s) = %s", signed, got)
+		}
+	}
+
+	if got := formatExpiresAt(sql.NullTime{}); got != "" {
+		t.Errorf("good-till-cancelled: got %q, want empty", got)
+	}
+}
+
+func TestClobExpiresAtMs(t *testing.T) {
+	if got := clobExpiresAtMs("2026-10-17T12:34:56.789Z"); got != time.Date(2026, 10, 17, 12, 34, 56, 789_000_000, time.UTC).UnixMilli() {
+		t.Errorf("clobExpiresAtMs = %d", got)
+	}
+	if got := clobExpiresAtMs(""); got != 0 {
+		t.Errorf("good-till-cancelled: got %d, want 0", got)
+	}
+}
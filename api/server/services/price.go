@@ -1,8 +1,11 @@
 package services
 
 import (
+	"api/server/lib"
 	repositories "api/server/repositories"
 	"strconv"
+
+	"github.com/google/uuid"
 )
 
 type PriceService struct {
@@ -30,3 +33,28 @@ func (ps *PriceService) GetLatestPriceByMarket(marketId string) (float32, error)
 	}
 	return float32(priceFloat), nil
 }
+
+// latest price for each market in one query - markets that have never traded get the mid-market price (same as GetLatestPriceByMarket)
+func (ps *PriceService) GetLatestPricesForMarkets(marketIds []uuid.UUID) (map[uuid.UUID]float32, error) {
+	prices := make(map[uuid.UUID]float32, len(marketIds))
+	if len(marketIds) == 0 {
+		return prices, nil
+	}
+
+	rows, err := ps.priceRepository.GetLatestPricesForMarkets(marketIds)
+	if err != nil {
+		return nil, ps.log.Log(ERROR, "failed to get latest prices for markets: %v", err)
+	}
+
+	for _, marketId := range marketIds {
+		prices[marketId] = float32(lib.MID_MARKET_PRICE)
+	}
+	for _, row := range rows {
+		priceFloat, err := strconv.ParseFloat(row.Price, 32)
+		if err != nil {
+			return nil, ps.log.Log(ERROR, "failed to parse price for market %s: %v", row.MarketID.String(), err)
+		}
+		prices[row.MarketID] = float32(priceFloat)
+	}
+	return prices, nil
+}
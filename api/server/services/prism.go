@@ -23,9 +23,10 @@ type Prism struct {
 	hederaService            *HederaService
 	marketsService           *MarketsService
 	predictionIntentsService *PredictionIntentsService
+	tradingRulesService      *TradingRulesService
 }
 
-func (p *Prism) InitPrism(log *LogService, dbRepository *repositories.DbRepository, marketsRepository *repositories.MarketsRepository, matchesRepository *repositories.MatchesRepository, natsService *NatsService, hederaService *HederaService, marketsService *MarketsService, predictionIntentsService *PredictionIntentsService) error {
+func (p *Prism) InitPrism(log *LogService, dbRepository *repositories.DbRepository, marketsRepository *repositories.MarketsRepository, matchesRepository *repositories.MatchesRepository, natsService *NatsService, hederaService *HederaService, marketsService *MarketsService, predictionIntentsService *PredictionIntentsService, tradingRulesService *TradingRulesService) error {
 	// inject deps:
 	p.log = log
 	p.dbRepository = dbRepository
@@ -36,6 +37,7 @@ func (p *Prism) InitPrism(log *LogService, dbRepository *repositories.DbReposito
 	p.hederaService = hederaService
 	p.marketsService = marketsService
 	p.predictionIntentsService = predictionIntentsService
+	p.tradingRulesService = tradingRulesService
 
 	p.log.Log(INFO, "Service: Prism service initialized successfully, %p", p)
 	return nil
@@ -92,6 +94,11 @@ func (p *Prism) MacroMetadata() (*pb_api.MacroMetadataResponse, error) {
 		return nil, p.log.Log(ERROR, "MIN_ORDER_SIZE_USD environment variable is not a valid float: %v", err)
 	}
 
+	tradingRules, err := p.tradingRulesService.GetNetworkTradingRules(networks)
+	if err != nil {
+		return nil, err
+	}
+
 	totalVolumeUsd := make(map[string]float64)
 	resolutionPeriods := []string{"1h", "24h", "7d", "30d"}
 	for _, period := range resolutionPeriods {
@@ -120,6 +127,7 @@ func (p *Prism) MacroMetadata() (*pb_api.MacroMetadataResponse, error) {
 		TvlUsd:                      1234567.89,     // TODO - implement real TVL calculation
 		TotalVolumeUsd:              totalVolumeUsd, // TODO - implement a real total volume
 		ActiveTraders:               nActiveTraders,
+		TradingRules:                tradingRules,
 	}
 
 	return response, nil
@@ -151,6 +159,8 @@ func (p *Prism) TriggerRecreateClob() (bool, error) {
 		if err != nil {
 			return false, p.log.Log(ERROR, "failed to create new market (marketId=%s) on CLOB: %v", market.MarketID.String(), err)
 		}
+		// paused and suspended markets must not match - but a paused book rejects orders, so they are halted once restored (step 3)
+		isHalted := market.Status == lib.MARKET_STATUS_PAUSED || market.IsSuspended
 
 		/////
 		// step 2 - retrieve from db all the PredictionIntents for restoring to the CLOB
@@ -162,10 +172,24 @@ func (p *Prism) TriggerRecreateClob() (bool, error) {
 		}
 		p.log.Log(INFO, "--> Found %d open PredictionIntents on marketId %s", len(*allPredictionIntents), market.MarketID.String())
 
+		// a replace in flight (see ReplacePredictionIntent) leaves the old intent open until the CLOB has swapped the orders -
+		// restore the old order, not its replacement (the swap on the CLOB that is gone fails and is reverted)
+		pendingReplacementTxIds := make(map[string]bool)
+		for _, predictionIntent := range *allPredictionIntents {
+			if predictionIntent.ReplacedByTxID.Valid {
+				pendingReplacementTxIds[predictionIntent.ReplacedByTxID.UUID.String()] = true
+			}
+		}
+
 		n := 0
 		for _, predictionIntent := range *allPredictionIntents {
 			p.log.Log(INFO, "\t - txId: %s", predictionIntent.TxID.String())
 
+			if pendingReplacementTxIds[predictionIntent.TxID.String()] {
+				p.log.Log(WARN, "\t skipping txId %s - it replaces %s, which is still open", predictionIntent.TxID.String(), predictionIntent.ReplacesTxID.UUID.String())
+				continue
+			}
+
 			// calculate "qtyRemaining" to be placed on CLOB (may not exist)
 			var qtyRemaining float64 = predictionIntent.Qty // set to Qty by default
 
@@ -199,6 +223,15 @@ func (p *Prism) TriggerRecreateClob() (bool, error) {
 				continue
 			}
 
+			// IOC/FOK orders never rest on the book - one still open here was interrupted mid-flight
+			if predictionIntent.TimeInForce != lib.TIME_IN_FORCE_GTC {
+				_, err = p.predictionIntentsService.predictionIntentsRepository.CancelPredictionIntent(predictionIntent.TxID.String(), "")
+				if err != nil {
+					return false, p.log.Log(ERROR, "failed to cancel %s order (txId=%s): %v", predictionIntent.TimeInForce, predictionIntent.TxID.String(), err)
+				}
+				continue
+			}
+
 			/////
 			// Next, recreate the CLOB order request object
 			/////
@@ -216,6 +249,8 @@ func (p *Prism) TriggerRecreateClob() (bool, error) {
 				PublicKey:   predictionIntent.PublicKeyHex, // passing extra key info - i) avoid lookups ii) handle situation where user has changed their key
 				EvmAddress:  predictionIntent.Evmaddress,
 				KeyType:     int32(predictionIntent.Keytype),
+				TimeInForce: predictionIntent.TimeInForce,
+				ExpiresAtMs: clobExpiresAtMs(formatExpiresAt(predictionIntent.ExpiresAt)),
 			}
 			clobRequestJSON, err := json.Marshal(clobRequestObj)
 			if err != nil {
@@ -225,12 +260,19 @@ func (p *Prism) TriggerRecreateClob() (bool, error) {
 			p.log.Log(INFO, "\tre-creating tx (qty=%f, qtyOrig=%f): %v", clobRequestObj.Qty, clobRequestObj.QtyOrig, clobRequestObj)
 
 			/////
-			// And push to CLOB via NATS
+			// And push to CLOB via NATS - or synchronously for a halted market, so that every order is on the book before it is paused
 			/////
-			subject := lib.SUBJECT_CLOB_ORDERS
-			err = p.natsService.Publish(subject, clobRequestJSON)
-			if err != nil {
-				return false, p.log.Log(ERROR, "failed to publish to NATS subject %s: %v", subject, err)
+			if isHalted {
+				_, err = lib.CreateOrderOnClob(clobRequestObj)
+				if err != nil {
+					return false, p.log.Log(ERROR, "failed to restore order (txId=%s) on halted market (marketId=%s): %v", predictionIntent.TxID.String(), market.MarketID.String(), err)
+				}
+			} else {
+				subject := lib.SUBJECT_CLOB_ORDERS
+				err = p.natsService.Publish(subject, clobRequestJSON)
+				if err != nil {
+					return false, p.log.Log(ERROR, "failed to publish to NATS subject %s: %v", subject, err)
+				}
 			}
 			p.log.Log(INFO, "\tCLOB notified.")
 
@@ -245,6 +287,16 @@ func (p *Prism) TriggerRecreateClob() (bool, error) {
 			n = n + 1
 		}
 
+		/////
+		// step 3 - halt paused and suspended markets, now that their orders are restored
+		/////
+		if isHalted {
+			err = lib.PauseMarketOnClob(market.MarketID.String(), true)
+			if err != nil {
+				return false, p.log.Log(ERROR, "failed to pause market (marketId=%s) on CLOB: %v", market.MarketID.String(), err)
+			}
+		}
+
 		p.log.Log(INFO, "--> Done. Added %d orders to CLOB for marketId %s", n, market.MarketID.String())
 	}
 
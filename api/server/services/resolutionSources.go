@@ -0,0 +1,274 @@
+package services
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+/*
+*
+A ResolutionSource proposes the outcome of a market.
+ProposeOutcome returns nil (and no error) while the source can not decide yet - the cron simply asks again on its next run.
+*/
+type ResolutionSource interface {
+	ProposeOutcome(ctx context.Context, market *sqlc.Market) (*bool, error)
+}
+
+/*
+*
+Build the resolution source referenced by a market from its type and JSON config.
+Also used to validate a config before it is stored against a market.
+*/
+func NewResolutionSource(sourceType string, config []byte) (ResolutionSource, error) {
+	switch sourceType {
+	case lib.RESOLUTION_SOURCE_MANUAL:
+		return &ManualResolutionSource{}, nil
+
+	case lib.RESOLUTION_SOURCE_HTTP_JSON:
+		var source HttpJsonResolutionSource
+		if err := json.Unmarshal(config, &source); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", sourceType, err)
+		}
+		if source.Url == "" || source.JsonPath == "" || source.YesValue == "" {
+			return nil, fmt.Errorf("invalid %s config: url, jsonPath and yesValue are required", sourceType)
+		}
+		if err := checkResolutionSourceUrl(source.Url); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", sourceType, err)
+		}
+		return &source, nil
+
+	case lib.RESOLUTION_SOURCE_PRICE_THRESHOLD:
+		var source PriceThresholdResolutionSource
+		if err := json.Unmarshal(config, &source); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", sourceType, err)
+		}
+		if source.Url == "" || source.JsonPath == "" || source.At.IsZero() {
+			return nil, fmt.Errorf("invalid %s config: url, jsonPath and at are required", sourceType)
+		}
+		if err := checkResolutionSourceUrl(strings.ReplaceAll(source.Url, "{at}", "0")); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", sourceType, err)
+		}
+		return &source, nil
+
+	default:
+		return nil, fmt.Errorf("unknown resolution source: %s", sourceType)
+	}
+}
+
+/////
+// manual
+/////
+
+// an ADMIN or ORACLE proposes the outcome with ResolveMarket - never proposes anything on its own
+type ManualResolutionSource struct{}
+
+func (s *ManualResolutionSource) ProposeOutcome(ctx context.Context, market *sqlc.Market) (*bool, error) {
+	return nil, nil
+}
+
+/////
+// http_json
+/////
+
+/*
+*
+Reads a value from an HTTP JSON endpoint with a JSONPath rule, e.g.
+{"url": "https://api.example.com/games/42", "jsonPath": "$.result.winner", "yesValue": "home", "noValue": "away"}
+The value resolves YES when it equals yesValue. If noValue is set, only that value resolves NO and anything else
+(e.g. "pending") is undecided - otherwise any other non-null value resolves NO.
+*/
+type HttpJsonResolutionSource struct {
+	Url      string `json:"url"`
+	JsonPath string `json:"jsonPath"`
+	YesValue string `json:"yesValue"`
+	NoValue  string `json:"noValue,omitempty"`
+
+	httpClient *http.Client // nil => resolutionSourceHttpClient
+}
+
+func (s *HttpJsonResolutionSource) ProposeOutcome(ctx context.Context, market *sqlc.Market) (*bool, error) {
+	value, err := fetchJsonPath(ctx, s.httpClient, s.Url, s.JsonPath)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil // not published yet
+	}
+
+	valueStr := jsonScalarToString(value)
+	outcome := valueStr == s.YesValue
+	if !outcome && s.NoValue != "" && valueStr != s.NoValue {
+		return nil, nil // neither yes nor no (yet)
+	}
+	return &outcome, nil
+}
+
+/////
+// price_threshold
+/////
+
+/*
+*
+Resolves YES when a price read from an HTTP JSON endpoint is above threshold at time T, e.g.
+{"url": "https://api.example.com/price?symbol=HBAR&ts={at}", "jsonPath": "$.price", "threshold": 0.25, "at": "2026-12-31T00:00:00Z"}
+Nothing is proposed before T. A {at} placeholder in the url is replaced with T in unix seconds so historical
+price endpoints can be used - otherwise the price at the first poll after T is used.
+*/
+type PriceThresholdResolutionSource struct {
+	Url       string    `json:"url"`
+	JsonPath  string    `json:"jsonPath"`
+	Threshold float64   `json:"threshold"`
+	At        time.Time `json:"at"`
+
+	httpClient *http.Client // nil => resolutionSourceHttpClient
+}
+
+func (s *PriceThresholdResolutionSource) ProposeOutcome(ctx context.Context, market *sqlc.Market) (*bool, error) {
+	if time.Now().Before(s.At) {
+		return nil, nil
+	}
+
+	url := strings.ReplaceAll(s.Url, "{at}", strconv.FormatInt(s.At.Unix(), 10))
+	value, err := fetchJsonPath(ctx, s.httpClient, url, s.JsonPath)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	price, err := strconv.ParseFloat(jsonScalarToString(value), 64)
+	if err != nil {
+		return nil, fmt.Errorf("price at %s is not a number: %v", s.JsonPath, value)
+	}
+	outcome := price > s.Threshold
+	return &outcome, nil
+}
+
+/////
+// helpers
+/////
+
+/*
+*
+Resolution source urls are set by ADMINs but fetched from inside the network, so only public hosts are reached:
+http(s) only, a timeout, and no connections (including after redirects and DNS lookups) to loopback, private,
+link-local (e.g. cloud metadata) or unspecified addresses
+*/
+var resolutionSourceHttpClient = &http.Client{
+	Timeout: lib.RESOLUTION_SOURCE_HTTP_TIMEOUT_SECONDS * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil, // a proxy would make the dialed address the proxy's
+		DialContext: (&net.Dialer{
+			Timeout: lib.RESOLUTION_SOURCE_HTTP_TIMEOUT_SECONDS * time.Second,
+			Control: denyNonPublicAddress,
+		}).DialContext,
+		TLSHandshakeTimeout:   lib.RESOLUTION_SOURCE_HTTP_TIMEOUT_SECONDS * time.Second,
+		ResponseHeaderTimeout: lib.RESOLUTION_SOURCE_HTTP_TIMEOUT_SECONDS * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return fmt.Errorf("too many redirects")
+		}
+		return checkResolutionSourceUrl(req.URL.String())
+	},
+}
+
+// http(s) urls with a host only
+func checkResolutionSourceUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %v", rawUrl, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url %s must be http or https", rawUrl)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("url %s has no host", rawUrl)
+	}
+	return nil
+}
+
+// runs on the resolved address of every connection, so a public hostname can not point (or rebind) to an internal one
+func denyNonPublicAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %v", address, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %s", address)
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("address %s is not public", ip)
+	}
+	return nil
+}
+
+// 100.64.0.0/10 (carrier-grade NAT) - not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// client is nil for resolutionSourceHttpClient - tests pass their own to reach a local stub
+func fetchJsonPath(ctx context.Context, client *http.Client, url string, jsonPath string) (interface{}, error) {
+	if client == nil {
+		client = resolutionSourceHttpClient
+	}
+	if err := checkResolutionSourceUrl(url); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lib.RESOLUTION_SOURCE_HTTP_TIMEOUT_SECONDS*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, string(lib.GET), url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolution source url %s: %v", url, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1MB is plenty for a result document
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %v", url, err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("response from %s is not JSON: %v", url, err)
+	}
+
+	return lib.JsonPathLookup(doc, jsonPath)
+}
+
+// strings as-is, numbers without a trailing .0, bools as true/false
+func jsonScalarToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a local stub of a result/price endpoint - serves body as JSON and records the last request path
+func newJsonStub(t *testing.T, body string) (*httptest.Server, *string) {
+	t.Helper()
+	var lastRequestUri string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequestUri = r.URL.RequestURI()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &lastRequestUri
+}
+
+func outcomeString(outcome *bool) string {
+	if outcome == nil {
+		return "undecided"
+	}
+	if *outcome {
+		return "yes"
+	}
+	return "no"
+}
+
+func TestHttpJsonResolutionSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		noValue string
+		want    string
+	}{
+		{"yes value", `{"result": {"winner": "home"}}`, "", "yes"},
+		{"any other value is no", `{"result": {"winner": "away"}}`, "", "no"},
+		{"no value", `{"result": {"winner": "away"}}`, "away", "no"},
+		{"neither yes nor no value", `{"result": {"winner": "pending"}}`, "away", "undecided"},
+		{"not published yet", `{"result": {"winner": null}}`, "", "undecided"},
+		{"numbers", `{"result": {"winner": 1}}`, "", "no"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newJsonStub(t, tt.body)
+			source := &HttpJsonResolutionSource{
+				Url:        server.URL + "/games/42",
+				JsonPath:   "$.result.winner",
+				YesValue:   "home",
+				NoValue:    tt.noValue,
+				httpClient: server.Client(),
+			}
+
+			outcome, err := source.ProposeOutcome(context.Background(), &sqlc.Market{})
+			if err != nil {
+				t.Fatalf("ProposeOutcome() error = %v", err)
+			}
+			if got := outcomeString(outcome); got != tt.want {
+				t.Errorf("ProposeOutcome() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHttpJsonResolutionSourceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	source := &HttpJsonResolutionSource{Url: server.URL, JsonPath: "$.winner", YesValue: "home", httpClient: server.Client()}
+	if _, err := source.ProposeOutcome(context.Background(), &sqlc.Market{}); err == nil {
+		t.Error("ProposeOutcome() on a 503 should fail")
+	}
+
+	notJson, _ := newJsonStub(t, `<html></html>`)
+	source = &HttpJsonResolutionSource{Url: notJson.URL, JsonPath: "$.winner", YesValue: "home", httpClient: notJson.Client()}
+	if _, err := source.ProposeOutcome(context.Background(), &sqlc.Market{}); err == nil {
+		t.Error("ProposeOutcome() on a non-JSON response should fail")
+	}
+}
+
+func TestPriceThresholdResolutionSource(t *testing.T) {
+	at := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		body      string
+		threshold float64
+		at        time.Time
+		want      string
+		wantErr   bool
+	}{
+		{"above threshold", `{"data": {"price": 0.3}}`, 0.25, at, "yes", false},
+		{"below threshold", `{"data": {"price": 0.2}}`, 0.25, at, "no", false},
+		{"at threshold is not above", `{"data": {"price": 0.25}}`, 0.25, at, "no", false},
+		{"price as a string", `{"data": {"price": "0.3"}}`, 0.25, at, "yes", false},
+		{"no price yet", `{"data": {"price": null}}`, 0.25, at, "undecided", false},
+		{"before at", `{"data": {"price": 0.3}}`, 0.25, time.Now().Add(time.Hour), "undecided", false},
+		{"not a number", `{"data": {"price": "n/a"}}`, 0.25, at, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newJsonStub(t, tt.body)
+			source := &PriceThresholdResolutionSource{
+				Url:        server.URL + "/price",
+				JsonPath:   "$.data.price",
+				Threshold:  tt.threshold,
+				At:         tt.at,
+				httpClient: server.Client(),
+			}
+
+			outcome, err := source.ProposeOutcome(context.Background(), &sqlc.Market{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ProposeOutcome() = %s, want an error", outcomeString(outcome))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProposeOutcome() error = %v", err)
+			}
+			if got := outcomeString(outcome); got != tt.want {
+				t.Errorf("ProposeOutcome() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceThresholdResolutionSourceAtPlaceholder(t *testing.T) {
+	server, lastRequestUri := newJsonStub(t, `{"price": 1}`)
+	at := time.Unix(1767225600, 0).UTC()
+	source := &PriceThresholdResolutionSource{
+		Url:        server.URL + "/price?symbol=HBAR&ts={at}",
+		JsonPath:   "$.price",
+		Threshold:  0.5,
+		At:         at,
+		httpClient: server.Client(),
+	}
+
+	if _, err := source.ProposeOutcome(context.Background(), &sqlc.Market{}); err != nil {
+		t.Fatalf("ProposeOutcome() error = %v", err)
+	}
+	if want := "/price?symbol=HBAR&ts=1767225600"; *lastRequestUri != want {
+		t.Errorf("requested %s, want %s", *lastRequestUri, want)
+	}
+}
+
+func TestResolutionSourceHttpClientDeniesLocalAddresses(t *testing.T) {
+	server, _ := newJsonStub(t, `{"winner": "home"}`)
+
+	// no httpClient => resolutionSourceHttpClient, which must not reach the (loopback) stub
+	source := &HttpJsonResolutionSource{Url: server.URL, JsonPath: "$.winner", YesValue: "home"}
+	_, err := source.ProposeOutcome(context.Background(), &sqlc.Market{})
+	if err == nil || !strings.Contains(err.Error(), "is not public") {
+		t.Errorf("ProposeOutcome() against %s error = %v, want a non-public address error", server.URL, err)
+	}
+}
+
+func TestNewResolutionSourceUrls(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceType string
+		config     string
+		wantErr    bool
+	}{
+		{"https", lib.RESOLUTION_SOURCE_HTTP_JSON, `{"url": "https://api.example.com/games/42", "jsonPath": "$.winner", "yesValue": "home"}`, false},
+		{"file scheme", lib.RESOLUTION_SOURCE_HTTP_JSON, `{"url": "file:///etc/passwd", "jsonPath": "$.winner", "yesValue": "home"}`, true},
+		{"gopher scheme", lib.RESOLUTION_SOURCE_HTTP_JSON, `{"url": "gopher://api.example.com", "jsonPath": "$.winner", "yesValue": "home"}`, true},
+		{"no host", lib.RESOLUTION_SOURCE_HTTP_JSON, `{"url": "http://", "jsonPath": "$.winner", "yesValue": "home"}`, true},
+		{"at placeholder", lib.RESOLUTION_SOURCE_PRICE_THRESHOLD, `{"url": "https://api.example.com/price?ts={at}", "jsonPath": "$.price", "threshold": 0.25, "at": "2026-12-31T00:00:00Z"}`, false},
+		{"price threshold scheme", lib.RESOLUTION_SOURCE_PRICE_THRESHOLD, `{"url": "ftp://api.example.com/price", "jsonPath": "$.price", "threshold": 0.25, "at": "2026-12-31T00:00:00Z"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewResolutionSource(tt.sourceType, []byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewResolutionSource() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	repositories "api/server/repositories"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	hiero "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+)
+
+/*
+*
+A proposed outcome is not resolved on-chain straight away: holders can dispute it during the dispute window.
+proposed -> finalized once the window passes without a dispute (cron)
+proposed -> disputed -> decided (ADMIN upholds or overturns) -> finalized
+*/
+type ResolutionsService struct {
+	log                         *LogService
+	marketResolutionsRepository *repositories.MarketResolutionsRepository
+	marketsRepository           *repositories.MarketsRepository
+	positionsRepository         *repositories.PositionsRepository
+	marketsService              *MarketsService
+	hederaService               *HederaService
+	natsService                 *NatsService
+	disputeWindow               time.Duration
+}
+
+func (rs *ResolutionsService) Init(log *LogService, marketResolutionsRepository *repositories.MarketResolutionsRepository, marketsRepository *repositories.MarketsRepository, positionsRepository *repositories.PositionsRepository, marketsService *MarketsService, hederaService *HederaService, natsService *NatsService) error {
+	rs.log = log
+	rs.marketResolutionsRepository = marketResolutionsRepository
+	rs.marketsRepository = marketsRepository
+	rs.positionsRepository = positionsRepository
+	rs.marketsService = marketsService
+	rs.hederaService = hederaService
+	rs.natsService = natsService
+
+	// optional - 0 finalises a proposed outcome straight away
+	disputeWindowHours := lib.RESOLUTION_DISPUTE_WINDOW_HOURS
+	if disputeWindowHoursStr := os.Getenv("RESOLUTION_DISPUTE_WINDOW_HOURS"); disputeWindowHoursStr != "" {
+		hours, err := strconv.Atoi(disputeWindowHoursStr)
+		if err != nil || hours < 0 {
+			return rs.log.Log(ERROR, "invalid RESOLUTION_DISPUTE_WINDOW_HOURS: %s", disputeWindowHoursStr)
+		}
+		disputeWindowHours = hours
+	}
+	rs.disputeWindow = time.Duration(disputeWindowHours) * time.Hour
+
+	rs.log.Log(INFO, "Service: Resolutions service initialized successfully (dispute window %s)", rs.disputeWindow)
+	return nil
+}
+
+/*
+*
+Propose the outcome of a market and open the dispute window.
+A market that is still trading is closed first - nobody should trade against a published outcome.
+proposedBy is the ADMIN/ORACLE accountId, or source:<resolution_source> when the cron proposes.
+*/
+func (rs *ResolutionsService) ProposeResolution(marketId string, outcome bool, proposedBy string) (*pb_api.MarketResolution, error) {
+	// guards
+	market, err := rs.marketsRepository.GetMarketById(marketId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get market by id: %v", err)
+	}
+	if market.Status != lib.MARKET_STATUS_OPEN && market.Status != lib.MARKET_STATUS_PAUSED && market.Status != lib.MARKET_STATUS_CLOSED {
+		return nil, rs.log.Log(ERROR, "market %s is %s - an outcome can not be proposed", marketId, market.Status)
+	}
+
+	/////
+	// OK
+	/////
+	// Step 1: on the **db**, in one transaction - close the market if it is still trading, move it to resolving and record the proposal (only one per market)
+	resolution, closedMarket, closedTxIds, err := rs.marketResolutionsRepository.ProposeMarketResolution(market.MarketID, outcome, proposedBy, time.Now().Add(rs.disputeWindow))
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to propose market resolution: %v", err)
+	}
+	if resolution == nil {
+		return nil, rs.log.Log(ERROR, "an outcome has already been proposed for market %s", marketId)
+	}
+	rs.log.Log(INFO, "Outcome=%t proposed for market %s by %s, dispute window ends %s", outcome, marketId, proposedBy, resolution.DisputeEndsAt.Format(time.RFC3339))
+
+	// Step 2: stop trading if the market was still open (CLOB -> NATS, as the cron does at closes_at)
+	if closedMarket != nil {
+		err = removeMarketFromClob(rs.marketsRepository, closedMarket)
+		if err != nil {
+			rs.log.Log(WARN, "market %s closed but failed to remove it from the CLOB (retried by the cron): %v", marketId, err)
+		}
+		err = publishMarketClosedEvent(rs.natsService, closedMarket, closedTxIds)
+		if err != nil {
+			rs.log.Log(ERROR, "failed to publish market closed event for market %s: %v", marketId, err)
+		}
+	}
+
+	// Step 3: without a dispute window there is nothing to wait for
+	if rs.disputeWindow == 0 {
+		resolution, err = rs.finalizeResolution(resolution)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rs.mapMarketResolutionToResponse(resolution, nil), nil
+}
+
+/*
+*
+A holder disputes the proposed outcome. The request is signed with the holder's Hedera key and the account's EVM address
+(looked up on the mirror node) must hold YES or NO tokens on the market.
+*/
+func (rs *ResolutionsService) FileDispute(req *pb_api.FileResolutionDisputeRequest) (*pb_api.ResolutionDispute, error) {
+	// guards
+	resolution, err := rs.marketResolutionsRepository.GetMarketResolution(req.MarketId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get market resolution: %v", err)
+	}
+	if resolution == nil {
+		return nil, rs.log.Log(ERROR, "no outcome has been proposed for market %s", req.MarketId)
+	}
+	if resolution.Status != lib.MARKET_RESOLUTION_PROPOSED && resolution.Status != lib.MARKET_RESOLUTION_DISPUTED {
+		return nil, rs.log.Log(ERROR, "the resolution of market %s is already %s", req.MarketId, resolution.Status)
+	}
+	if !time.Now().Before(resolution.DisputeEndsAt) {
+		return nil, rs.log.Log(ERROR, "the dispute window for market %s closed at %s", req.MarketId, resolution.DisputeEndsAt.Format(time.RFC3339))
+	}
+
+	market, err := rs.marketsRepository.GetMarketById(req.MarketId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get market by id: %v", err)
+	}
+
+	// the public key sent must belong to the account on the market's network
+	accountId, err := hiero.AccountIDFromString(req.AccountId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "invalid account ID: %s", req.AccountId)
+	}
+	publicKeyLookedUp, _, err := rs.hederaService.GetPublicKey(accountId, market.Net)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get public key: %v", err)
+	}
+	publicKey, err := hiero.PublicKeyFromString(req.PublicKey)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to parse public key from string: %v", err)
+	}
+	if publicKeyLookedUp.String() != publicKey.String() || publicKey.String() == "" {
+		return nil, rs.log.Log(ERROR, "public key mismatch: expected %s, got %s", publicKeyLookedUp.String(), publicKey.String())
+	}
+
+	// now verify the signature over the dispute
+	isValidSig, err := lib.VerifySig(&publicKey, lib.Utf82hex(lib.AssembleResolutionDisputePayload(req)), req.Sig)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to verify signature: %v", err)
+	}
+	if !isValidSig {
+		return nil, rs.log.Log(ERROR, "invalid signature for account %s", req.AccountId)
+	}
+
+	// only holders can dispute
+	evmAddress, err := rs.hederaService.GetEvmAddress(accountId, market.Net)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get evm address: %v", err)
+	}
+	position, err := rs.positionsRepository.GetHolderPosition(market.MarketID, evmAddress)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get position: %v", err)
+	}
+	if position == nil {
+		return nil, rs.log.Log(ERROR, "account %s holds no position on market %s", req.AccountId, req.MarketId)
+	}
+
+	/////
+	// OK
+	/////
+	dispute, err := rs.marketResolutionsRepository.CreateResolutionDispute(market.MarketID, req.AccountId, position.EvmAddress, req.Evidence, req.PublicKey, req.KeyType, req.Sig)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to file dispute: %v", err)
+	}
+	if dispute == nil {
+		return nil, rs.log.Log(ERROR, "the dispute window for market %s is closed", req.MarketId)
+	}
+
+	rs.log.Log(INFO, "Resolution of market %s disputed by account %s", req.MarketId, req.AccountId)
+	return mapResolutionDisputeToResponse(dispute), nil
+}
+
+/*
+*
+ADMIN: uphold or overturn a disputed outcome, then resolve the market on-chain with the final outcome.
+If resolving the market fails (on-chain or on the db) the decision stands and the cron retries it - a market already resolved on-chain only gets the db step.
+*/
+func (rs *ResolutionsService) DecideResolution(marketId string, decision string, decidedBy string, reason string) (*pb_api.MarketResolution, error) {
+	// guards
+	resolution, err := rs.marketResolutionsRepository.GetMarketResolution(marketId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get market resolution: %v", err)
+	}
+	if resolution == nil {
+		return nil, rs.log.Log(ERROR, "no outcome has been proposed for market %s", marketId)
+	}
+	if resolution.Status != lib.MARKET_RESOLUTION_DISPUTED {
+		return nil, rs.log.Log(ERROR, "the resolution of market %s is %s - only disputed resolutions can be decided", marketId, resolution.Status)
+	}
+	if time.Now().Before(resolution.DisputeEndsAt) {
+		return nil, rs.log.Log(ERROR, "the dispute window for market %s is open until %s", marketId, resolution.DisputeEndsAt.Format(time.RFC3339))
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, rs.log.Log(ERROR, "a reason is required to decide a disputed resolution")
+	}
+
+	var finalOutcome bool
+	switch decision {
+	case lib.RESOLUTION_DECISION_UPHELD:
+		finalOutcome = resolution.ProposedOutcome
+	case lib.RESOLUTION_DECISION_OVERTURNED:
+		finalOutcome = !resolution.ProposedOutcome
+	default:
+		return nil, rs.log.Log(ERROR, "invalid decision: %s", decision)
+	}
+
+	/////
+	// OK
+	/////
+	// Step 1: record the decision - claims the resolution so a second ADMIN can not decide it too
+	resolution, err = rs.marketResolutionsRepository.DecideMarketResolution(resolution.MarketID, lib.MARKET_RESOLUTION_DISPUTED, decision, finalOutcome, decidedBy, reason)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to decide market resolution: %v", err)
+	}
+	if resolution == nil {
+		return nil, rs.log.Log(ERROR, "the resolution of market %s is no longer disputed", marketId)
+	}
+
+	// Step 2: resolve on-chain
+	_, err = rs.finalizeResolution(resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs.GetMarketResolution(marketId)
+}
+
+/*
+*
+Cron: finalise undisputed proposals whose dispute window has passed, and retry decided resolutions
+that failed to go on-chain.
+*/
+func (rs *ResolutionsService) FinalizeDueResolutions() {
+	resolutions, err := rs.marketResolutionsRepository.GetMarketResolutionsDueForFinalization()
+	if err != nil {
+		rs.log.Log(ERROR, "Failed to fetch market resolutions due for finalization: %v", err)
+		return
+	}
+
+	for _, resolution := range resolutions {
+		_, err = rs.finalizeResolution(&resolution)
+		if err != nil {
+			continue // already logged - retried on the next run
+		}
+	}
+}
+
+/*
+*
+Public: the proposed outcome, its disputes and the final decision
+*/
+func (rs *ResolutionsService) GetMarketResolution(marketId string) (*pb_api.MarketResolution, error) {
+	resolution, err := rs.marketResolutionsRepository.GetMarketResolution(marketId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get market resolution: %v", err)
+	}
+	if resolution == nil {
+		return nil, rs.log.Log(ERROR, "no outcome has been proposed for market %s", marketId)
+	}
+
+	disputes, err := rs.marketResolutionsRepository.GetResolutionDisputes(resolution.MarketID)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get resolution disputes: %v", err)
+	}
+
+	return rs.mapMarketResolutionToResponse(resolution, disputes), nil
+}
+
+// resolve a decided resolution on-chain (smart contract -> db -> CLOB) and mark it finalized
+func (rs *ResolutionsService) finalizeResolution(resolution *sqlc.MarketResolution) (*sqlc.MarketResolution, error) {
+	marketId := resolution.MarketID.String()
+
+	if resolution.Status == lib.MARKET_RESOLUTION_PROPOSED { // undisputed - the window has passed (or there is none)
+		decided, err := rs.marketResolutionsRepository.DecideMarketResolution(resolution.MarketID, lib.MARKET_RESOLUTION_PROPOSED, lib.RESOLUTION_DECISION_UNDISPUTED, resolution.ProposedOutcome, "", "")
+		if err != nil {
+			return nil, rs.log.Log(ERROR, "market %s: failed to decide undisputed resolution: %v", marketId, err)
+		}
+		if decided == nil {
+			return nil, rs.log.Log(ERROR, "market %s: resolution is no longer proposed", marketId)
+		}
+		resolution = decided
+	}
+
+	changedBy := resolution.DecidedBy.String
+	if changedBy == "" { // undisputed
+		changedBy = lib.MARKET_STATUS_CHANGED_BY_SYSTEM
+	}
+	_, err := rs.marketsService.ResolveMarket(marketId, resolution.FinalOutcome.Bool, changedBy)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "market %s: %s resolution (outcome=%t) not finalised yet: %v", marketId, resolution.Decision.String, resolution.FinalOutcome.Bool, err)
+	}
+
+	finalized, err := rs.marketResolutionsRepository.FinalizeMarketResolution(resolution.MarketID)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "market %s resolved but failed to finalize its resolution: %v", marketId, err)
+	}
+	if finalized == nil {
+		return nil, rs.log.Log(ERROR, "market %s resolved but its resolution is no longer decided", marketId)
+	}
+
+	rs.log.Log(INFO, "Finalized resolution of market %s (%s, outcome=%t)", marketId, finalized.Decision.String, finalized.FinalOutcome.Bool)
+	return finalized, nil
+}
+
+func (rs *ResolutionsService) mapMarketResolutionToResponse(resolution *sqlc.MarketResolution, disputes []sqlc.ResolutionDispute) *pb_api.MarketResolution {
+	response := &pb_api.MarketResolution{
+		MarketId:        resolution.MarketID.String(),
+		ProposedOutcome: resolution.ProposedOutcome,
+		ProposedBy:      resolution.ProposedBy,
+		ProposedAt:      resolution.ProposedAt.Format("2006-01-02T15:04:05Z"),
+		DisputeEndsAt:   resolution.DisputeEndsAt.Format("2006-01-02T15:04:05Z"),
+		Status:          resolution.Status,
+		Decision:        resolution.Decision.String,
+		DecidedBy:       resolution.DecidedBy.String,
+		DecisionReason:  resolution.DecisionReason.String,
+	}
+	if resolution.FinalOutcome.Valid {
+		response.FinalOutcome = &resolution.FinalOutcome.Bool
+	}
+	if resolution.DecidedAt.Valid {
+		response.DecidedAt = resolution.DecidedAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	if resolution.FinalizedAt.Valid {
+		response.FinalizedAt = resolution.FinalizedAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	for _, dispute := range disputes {
+		response.Disputes = append(response.Disputes, mapResolutionDisputeToResponse(&dispute))
+	}
+	return response
+}
+
+func mapResolutionDisputeToResponse(dispute *sqlc.ResolutionDispute) *pb_api.ResolutionDispute {
+	return &pb_api.ResolutionDispute{
+		MarketId:   dispute.MarketID.String(),
+		AccountId:  dispute.AccountID,
+		EvmAddress: dispute.EvmAddress,
+		Evidence:   dispute.Evidence,
+		CreatedAt:  dispute.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
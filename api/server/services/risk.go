@@ -0,0 +1,189 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	repositories "api/server/repositories"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	hiero "github.com/hiero-ledger/hiero-sdk-go/v2/sdk"
+)
+
+/*
+*
+Account-wide risk: the collateral (|priceUsd| * qty) an account has committed through its open prediction intents,
+summed across all of its markets and networks.
+USDC on one network can not back an intent on another, so the limits are per network:
+- the intents settled by each smart contract must be covered by the allowance the account gave that contract
+- all of the account's intents on the network must be covered by its USDC balance
+The same check runs at intake (CreatePredictionIntent, ReplacePredictionIntent) and in the cron (KickOutOrderIntentsNotBackedByFunds).
+*/
+type RiskService struct {
+	log                         *LogService
+	predictionIntentsRepository *repositories.PredictionIntentsRepository
+	hederaService               *HederaService
+}
+
+func (rs *RiskService) Init(log *LogService, predictionIntentsRepository *repositories.PredictionIntentsRepository, hederaService *HederaService) error {
+	rs.log = log
+	rs.predictionIntentsRepository = predictionIntentsRepository
+	rs.hederaService = hederaService
+
+	rs.log.Log(INFO, "Service: Risk service initialized successfully")
+	return nil
+}
+
+// collateral committed per network, and per smart contract on each network
+type accountExposure struct {
+	byNet      map[string]float64
+	byContract map[string]float64 // net + "/" + smartContractId
+}
+
+// the funds behind an account's exposure - each looked up on the mirror node once
+type accountFunds struct {
+	accountId    hiero.AccountID
+	balanceUsd   map[string]float64
+	allowanceUsd map[string]float64 // net + "/" + smartContractId
+}
+
+/*
+*
+Reject an intent the account can not fund on top of everything it already has open, on any market.
+replacesTxId is the open intent it replaces (its collateral is released), or "".
+*/
+func (rs *RiskService) CheckPredictionIntent(req *pb_api.PredictionIntentRequest, smartContractId string, replacesTxId string) error {
+	openIntents, err := rs.predictionIntentsRepository.GetAllOpenPredictionIntentsByAccountId(req.AccountId)
+	if err != nil {
+		return rs.log.Log(ERROR, "failed to get open prediction intents of account %s: %v", req.AccountId, err)
+	}
+
+	funds, err := newAccountFunds(req.AccountId)
+	if err != nil {
+		return rs.log.Log(ERROR, "%v", err)
+	}
+
+	exposure := &accountExposure{byNet: map[string]float64{}, byContract: map[string]float64{}}
+	for _, pi := range openIntents {
+		if strings.EqualFold(pi.TxID.String(), replacesTxId) {
+			continue
+		}
+		exposure.byNet[pi.Net] += math.Abs(pi.PriceUsd) * pi.Qty
+		exposure.byContract[pi.Net+"/"+pi.SmartContractID] += math.Abs(pi.PriceUsd) * pi.Qty
+	}
+
+	net := strings.ToLower(req.Net)
+	shortfall, err := rs.commit(funds, exposure, net, smartContractId, math.Abs(req.PriceUsd)*req.Qty)
+	if err != nil {
+		return rs.log.Log(ERROR, "failed to check the funds of account %s: %v", req.AccountId, err)
+	}
+	if shortfall != "" {
+		return rs.log.Log(ERROR, "account %s can not fund this predictionIntent on top of its %d open prediction intents: %s", req.AccountId, len(openIntents), shortfall)
+	}
+
+	rs.log.Log(INFO, "Account %s exposure on %s: $%.2f (balance $%.2f)", req.AccountId, net, exposure.byNet[net], funds.balanceUsd[net])
+	return nil
+}
+
+/*
+*
+The open intents of an account that its funds no longer cover. Intents are kept oldest first, so the newest are the ones to evict.
+Intents on a network whose funds can not be looked up are left alone (checked again on the next run).
+*/
+func (rs *RiskService) GetOverCommittedPredictionIntents(accountId string) ([]sqlc.GetAllOpenPredictionIntentsByAccountIdRow, error) {
+	openIntents, err := rs.predictionIntentsRepository.GetAllOpenPredictionIntentsByAccountId(accountId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "failed to get open prediction intents of account %s: %v", accountId, err)
+	}
+
+	funds, err := newAccountFunds(accountId)
+	if err != nil {
+		return nil, rs.log.Log(ERROR, "%v", err)
+	}
+
+	exposure := &accountExposure{byNet: map[string]float64{}, byContract: map[string]float64{}}
+	overCommitted := []sqlc.GetAllOpenPredictionIntentsByAccountIdRow{}
+	for _, pi := range openIntents {
+		shortfall, err := rs.commit(funds, exposure, pi.Net, pi.SmartContractID, math.Abs(pi.PriceUsd)*pi.Qty)
+		if err != nil {
+			rs.log.Log(ERROR, "failed to check the funds of account %s for txId %s: %v", accountId, pi.TxID.String(), err)
+			continue
+		}
+		if shortfall != "" {
+			rs.log.Log(WARN, "account %s is over-committed - txId %s on market %s: %s", accountId, pi.TxID.String(), pi.MarketID.String(), shortfall)
+			overCommitted = append(overCommitted, pi)
+		}
+	}
+
+	rs.log.Log(INFO, "Account %s: %d open prediction intents, %d over-committed, exposure by network: %v", accountId, len(openIntents), len(overCommitted), exposure.byNet)
+	return overCommitted, nil
+}
+
+// adds collateral to the exposure if the funds cover it - otherwise returns why not, and the exposure is unchanged
+func (rs *RiskService) commit(funds *accountFunds, exposure *accountExposure, net string, smartContractId string, collateral float64) (string, error) {
+	contractKey := net + "/" + smartContractId
+
+	allowanceUsd, ok := funds.allowanceUsd[contractKey]
+	if !ok {
+		ledgerId, err := hiero.LedgerIDFromString(net)
+		if err != nil {
+			return "", fmt.Errorf("invalid network %s: %v", net, err)
+		}
+		contractId, err := hiero.ContractIDFromString(smartContractId)
+		if err != nil {
+			return "", fmt.Errorf("invalid smart contract ID %s: %v", smartContractId, err)
+		}
+		usdcAddress, err := hiero.ContractIDFromString(os.Getenv(fmt.Sprintf("%s_USDC_ADDRESS", strings.ToUpper(net))))
+		if err != nil {
+			return "", fmt.Errorf("failed to validate %s_USDC_ADDRESS: %v", strings.ToUpper(net), err)
+		}
+		usdcDecimals, err := strconv.ParseUint(os.Getenv("USDC_DECIMALS"), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse USDC_DECIMALS: %v", err)
+		}
+		allowanceUsd, err = rs.hederaService.GetSpenderAllowanceUsd(*ledgerId, funds.accountId, contractId, usdcAddress, usdcDecimals)
+		if err != nil {
+			return "", fmt.Errorf("failed to get spender allowance: %v", err)
+		}
+		funds.allowanceUsd[contractKey] = allowanceUsd
+	}
+
+	balanceUsd, ok := funds.balanceUsd[net]
+	if !ok {
+		ledgerId, err := hiero.LedgerIDFromString(net)
+		if err != nil {
+			return "", fmt.Errorf("invalid network %s: %v", net, err)
+		}
+		balanceUsd, err = rs.hederaService.GetUsdcBalanceUsd(*ledgerId, funds.accountId)
+		if err != nil {
+			return "", fmt.Errorf("failed to get USDC balance: %v", err)
+		}
+		funds.balanceUsd[net] = balanceUsd
+	}
+
+	if exposure.byContract[contractKey]+collateral > allowanceUsd {
+		return fmt.Sprintf("$%.2f committed to smart contract %s on %s (with this $%.2f) exceeds the allowance of $%.2f", exposure.byContract[contractKey]+collateral, smartContractId, net, collateral, allowanceUsd), nil
+	}
+	if exposure.byNet[net]+collateral > balanceUsd {
+		return fmt.Sprintf("$%.2f committed on %s (with this $%.2f) exceeds the USDC balance of $%.2f", exposure.byNet[net]+collateral, net, collateral, balanceUsd), nil
+	}
+
+	exposure.byContract[contractKey] += collateral
+	exposure.byNet[net] += collateral
+	return "", nil
+}
+
+func newAccountFunds(accountIdStr string) (*accountFunds, error) {
+	accountId, err := hiero.AccountIDFromString(accountIdStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accountId %s: %v", accountIdStr, err)
+	}
+	return &accountFunds{
+		accountId:    accountId,
+		balanceUsd:   map[string]float64{},
+		allowanceUsd: map[string]float64{},
+	}, nil
+}
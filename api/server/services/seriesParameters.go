@@ -0,0 +1,117 @@
+package services
+
+import (
+	"api/server/lib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+/*
+*
+A ParameterGenerator produces the parameters of the next market of a recurring series (see MarketSeriesService).
+They are available to the template as .Params - e.g. {{.Params.threshold}}.
+*/
+type ParameterGenerator interface {
+	GenerateParams(ctx context.Context, closesAt time.Time) (map[string]interface{}, error)
+}
+
+/*
+*
+Build the parameter generator referenced by a series from its type and JSON config.
+Also used to validate a config before it is stored against a series.
+*/
+func NewParameterGenerator(generatorType string, config []byte) (ParameterGenerator, error) {
+	switch generatorType {
+	case lib.SERIES_PARAMETERS_STATIC:
+		var generator StaticParameterGenerator
+		if err := json.Unmarshal(config, &generator); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", generatorType, err)
+		}
+		return &generator, nil
+
+	case lib.SERIES_PARAMETERS_HTTP_JSON_PRICE:
+		var generator HttpJsonPriceParameterGenerator
+		if err := json.Unmarshal(config, &generator); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", generatorType, err)
+		}
+		if generator.Url == "" || generator.JsonPath == "" || generator.Param == "" {
+			return nil, fmt.Errorf("invalid %s config: url, jsonPath and param are required", generatorType)
+		}
+		if generator.RoundTo < 0 {
+			return nil, fmt.Errorf("invalid %s config: roundTo must not be negative", generatorType)
+		}
+		if err := checkResolutionSourceUrl(generator.Url); err != nil {
+			return nil, fmt.Errorf("invalid %s config: %v", generatorType, err)
+		}
+		return &generator, nil
+
+	default:
+		return nil, fmt.Errorf("unknown parameter generator: %s", generatorType)
+	}
+}
+
+/////
+// static
+/////
+
+// the same parameters for every market, e.g. {"params": {"team": "Arsenal"}}
+type StaticParameterGenerator struct {
+	Params map[string]interface{} `json:"params"`
+}
+
+func (g *StaticParameterGenerator) GenerateParams(ctx context.Context, closesAt time.Time) (map[string]interface{}, error) {
+	params := make(map[string]interface{}, len(g.Params))
+	for k, v := range g.Params {
+		params[k] = v
+	}
+	return params, nil
+}
+
+/////
+// http_json_price
+/////
+
+/*
+*
+The current price read from an HTTP JSON endpoint, rounded, plus any static params, e.g.
+{"url": "https://api.example.com/price?symbol=HBAR", "jsonPath": "$.price", "param": "threshold", "roundTo": 0.01}
+gives {"threshold": 0.27} for "Will HBAR close above ${{.Params.threshold}} on Friday?"
+*/
+type HttpJsonPriceParameterGenerator struct {
+	Url      string                 `json:"url"`
+	JsonPath string                 `json:"jsonPath"`
+	Param    string                 `json:"param"`
+	RoundTo  float64                `json:"roundTo,omitempty"` // e.g. 0.01 - not rounded if 0
+	Params   map[string]interface{} `json:"params,omitempty"`
+}
+
+func (g *HttpJsonPriceParameterGenerator) GenerateParams(ctx context.Context, closesAt time.Time) (map[string]interface{}, error) {
+	value, err := fetchJsonPath(ctx, nil, g.Url, g.JsonPath)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("no price at %s in %s", g.JsonPath, g.Url)
+	}
+
+	price, err := strconv.ParseFloat(jsonScalarToString(value), 64)
+	if err != nil {
+		return nil, fmt.Errorf("price at %s is not a number: %v", g.JsonPath, value)
+	}
+	if g.RoundTo > 0 {
+		// round, then drop float noise (0.27000000000000002 => 0.27) so the statement reads well
+		decimals := max(0, int(-math.Floor(math.Log10(g.RoundTo))))
+		price, _ = strconv.ParseFloat(strconv.FormatFloat(math.Round(price/g.RoundTo)*g.RoundTo, 'f', decimals, 64), 64)
+	}
+
+	params := make(map[string]interface{}, len(g.Params)+1)
+	for k, v := range g.Params {
+		params[k] = v
+	}
+	params[g.Param] = price
+	return params, nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	pb_api "api/gen"
+	sqlc "api/gen/sqlc"
+	"api/server/lib"
+	repositories "api/server/repositories"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TradingRulesService struct {
+	log                    *LogService
+	tradingRulesRepository *repositories.TradingRulesRepository
+	marketsRepository      *repositories.MarketsRepository
+}
+
+func (trs *TradingRulesService) Init(log *LogService, tradingRulesRepository *repositories.TradingRulesRepository, marketsRepository *repositories.MarketsRepository) error {
+	trs.log = log
+	trs.tradingRulesRepository = tradingRulesRepository
+	trs.marketsRepository = marketsRepository
+
+	trs.log.Log(INFO, "Service: TradingRules service initialized successfully")
+	return nil
+}
+
+/*
+*
+The rules an intent on marketId must follow: the market's own, else its network's,
+else the defaults (MIN_ORDER_SIZE_USD and lib.TRADING_RULES_DEFAULT_*)
+*/
+func (trs *TradingRulesService) GetTradingRules(net string, marketId string) (*pb_api.TradingRules, error) {
+	tradingRules, err := trs.tradingRulesRepository.GetTradingRules(strings.ToLower(net), marketId)
+	if err != nil {
+		return nil, trs.log.Log(ERROR, "failed to get trading rules (net=%s, marketId=%s): %v", net, marketId, err)
+	}
+	if tradingRules == nil {
+		return defaultTradingRules(strings.ToLower(net)), nil
+	}
+	return mapTradingRules(tradingRules), nil
+}
+
+// the rules of each network - a market may override them
+func (trs *TradingRulesService) GetNetworkTradingRules(networks []string) (map[string]*pb_api.TradingRules, error) {
+	rows, err := trs.tradingRulesRepository.GetAllNetworkTradingRules()
+	if err != nil {
+		return nil, trs.log.Log(ERROR, "failed to get network trading rules: %v", err)
+	}
+
+	tradingRulesByNet := make(map[string]*pb_api.TradingRules)
+	for _, row := range rows {
+		tradingRulesByNet[row.Net] = mapTradingRules(&row)
+	}
+	for _, net := range networks {
+		netLower := strings.ToLower(strings.TrimSpace(net))
+		if _, ok := tradingRulesByNet[netLower]; !ok && netLower != "" {
+			tradingRulesByNet[netLower] = defaultTradingRules(netLower)
+		}
+	}
+	return tradingRulesByNet, nil
+}
+
+/*
+*
+Reject an intent that breaks the trading rules of its market - INVALID_ARGUMENT, the message prefixed with the rule's code
+(lib.TRADING_RULE_*) so clients can tell them apart
+*/
+func (trs *TradingRulesService) CheckPredictionIntent(req *pb_api.PredictionIntentRequest) error {
+	tradingRules, err := trs.GetTradingRules(req.Net, req.MarketId)
+	if err != nil {
+		return err
+	}
+
+	priceUsd := math.Abs(req.PriceUsd)
+	notionalUsd := priceUsd * req.Qty
+
+	var code, reason string
+	switch {
+	case !isMultipleOf(priceUsd, tradingRules.PriceTick):
+		code, reason = lib.TRADING_RULE_PRICE_TICK, "priceUsd "+formatFloat(req.PriceUsd)+" is not a multiple of the price tick "+formatFloat(tradingRules.PriceTick)
+	case !isMultipleOf(req.Qty, tradingRules.QtyStep):
+		code, reason = lib.TRADING_RULE_QTY_STEP, "qty "+formatFloat(req.Qty)+" is not a multiple of the qty step "+formatFloat(tradingRules.QtyStep)
+	case notionalUsd < tradingRules.MinNotionalUsd:
+		code, reason = lib.TRADING_RULE_MIN_NOTIONAL, "notional $"+formatFloat(notionalUsd)+" is below the minimum of $"+formatFloat(tradingRules.MinNotionalUsd)
+	case tradingRules.MaxNotionalUsd > 0 && notionalUsd > tradingRules.MaxNotionalUsd:
+		code, reason = lib.TRADING_RULE_MAX_NOTIONAL, "notional $"+formatFloat(notionalUsd)+" is above the maximum of $"+formatFloat(tradingRules.MaxNotionalUsd)
+	default:
+		return nil
+	}
+
+	trs.log.Log(ERROR, "%s: %s (txId=%s, marketId=%s, rules=%s)", code, reason, req.TxId, req.MarketId, tradingRules.Source)
+	return status.Errorf(codes.InvalidArgument, "%s: %s", code, reason)
+}
+
+/*
+*
+ADMIN: set the trading rules of a network, or of one market (overriding its network's)
+*/
+func (trs *TradingRulesService) SetTradingRules(req *pb_api.SetTradingRulesRequest, accountId string) (*pb_api.TradingRules, error) {
+	// guards
+	net := strings.ToLower(req.Net)
+	if req.MaxNotionalUsd != nil && req.GetMaxNotionalUsd() < req.MinNotionalUsd {
+		return nil, trs.log.Log(ERROR, "maxNotionalUsd %f is below minNotionalUsd %f", req.GetMaxNotionalUsd(), req.MinNotionalUsd)
+	}
+	if req.MarketId != nil {
+		market, err := trs.marketsRepository.GetMarketById(req.GetMarketId())
+		if err != nil {
+			return nil, trs.log.Log(ERROR, "failed to get market by id %s: %v", req.GetMarketId(), err)
+		}
+		if market.Net != net {
+			return nil, trs.log.Log(ERROR, "market %s is on %s, not %s", req.GetMarketId(), market.Net, net)
+		}
+	}
+
+	/////
+	// OK
+	/////
+	tradingRules, err := trs.tradingRulesRepository.UpsertTradingRules(net, strings.ToLower(req.GetMarketId()), req.MinNotionalUsd, req.MaxNotionalUsd, req.PriceTick, req.QtyStep, accountId)
+	if err != nil {
+		return nil, trs.log.Log(ERROR, "failed to set trading rules (net=%s, marketId=%s): %v", net, req.GetMarketId(), err)
+	}
+
+	trs.log.Log(INFO, "Trading rules set by %s (net=%s, marketId=%s): minNotionalUsd=%f, maxNotionalUsd=%v, priceTick=%f, qtyStep=%f", accountId, net, req.GetMarketId(), req.MinNotionalUsd, req.MaxNotionalUsd, req.PriceTick, req.QtyStep)
+	return mapTradingRules(tradingRules), nil
+}
+
+func mapTradingRules(tradingRules *sqlc.TradingRule) *pb_api.TradingRules {
+	response := &pb_api.TradingRules{
+		Net:            tradingRules.Net,
+		MinNotionalUsd: tradingRules.MinNotionalUsd,
+		MaxNotionalUsd: tradingRules.MaxNotionalUsd.Float64, // 0 => no maximum
+		PriceTick:      tradingRules.PriceTick,
+		QtyStep:        tradingRules.QtyStep,
+		Source:         lib.TRADING_RULES_SOURCE_NETWORK,
+	}
+	if tradingRules.MarketID.Valid {
+		response.MarketId = tradingRules.MarketID.UUID.String()
+		response.Source = lib.TRADING_RULES_SOURCE_MARKET
+	}
+	return response
+}
+
+func defaultTradingRules(net string) *pb_api.TradingRules {
+	minOrderSizeUsd, _ := strconv.ParseFloat(os.Getenv("MIN_ORDER_SIZE_USD"), 64) // a required env var (see main.go)
+	return &pb_api.TradingRules{
+		Net:            net,
+		MinNotionalUsd: minOrderSizeUsd,
+		PriceTick:      lib.TRADING_RULES_DEFAULT_PRICE_TICK,
+		QtyStep:        lib.TRADING_RULES_DEFAULT_QTY_STEP,
+		Source:         lib.TRADING_RULES_SOURCE_DEFAULT,
+	}
+}
+
+// doubles - allow for float error relative to the step (0.3 is not exactly 3 * 0.1)
+func isMultipleOf(value float64, step float64) bool {
+	n := value / step
+	return math.Abs(n-math.Round(n)) < 1e-6
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
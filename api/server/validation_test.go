@@ -0,0 +1,113 @@
+package main
+
+import (
+	pb_api "api/gen"
+	"strings"
+	"testing"
+)
+
+// the PGV rules of CreateMarketv2Request that guard the image inputs
+func TestCreateMarketv2RequestImageValidation(t *testing.T) {
+	valid := func() *pb_api.CreateMarketv2Request {
+		return &pb_api.CreateMarketv2Request{
+			MarketId:    "01920000-0000-7000-8000-000000000000",
+			Net:         "testnet",
+			Statement:   "Will it rain in London tomorrow?",
+			ImgChunk:    []byte{0x89, 'P', 'N', 'G'},
+			ImgFileName: "rain.png",
+			ImgMimeType: "image/png",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(req *pb_api.CreateMarketv2Request)
+		wantErr string // "" => valid
+	}{
+		{"png", func(req *pb_api.CreateMarketv2Request) {}, ""},
+		{"jpeg", func(req *pb_api.CreateMarketv2Request) { req.ImgMimeType = "image/jpeg" }, ""},
+		{"gif", func(req *pb_api.CreateMarketv2Request) { req.ImgMimeType = "image/gif" }, ""},
+		{"svg", func(req *pb_api.CreateMarketv2Request) { req.ImgMimeType = "image/svg+xml" }, "ImgMimeType"},
+		{"webp", func(req *pb_api.CreateMarketv2Request) { req.ImgMimeType = "image/webp" }, "ImgMimeType"},
+		{"chunk of 5MB", func(req *pb_api.CreateMarketv2Request) { req.ImgChunk = make([]byte, 5<<20) }, ""},
+		{"chunk over 5MB", func(req *pb_api.CreateMarketv2Request) { req.ImgChunk = make([]byte, 5<<20+1) }, "ImgChunk"},
+		{"file name too long", func(req *pb_api.CreateMarketv2Request) { req.ImgFileName = strings.Repeat("a", 256) }, "ImgFileName"},
+		{"upload id", func(req *pb_api.CreateMarketv2Request) {
+			req.ImgChunk = nil
+			req.ImgUploadId = "01920000-0000-7000-8000-000000000001"
+		}, ""},
+		{"upload id only", func(req *pb_api.CreateMarketv2Request) {
+			req.ImgChunk = nil
+			req.ImgFileName = ""
+			req.ImgMimeType = ""
+			req.ImgUploadId = "01920000-0000-7000-8000-000000000001"
+		}, ""},
+		{"invalid upload id", func(req *pb_api.CreateMarketv2Request) {
+			req.ImgChunk = nil
+			req.ImgUploadId = "not-an-upload-id"
+		}, "ImgUploadId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+
+			err := req.ValidateAll()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateAll() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateAll() error = %v, want one about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// the CLOB's qty tolerance assumes no qty step is finer than USDC's 6 decimals
+func TestSetTradingRulesRequestQtyStepValidation(t *testing.T) {
+	tests := []struct {
+		qtyStep float64
+		wantErr bool
+	}{
+		{1, false},
+		{0.01, false},
+		{0.000001, false},
+		{0.0000001, true},
+		{0, true},
+	}
+
+	for _, tt := range tests {
+		req := &pb_api.SetTradingRulesRequest{Net: "testnet", PriceTick: 0.001, QtyStep: tt.qtyStep}
+		err := req.ValidateAll()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("qtyStep=%g: ValidateAll() error = %v, wantErr %t", tt.qtyStep, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetMarketsRequestCategoryIdValidation(t *testing.T) {
+	categoryId := func(id int32) *int32 { return &id }
+
+	tests := []struct {
+		name       string
+		categoryId *int32
+		wantErr    bool
+	}{
+		{"no category", nil, false},
+		{"category", categoryId(3), false},
+		{"category 0", categoryId(0), true},
+		{"negative category", categoryId(-1), true},
+	}
+
+	for _, tt := range tests {
+		req := &pb_api.GetMarketsRequest{Limit: 10, CategoryId: tt.categoryId}
+		err := req.ValidateAll()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateAll() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -2,6 +2,8 @@ fn main() -> Result<(), Box<dyn std::error::Error>> {
    tonic_prost_build::configure()
         .build_server(true)
         .type_attribute(".", "#[derive(serde::Serialize, serde::Deserialize)]")
+        .field_attribute("clob.CreateOrderRequestClob.time_in_force", "#[serde(default)]") // orders published before time_in_force existed are GTC
+        .field_attribute("clob.CreateOrderRequestClob.expires_at_ms", "#[serde(default)]") // omitted (omitempty) for good-till-cancelled orders
         // .out_dir("src/gen")
         .compile_protos(
             &["proto/api.proto", "proto/clob.proto"],
@@ -1,3 +1,12 @@
 pub const CLOB_ORDERS: &str = "clob.orders";
 pub const CLOB_MATCHES_FULL: &str = "clob.matches.full";
 pub const CLOB_MATCHES_PARTIAL: &str = "clob.matches.partial";
+
+// CreateOrderRequestClob.time_in_force - empty is GTC
+pub const TIME_IN_FORCE_GTC: &str = "GTC"; // rests on the book until filled or cancelled
+pub const TIME_IN_FORCE_IOC: &str = "IOC"; // fills what it can now, the remainder is cancelled
+pub const TIME_IN_FORCE_FOK: &str = "FOK"; // fills in full now or not at all
+
+// qty comparisons only absorb floating point error - kept far below the smallest qty step the API allows (0.000001, USDC has 6 decimals),
+// so an order short by even one step is never treated as filled
+pub const QTY_EPSILON: f64 = 1e-9;
@@ -32,17 +32,27 @@ impl ClobInternal for ClobService {
     async fn create_order(
         &self,
         request: Request<CreateOrderRequestClob>,
-    ) -> Result<Response<StdResponse>, Status> {
+    ) -> Result<Response<crate::orderbook::proto::CreateOrderResponse>, Status> {
         let order = request.into_inner();
         
-        let result = self.order_book_service.place_order(order).await;
-        if let Err(e) = result {
-            log::error!("Failed to place order: {}", e);
-            return Err(Status::internal(e.to_string()));
+        if self.order_book_service.order_exists(&order.tx_id).await {
+            log::warn!("Duplicate order txId detected: {}. Order not entered into the orderbook.", order.tx_id);
+            return Err(Status::already_exists(format!("WARN: order {} already exists", order.tx_id)));
         }
-        let response = StdResponse {
+
+        let outcome = match self.order_book_service.place_order(order).await {
+            Ok(outcome) => outcome,
+            Err(e) => {
+                log::error!("Failed to place order: {}", e);
+                return Err(Status::internal(e.to_string()));
+            }
+        };
+        let response = crate::orderbook::proto::CreateOrderResponse {
             message: "success".to_string(),
             error_code: 0,
+            filled_qty: outcome.filled_qty,
+            remaining_qty: outcome.remaining_qty,
+            is_resting: outcome.is_resting,
         };
 
         Ok(Response::new(response))
@@ -60,6 +70,11 @@ impl ClobInternal for ClobService {
         
         match result {
             Ok(success) if success => (),
+            Ok(_) => {
+                // distinct status so callers can treat a retried creation as done
+                log::warn!("Market {} already exists", inner.market_id);
+                return Err(Status::already_exists(format!("WARN: market {} already exists", inner.market_id)));
+            }
             _ => {
                 log::error!("Failed to add market");
                 return Err(Status::internal(format!("WARN: could not add market {}. Does the market already exist?", inner.market_id)));
@@ -73,6 +88,56 @@ impl ClobInternal for ClobService {
         Ok(Response::new(response))
     }
 
+    async fn delete_market(
+        &self,
+        request: Request<crate::orderbook::proto::MarketIdRequest>,
+    ) -> Result<Response<crate::orderbook::proto::StdResponse>, Status> {
+        let inner = request.into_inner();
+
+        let result = self.order_book_service.remove_market(&inner.market_id).await;
+
+        match result {
+            Ok(success) if success => (),
+            Ok(_) => {
+                log::error!("Failed to remove market: not found");
+                return Err(Status::not_found(format!("WARN: could not remove market {}. Does the market exist?", inner.market_id)));
+            }
+            Err(e) => {
+                log::error!("Failed to remove market: {}", e);
+                return Err(Status::internal(format!("WARN: could not remove market {}: {}", inner.market_id, e)));
+            }
+        }
+        let response = crate::orderbook::proto::StdResponse {
+            message: "success".to_string(),
+            error_code: 0,
+        };
+
+        Ok(Response::new(response))
+    }
+
+    async fn pause_market(
+        &self,
+        request: Request<crate::orderbook::proto::PauseMarketRequest>,
+    ) -> Result<Response<crate::orderbook::proto::StdResponse>, Status> {
+        let inner = request.into_inner();
+
+        let result = self.order_book_service.set_market_paused(&inner.market_id, inner.is_paused).await;
+
+        match result {
+            Ok(success) if success => (),
+            _ => {
+                log::error!("Failed to set is_paused on market");
+                return Err(Status::internal(format!("WARN: could not set is_paused={} for market {}. Does the market exist?", inner.is_paused, inner.market_id)));
+            }
+        }
+        let response = crate::orderbook::proto::StdResponse {
+            message: "success".to_string(),
+            error_code: 0,
+        };
+
+        Ok(Response::new(response))
+    }
+
     async fn cancel_order(
         &self,
         request: Request<crate::orderbook::proto::CancelOrderRequest>,
@@ -96,6 +161,63 @@ impl ClobInternal for ClobService {
         Ok(Response::new(response))
     }
 
+    async fn cancel_orders(
+        &self,
+        request: Request<crate::orderbook::proto::CancelOrdersRequest>,
+    ) -> Result<Response<crate::orderbook::proto::CancelOrdersResponse>, Status> {
+        let inner = request.into_inner();
+
+        let result = self.order_book_service.cancel_orders(&inner.orders).await;
+
+        match result {
+            Ok(cancelled_tx_ids) => {
+                let response = crate::orderbook::proto::CancelOrdersResponse {
+                    cancelled_tx_ids,
+                };
+                Ok(Response::new(response))
+            },
+            Err(e) => {
+                log::error!("Failed to cancel {} orders: {}", inner.orders.len(), e);
+                Err(Status::internal(e.to_string()))
+            }
+        }
+    }
+
+    async fn replace_order(
+        &self,
+        request: Request<crate::orderbook::proto::ReplaceOrderRequest>,
+    ) -> Result<Response<crate::orderbook::proto::CreateOrderResponse>, Status> {
+        let inner = request.into_inner();
+        let Some(order) = inner.order else {
+            return Err(Status::invalid_argument("order is required"));
+        };
+
+        if self.order_book_service.order_exists(&order.tx_id).await {
+            log::warn!("Duplicate order txId detected: {}. Order {} not replaced.", order.tx_id, inner.cancel_tx_id);
+            return Err(Status::already_exists(format!("WARN: order {} already exists", order.tx_id)));
+        }
+
+        let outcome = match self.order_book_service.replace_order(&inner.cancel_tx_id, order).await {
+            Ok(Some(outcome)) => outcome,
+            Ok(None) => {
+                return Err(Status::failed_precondition(format!("WARN: order {} is no longer on the book (filled or cancelled)", inner.cancel_tx_id)));
+            }
+            Err(e) => {
+                log::error!("Failed to replace order {}: {}", inner.cancel_tx_id, e);
+                return Err(Status::internal(e.to_string()));
+            }
+        };
+        let response = crate::orderbook::proto::CreateOrderResponse {
+            message: "success".to_string(),
+            error_code: 0,
+            filled_qty: outcome.filled_qty,
+            remaining_qty: outcome.remaining_qty,
+            is_resting: outcome.is_resting,
+        };
+
+        Ok(Response::new(response))
+    }
+
     async fn get_orders_for_user(
         &self,
         request: Request<crate::orderbook::proto::UserRequest>,
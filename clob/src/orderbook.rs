@@ -10,9 +10,17 @@ static TX_ID_LUT: Lazy<Mutex<HashSet<String>>> = Lazy::new(|| Mutex::new(HashSet
 pub mod proto {
     tonic::include_proto!("clob");
 }
-use proto::{CreateOrderRequestClob, BookSnapshot, OrderDetail};
+use proto::{CreateOrderRequestClob, CancelOrderRequest, BookSnapshot, OrderDetail};
 
-use crate::{nats};
+use crate::{constants, nats};
+
+// what happened to an order when it was placed
+#[derive(Debug, Clone, Copy)]
+pub struct OrderOutcome {
+    pub filled_qty: f64,
+    pub remaining_qty: f64,
+    pub is_resting: bool, // false => the remaining qty was cancelled (IOC/FOK)
+}
 
 #[derive(Debug, Clone)]
 pub struct OrderBookService {
@@ -46,35 +54,59 @@ impl OrderBookService {
         return Ok(true);
     }
 
-    // pub async fn remove_market(&self, market_id: &str) {
-    // // Guards
-    // if !is_valid_market_id(market_id.clone()) {
-    //     log::error!("Invalid market_id format: {}", market_id);
-    //     return;
-    // }
-    //     let mut order_books = self.order_books.write().await;
-    //     order_books.remove(market_id);
-    // }
+    pub async fn remove_market(&self, market_id: &str) -> Result<bool, Box<dyn std::error::Error>> {
+        // No guards for performance - assume validated upstream
+
+        let mut order_books = self.order_books.write().await;
+        if order_books.remove(&market_id.to_lowercase()).is_none() {
+            log::warn!("WARN: Attempt to remove a market ({}) which does not exist in OrderBookService", market_id.to_lowercase());
+            return Ok(false);
+        }
+
+        log::info!("Market \"{}\" removed from OrderBookService", market_id.to_lowercase());
+        return Ok(true);
+    }
+
+    pub async fn set_market_paused(&self, market_id: &str, is_paused: bool) -> Result<bool, Box<dyn std::error::Error>> {
+        // No guards for performance - assume validated upstream
+
+        let order_books = self.order_books.read().await;
+        if let Some(order_book) = order_books.get(&market_id.to_lowercase()) {
+            let mut book = order_book.write().await;
+            book.is_paused = is_paused;
+
+            log::info!("Market \"{}\" is_paused = {}", market_id.to_lowercase(), is_paused);
+            Ok(true)
+        } else {
+            Err("Market not found".into())
+        }
+    }
 
     pub async fn order_exists(&self, tx_id: &str) -> bool {
         let lut = TX_ID_LUT.lock().unwrap();
         lut.contains(tx_id)
     }
 
-    pub async fn place_order(&self, order: CreateOrderRequestClob) -> Result<(), Box<dyn std::error::Error>> {
+    pub async fn place_order(&self, order: CreateOrderRequestClob) -> Result<OrderOutcome, Box<dyn std::error::Error>> {
         // No guards for performance - assume validated upstream
         let order_books = self.order_books.read().await;
         if let Some(order_book) = order_books.get(&order.market_id.to_lowercase()) {
             let tx_id = order.tx_id.clone();
             let mut book = order_book.write().await;
-            book.add_order(order).await;
+
+            // paused markets don't match (or accept) orders
+            if book.is_paused {
+                log::warn!("Market {} is paused. Order {} not entered into the orderbook.", order.market_id, tx_id);
+                return Err("Market is paused".into());
+            }
+            let outcome = book.add_order(order).await;
 
             // Add tx_id to the LUT to avoid duplicate tx_ids
             let mut lut = TX_ID_LUT.lock().unwrap();
             lut.insert(tx_id);
 
             // return OK
-            Ok(())
+            Ok(outcome)
         } else {
             Err("Market not found".into())
         }
@@ -131,13 +163,12 @@ impl OrderBookService {
             //     (None, None) => 0.5, // No orders
             // };
 
-            let best_bid = best_bid.unwrap_or(0.5);
-            let best_ask = best_ask.unwrap_or(0.5);
-
             let price_update = proto::PriceUpdate {
-                price_bid_usd: best_bid,
-                price_ask_usd: best_ask,
+                price_bid_usd: best_bid.unwrap_or(0.5),
+                price_ask_usd: best_ask.unwrap_or(0.5),
                 timestamp_ms: chrono::Utc::now().timestamp_millis(),
+                has_bid: best_bid.is_some(),
+                has_ask: best_ask.is_some(),
             };
 
             Ok(price_update)
@@ -176,6 +207,83 @@ impl OrderBookService {
         }
     }
 
+    pub async fn cancel_orders(&self, orders: &[CancelOrderRequest]) -> Result<Vec<String>, Box<dyn std::error::Error>> {
+        // No guards for performance - assume validated upstream
+
+        // group by market so each book is write-locked once
+        let mut tx_ids_by_market: HashMap<String, HashSet<String>> = HashMap::new();
+        for order in orders {
+            tx_ids_by_market.entry(order.market_id.to_lowercase()).or_default().insert(order.tx_id.to_lowercase());
+        }
+
+        let order_books = self.order_books.read().await;
+        let mut cancelled_tx_ids = Vec::new();
+        for (market_id, tx_ids) in tx_ids_by_market.iter() {
+            let Some(order_book) = order_books.get(market_id) else {
+                log::warn!("Market {} not found - skipping {} cancels", market_id, tx_ids.len());
+                continue;
+            };
+
+            let mut guard = order_book.write().await;
+            let book = &mut *guard;
+            for side in [&mut book.buy_orders, &mut book.sell_orders] {
+                side.retain(|o| {
+                    if tx_ids.contains(&o.tx_id.to_lowercase()) {
+                        cancelled_tx_ids.push(o.tx_id.clone());
+                        return false;
+                    }
+                    true
+                });
+            }
+        }
+
+        log::info!("Cancelled {} of {} orders", cancelled_tx_ids.len(), orders.len());
+        Ok(cancelled_tx_ids)
+    }
+
+    // returns None (and nothing changes) if cancel_tx_id is no longer on the book - e.g. it has already filled
+    pub async fn replace_order(&self, cancel_tx_id: &str, order: CreateOrderRequestClob) -> Result<Option<OrderOutcome>, Box<dyn std::error::Error>> {
+        // No guards for performance - assume validated upstream
+
+        let order_books = self.order_books.read().await;
+        let Some(order_book) = order_books.get(&order.market_id.to_lowercase()) else {
+            return Err("Market not found".into());
+        };
+
+        // cancel and create under the one write lock - nothing can match the old order in between
+        let mut guard = order_book.write().await;
+        let book = &mut *guard;
+
+        // paused markets don't match (or accept) orders
+        if book.is_paused {
+            log::warn!("Market {} is paused. Order {} not replaced.", order.market_id, cancel_tx_id);
+            return Err("Market is paused".into());
+        }
+
+        let mut is_cancelled = false;
+        for side in [&mut book.buy_orders, &mut book.sell_orders] {
+            if let Some(pos) = side.iter().position(|o| o.tx_id.eq_ignore_ascii_case(cancel_tx_id)) {
+                side.remove(pos);
+                is_cancelled = true;
+                break;
+            }
+        }
+        if !is_cancelled {
+            log::warn!("Order with tx_id {} not found in market {} - not replaced", cancel_tx_id, order.market_id);
+            return Ok(None);
+        }
+
+        let tx_id = order.tx_id.clone();
+        log::info!("Order with tx_id {} replaced by {} in market {}", cancel_tx_id, tx_id, order.market_id);
+        let outcome = book.add_order(order).await;
+
+        // Add tx_id to the LUT to avoid duplicate tx_ids
+        let mut lut = TX_ID_LUT.lock().unwrap();
+        lut.insert(tx_id);
+
+        Ok(Some(outcome))
+    }
+
     pub async fn get_orders_for_user(&self, evm_address: &str) -> Result<Vec<CreateOrderRequestClob>, Box<dyn std::error::Error>> {
         // No guards for performance - assume validated upstream
 
@@ -208,6 +316,7 @@ impl OrderBookService {
 pub struct OrderBook {
     buy_orders: Vec<CreateOrderRequestClob>,
     sell_orders: Vec<CreateOrderRequestClob>,
+    is_paused: bool,
     nats_service: Arc<nats::NatsService> // wrap in arc to make cloning cheap
 }
 
@@ -216,39 +325,89 @@ impl OrderBook {
         Self {
             buy_orders: Vec::new(),
             sell_orders: Vec::new(),
+            is_paused: false,
             nats_service: Arc::new(nats_service.clone()),
         }
     }
 
-    pub async fn add_order(&mut self, order: CreateOrderRequestClob) {
+    pub async fn add_order(&mut self, order: CreateOrderRequestClob) -> OrderOutcome {
         // No guards for performance - assume validated upstream
 
         log::info!("CREATE \t CreateOrderRequestClob: {:?}", order); // Log the incoming order
 
+        // good-till-time: an expired order never matches - resting ones are dropped here, ahead of the API's cron cancelling them
+        let now_ms = chrono::Utc::now().timestamp_millis();
+        for side in [&mut self.buy_orders, &mut self.sell_orders] {
+            side.retain(|o| {
+                if Self::is_expired(o, now_ms) {
+                    log::info!("EXPIRED \t dropped txId {} (expiresAtMs={})", o.tx_id, o.expires_at_ms);
+                    return false;
+                }
+                true
+            });
+        }
+        if Self::is_expired(&order, now_ms) {
+            log::info!("EXPIRED \t txId {} not entered (expiresAtMs={})", order.tx_id, order.expires_at_ms);
+            return OrderOutcome { filled_qty: 0.0, remaining_qty: order.qty, is_resting: false };
+        }
+
         if order.price_usd < 0.0 {
-            Self::match_order(&self.nats_service, order, &mut self.buy_orders, &mut self.sell_orders).await;
+            Self::match_order(&self.nats_service, order, &mut self.buy_orders, &mut self.sell_orders).await
         } else {
-            Self::match_order(&self.nats_service, order, &mut self.sell_orders, &mut self.buy_orders).await;
+            Self::match_order(&self.nats_service, order, &mut self.sell_orders, &mut self.buy_orders).await
         }
     }
 
-    async fn match_order(nats_service: &nats::NatsService, mut incoming_order: CreateOrderRequestClob, opposite_orders: &mut Vec<CreateOrderRequestClob>, same_side_orders: &mut Vec<CreateOrderRequestClob>) {
+    fn is_expired(order: &CreateOrderRequestClob, now_ms: i64) -> bool {
+        order.expires_at_ms > 0 && order.expires_at_ms <= now_ms
+    }
+
+    // GTC, IOC or FOK - a "market" order is never GTC, it must not sit on the book
+    fn time_in_force(order: &CreateOrderRequestClob) -> &'static str {
+        match order.time_in_force.to_uppercase().as_str() {
+            constants::TIME_IN_FORCE_IOC => constants::TIME_IN_FORCE_IOC,
+            constants::TIME_IN_FORCE_FOK => constants::TIME_IN_FORCE_FOK,
+            _ if order.market_limit.eq_ignore_ascii_case("market") => constants::TIME_IN_FORCE_IOC,
+            _ => constants::TIME_IN_FORCE_GTC,
+        }
+    }
+
+    // Match based on price constraints
+    fn prices_cross(incoming_order: &CreateOrderRequestClob, existing_order: &CreateOrderRequestClob) -> bool {
+        (incoming_order.price_usd > 0.0 && incoming_order.price_usd >= existing_order.price_usd) ||
+        (incoming_order.price_usd < 0.0 && existing_order.price_usd >= incoming_order.price_usd.abs())
+    }
+
+    async fn match_order(nats_service: &nats::NatsService, mut incoming_order: CreateOrderRequestClob, opposite_orders: &mut Vec<CreateOrderRequestClob>, same_side_orders: &mut Vec<CreateOrderRequestClob>) -> OrderOutcome {
         // No guards for performance - assume validated upstream
 
         opposite_orders.sort_by(|a, b| b.price_usd.partial_cmp(&a.price_usd).unwrap());
 
+        let qty_placed = incoming_order.qty;
+        let time_in_force = Self::time_in_force(&incoming_order);
+
+        // FOK: check the liquidity at acceptable prices before touching the book - all or nothing
+        if time_in_force == constants::TIME_IN_FORCE_FOK {
+            let qty_available: f64 = opposite_orders.iter()
+                .take_while(|existing_order| Self::prices_cross(&incoming_order, existing_order))
+                .map(|existing_order| existing_order.qty)
+                .sum();
+            if qty_available + constants::QTY_EPSILON < incoming_order.qty {
+                log::info!("FOK_KILL \t only {} of {} available for txId {}", qty_available, incoming_order.qty, incoming_order.tx_id);
+                return OrderOutcome { filled_qty: 0.0, remaining_qty: incoming_order.qty, is_resting: false };
+            }
+        }
+
         let i = 0;
         while i < opposite_orders.len() {
             let existing_order = &mut opposite_orders[i];
-            // Match based on price constraints
-            if (incoming_order.price_usd > 0.0 && incoming_order.price_usd >= existing_order.price_usd) ||
-               (incoming_order.price_usd < 0.0 && existing_order.price_usd >= incoming_order.price_usd.abs()) {
+            if Self::prices_cross(&incoming_order, existing_order) {
 
                 let orc2= existing_order.clone();
-                if incoming_order.qty <= existing_order.qty {
+                if incoming_order.qty <= existing_order.qty + constants::QTY_EPSILON {
                     // FULL match!
                     existing_order.qty -= incoming_order.qty;
-                    if existing_order.qty.abs() < 1e-3 { // small EPSILON to account for floating point precision
+                    if existing_order.qty.abs() < constants::QTY_EPSILON {
                         opposite_orders.remove(i);
                     }
 
@@ -269,7 +428,7 @@ impl OrderBook {
                         }
                     });
                     
-                    return;
+                    return OrderOutcome { filled_qty: qty_placed, remaining_qty: 0.0, is_resting: false };
                 } else {
                     // PARTIAL match
                     incoming_order.qty -= existing_order.qty;
@@ -299,8 +458,16 @@ impl OrderBook {
             }
         }
 
+        let remaining_qty = incoming_order.qty;
+        if time_in_force != constants::TIME_IN_FORCE_GTC {
+            // IOC (or a "market" order): the remainder is cancelled, never rested
+            log::info!("{} \t cancelled the unfilled {} of {} for txId {}", time_in_force, remaining_qty, qty_placed, incoming_order.tx_id);
+            return OrderOutcome { filled_qty: qty_placed - remaining_qty, remaining_qty, is_resting: false };
+        }
+
         // If no match, add to the respective order book // log::info!("No match found, adding to same side orders: {:?}", incoming_order);
         same_side_orders.push(incoming_order);
+        OrderOutcome { filled_qty: qty_placed - remaining_qty, remaining_qty, is_resting: true }
     }
 
     pub fn snapshot(&self, depth: usize) -> BookSnapshot {
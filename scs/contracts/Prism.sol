@@ -41,6 +41,7 @@ contract Prism {
   mapping(uint128 => bool) public outcomes;               // true = YES wins, false = NO wins
   mapping(uint128 => uint256) public resolutionTimes;
   mapping(uint128 => uint256) public totalCollateralUsd;
+  mapping(uint128 => bool) public voided;                 // true = market was voided, holders are refunded 50/50
   
   mapping(uint128 => mapping(address => uint256)) public yesTokens;
   mapping(uint128 => mapping(address => uint256)) public noTokens;
@@ -50,6 +51,7 @@ contract Prism {
   
   event PositionTokensPurchased(uint128 marketId, address indexed buyer, uint256 collateralUsd, uint256 qtyScaled);
   event MarketResolved(uint128 marketId, bool outcome);
+  event MarketVoided(uint128 marketId);
   event WinningsRedeemed(uint128 marketId, address indexed user, uint256 amount);
   event TokenAssociated(address indexed token);
   event AccountAuthorizationResponse(int64 responseCode, address account, bool response);
@@ -199,7 +201,13 @@ contract Prism {
   function redeem(uint128 marketId) external returns (uint256 amountUSDC) {
     require(resolutionTimes[marketId] > 0, "Not resolved yet");
     
-    uint256 nTokens = outcomes[marketId] ? yesTokens[marketId][msg.sender] : noTokens[marketId][msg.sender];
+    uint256 nTokens;
+    if (voided[marketId]) {
+      // voided market: every YES and NO token is worth half of its collateral
+      nTokens = (yesTokens[marketId][msg.sender] + noTokens[marketId][msg.sender]) / 2;
+    } else {
+      nTokens = outcomes[marketId] ? yesTokens[marketId][msg.sender] : noTokens[marketId][msg.sender];
+    }
     require(nTokens > 0, "No winning tokens");
 
     // TODO - 2% profit redeem fee...
@@ -252,7 +260,19 @@ contract Prism {
 
   // TODO - implement storage pruning...
 
-  // TODO - implement an admin function close a market and return funds 50/50 to YES and NO token holders respectively
+  /**
+  This function allows the owner to void (cancel) a market, e.g. when its statement is ambiguous.
+  The market can no longer be resolved and holders redeem their collateral 50/50 via redeem().
+  @param marketId The ID of the market to be voided.
+  */
+  function voidMarket(uint128 marketId) external onlyOwner {
+    require(resolutionTimes[marketId] == 0, "Already resolved");
+
+    voided[marketId] = true;
+    resolutionTimes[marketId] = block.timestamp;
+
+    emit MarketVoided(marketId);
+  }
 
     
 